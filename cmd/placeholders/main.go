@@ -0,0 +1,42 @@
+package main
+
+import (
+	"api/core"
+	"api/logger"
+	"api/storage/postgresql"
+	"context"
+	"flag"
+	"net/http"
+	"os"
+	"time"
+)
+
+// Fills the BlurHash and dominant color of the images uploaded before they were computed, safe to run repeatedly
+func main() {
+	log := logger.NewLogger(logger.WithPretty())
+
+	maxPixels := flag.Int("max-pixels", 50000000, "images with more pixels are skipped without decoding them")
+	timeout := flag.Duration("timeout", 30*time.Second, "timeout of downloading a single image")
+	flag.Parse()
+
+	connectionUrl := os.Getenv("DATABASE_URL")
+	if connectionUrl == "" {
+		log.Fatal().Msg("missing 'DATABASE_URL' env variable")
+	}
+
+	ctx := context.Background()
+	db := postgresql.NewDatabase(log)
+	if err := db.Connect(ctx, connectionUrl); err != nil {
+		log.Fatal().Err(err).Msg("failed connecting to the database")
+	}
+	defer db.Close()
+
+	backfill := core.NewPlaceholderBackfill(
+		postgresql.NewImageRepository(db), &http.Client{Timeout: *timeout}, *maxPixels, log,
+	)
+	filled, skipped, err := backfill.Run(ctx)
+	if err != nil {
+		log.Fatal().Err(err).Msgf("Backfill stopped after filling %d images", filled)
+	}
+	log.Info().Msgf("Filled placeholders of %d images, skipped %d", filled, skipped)
+}
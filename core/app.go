@@ -8,10 +8,16 @@ import (
 )
 
 type App struct {
-	Config        Config
-	ImagesService *ImagesService
-	Auth          auth.Authenticator
-	storage       storage.Storage
+	Config          Config
+	ImagesService   *ImagesService
+	WebhooksService *WebhooksService
+	UsersService    *UsersService
+	AuditLogger     *AuditLogger
+	Auth            auth.Authenticator
+	storage         storage.Storage
+	outboxRelay     *OutboxRelay
+	trashPurger     *TrashPurger
+	sagaRecoverer   *UploadSagaRecoverer
 }
 
 func NewApp(
@@ -19,12 +25,24 @@ func NewApp(
 	storage storage.Storage,
 	auth auth.Authenticator,
 	imagesService *ImagesService,
+	webhooksService *WebhooksService,
+	usersService *UsersService,
+	auditLogger *AuditLogger,
+	outboxRelay *OutboxRelay,
+	trashPurger *TrashPurger,
+	sagaRecoverer *UploadSagaRecoverer,
 ) *App {
 	return &App{
-		Config:        config,
-		storage:       storage,
-		Auth:          auth,
-		ImagesService: imagesService,
+		Config:          config,
+		storage:         storage,
+		Auth:            auth,
+		ImagesService:   imagesService,
+		WebhooksService: webhooksService,
+		UsersService:    usersService,
+		AuditLogger:     auditLogger,
+		outboxRelay:     outboxRelay,
+		trashPurger:     trashPurger,
+		sagaRecoverer:   sagaRecoverer,
 	}
 }
 
@@ -42,13 +60,27 @@ func (a *App) Init(initCtx context.Context, ctx context.Context) error {
 		a.Auth.StartConsumingPostAuthAsync(ctx)
 	}
 
+	a.sagaRecoverer.StartAsync(ctx)
+	a.outboxRelay.StartAsync(ctx)
+	a.trashPurger.StartAsync(ctx)
+
 	return nil
 }
 
 func (a *App) Shutdown(_ context.Context) error {
+	// The relay, the purger and the recoverer read the storage, so they stop first
+	relayErr := a.outboxRelay.Shutdown()
+	if err := a.trashPurger.Shutdown(); err != nil && relayErr == nil {
+		relayErr = err
+	}
+	if err := a.sagaRecoverer.Shutdown(); err != nil && relayErr == nil {
+		relayErr = err
+	}
 	a.storage.Close()
 	if !a.Config.SqsPostAuthConsumerDisabled {
-		return a.Auth.Shutdown()
+		if err := a.Auth.Shutdown(); err != nil {
+			return err
+		}
 	}
-	return nil
+	return relayErr
 }
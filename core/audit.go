@@ -0,0 +1,96 @@
+package core
+
+import (
+	"api/pkg/clientip"
+	"api/storage"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"time"
+)
+
+// auditTimeout bounds the recording of an audit event, it runs detached from the request so that an action that
+// completed is recorded even when the client went away
+const auditTimeout = 10 * time.Second
+
+// AuditLogger records the admin actions together with the request that made them
+type AuditLogger struct {
+	repository storage.AuditRepository
+	logger     *zerolog.Logger
+}
+
+func NewAuditLogger(repository storage.AuditRepository, logger *zerolog.Logger) *AuditLogger {
+	return &AuditLogger{repository: repository, logger: logger}
+}
+
+// Log records the action of the actor on the target, before and after are marshalled to JSON and may be nil. An
+// action that already happened is not undone when recording it fails, so the failure is only logged.
+func (audit *AuditLogger) Log(
+	ctx context.Context, actorId string, action storage.AuditAction, targetId string, before, after interface{},
+) {
+	event := storage.AuditEvent{
+		ActorId:  actorId,
+		Action:   action,
+		TargetId: targetId,
+		ClientIp: clientip.FromContext(ctx),
+	}
+	if requestId, ok := hlog.IDFromCtx(ctx); ok {
+		event.RequestId = requestId.String()
+	}
+
+	var err error
+	if event.Before, err = marshalAuditState(before); err != nil {
+		audit.logFailure(event, err)
+		return
+	}
+	if event.After, err = marshalAuditState(after); err != nil {
+		audit.logFailure(event, err)
+		return
+	}
+
+	createCtx, cancel := context.WithTimeout(context.Background(), auditTimeout)
+	defer cancel()
+
+	if _, err = audit.repository.Create(createCtx, event); err != nil {
+		audit.logFailure(event, err)
+	}
+}
+
+func (audit *AuditLogger) logFailure(event storage.AuditEvent, err error) {
+	audit.logger.Error().
+		Err(err).
+		Str("actorId", event.ActorId).
+		Str("action", string(event.Action)).
+		Str("targetId", event.TargetId).
+		Str("requestId", event.RequestId).
+		Msg("failed recording audit event")
+}
+
+func marshalAuditState(state interface{}) (json.RawMessage, error) {
+	if state == nil {
+		return nil, nil
+	}
+	return json.Marshal(state)
+}
+
+func (audit *AuditLogger) GetAuditEvents(
+	ctx context.Context, filter storage.AuditEventFilter, limit, offset int,
+) (storage.AuditEventPage, error) {
+	for _, id := range []string{filter.ActorId, filter.TargetId} {
+		if id == "" {
+			continue
+		}
+		if err := parseUuids(id); err != nil {
+			return storage.AuditEventPage{}, err
+		}
+	}
+
+	page, err := audit.repository.Get(ctx, filter, limit, offset)
+	if err != nil {
+		return storage.AuditEventPage{}, fmt.Errorf("failed fetching audit events: %w", err)
+	}
+
+	return page, nil
+}
@@ -0,0 +1,175 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/pkg/clientip"
+	"api/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/hlog"
+	"testing"
+)
+
+const auditTagIdMock = "8a0f6a1e-0d1c-4c53-9ab1-3d8a2c5d6e7f"
+
+type auditRepoStub struct {
+	storage.AuditRepoMock
+	events []storage.AuditEvent
+}
+
+func (repo *auditRepoStub) Create(_ context.Context, event storage.AuditEvent) (storage.AuditEvent, error) {
+	repo.events = append(repo.events, event)
+	return event, nil
+}
+
+type auditTagRepoStub struct {
+	storage.TagRepository
+	deleted bool
+	renamed bool
+}
+
+func (repo *auditTagRepoStub) GetOne(_ context.Context, tagId string) (storage.Tag, error) {
+	return storage.Tag{Id: tagId, Value: "planes"}, nil
+}
+
+func (repo *auditTagRepoStub) Rename(_ context.Context, tagId, value string) (storage.Tag, error) {
+	repo.renamed = true
+	return storage.Tag{Id: tagId, Value: value}, nil
+}
+
+func (repo *auditTagRepoStub) DeleteOne(_ context.Context, _ string) error {
+	repo.deleted = true
+	return nil
+}
+
+type auditAuthenticatorStub struct {
+	auth.Mock
+	user storage.User
+}
+
+func (authenticator *auditAuthenticatorStub) GetOrSyncUser(
+	_ context.Context, _ auth.AuthorizationDto,
+) (storage.User, error) {
+	return authenticator.user, nil
+}
+
+func TestAuditLogger_Log(t *testing.T) {
+	logger := zerolog.Nop()
+	repo := &auditRepoStub{}
+	audit := NewAuditLogger(repo, &logger)
+
+	requestId := xid.New()
+	ctx := clientip.NewContext(hlog.CtxWithID(context.Background(), requestId), "127.0.0.1")
+	audit.Log(ctx, "actor", storage.AuditTagCreated, auditTagIdMock, nil, storage.Tag{Value: "planes"})
+
+	if len(repo.events) != 1 {
+		t.Fatalf("Expected a single event, got %d", len(repo.events))
+	}
+	event := repo.events[0]
+	if event.RequestId != requestId.String() || event.ClientIp != "127.0.0.1" {
+		t.Fatalf("Expected request %s from 127.0.0.1, got %+v", requestId, event)
+	}
+	if event.Before != nil {
+		t.Fatalf("Expected no state before creation, got %s", event.Before)
+	}
+	var after storage.Tag
+	if err := json.Unmarshal(event.After, &after); err != nil || after.Value != "planes" {
+		t.Fatalf("Expected created tag after, got %s", event.After)
+	}
+}
+
+func TestDeleteTag_Audit(t *testing.T) {
+	values := []struct {
+		Name           string
+		Role           storage.AuthRole
+		ExpectedEvents int
+	}{
+		{Name: "Admin", Role: storage.AuthRoleAdmin, ExpectedEvents: 1},
+		{Name: "Not admin", Role: storage.AuthRoleNone, ExpectedEvents: 0},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			repo := &auditRepoStub{}
+			tags := &auditTagRepoStub{}
+			service := &ImagesService{
+				tagsRepository: tags,
+				authenticator:  &auditAuthenticatorStub{user: storage.User{Id: "admin", Role: data.Role}},
+				audit:          NewAuditLogger(repo, &logger),
+				logger:         &logger,
+			}
+
+			err := service.DeleteTag(context.Background(), auth.AuthorizationDto{}, auditTagIdMock)
+			if data.Role != storage.AuthRoleAdmin {
+				var forbidden exception.Forbidden
+				if !errors.As(err, &forbidden) {
+					t.Fatalf("Expected forbidden, got %v", err)
+				}
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(repo.events) != data.ExpectedEvents {
+				t.Fatalf("Expected %d events, got %d", data.ExpectedEvents, len(repo.events))
+			}
+			if data.ExpectedEvents == 0 {
+				return
+			}
+			event := repo.events[0]
+			if event.ActorId != "admin" || event.Action != storage.AuditTagDeleted || event.TargetId != auditTagIdMock {
+				t.Fatalf("Expected tag deletion by admin, got %+v", event)
+			}
+			if event.Before == nil || event.After != nil {
+				t.Fatalf("Expected only the state before deletion, got %+v", event)
+			}
+		})
+	}
+}
+
+func TestRenameTag_Authorization(t *testing.T) {
+	values := []struct {
+		Name string
+		Role storage.AuthRole
+	}{
+		{Name: "Admin", Role: storage.AuthRoleAdmin},
+		{Name: "Not admin", Role: storage.AuthRoleNone},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			repo := &auditRepoStub{}
+			tags := &auditTagRepoStub{}
+			service := &ImagesService{
+				tagsRepository: tags,
+				authenticator:  &auditAuthenticatorStub{user: storage.User{Id: "admin", Role: data.Role}},
+				audit:          NewAuditLogger(repo, &logger),
+				logger:         &logger,
+			}
+
+			_, err := service.RenameTag(context.Background(), auth.AuthorizationDto{}, auditTagIdMock, "aircraft")
+			if data.Role != storage.AuthRoleAdmin {
+				var forbidden exception.Forbidden
+				if !errors.As(err, &forbidden) {
+					t.Fatalf("Expected forbidden, got %v", err)
+				}
+				if tags.renamed || len(repo.events) != 0 {
+					t.Fatal("Expected the tag not to be renamed")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tags.renamed || len(repo.events) != 1 || repo.events[0].Action != storage.AuditTagUpdated {
+				t.Fatalf("Expected the tag to be renamed and audited, got %+v", repo.events)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package core
 
 import (
+	"api/image/pipeline"
+	"api/pkg/slug"
 	"errors"
+	"fmt"
+	"golang.org/x/text/language"
 	"os"
 	"strconv"
+	"strings"
 )
 
 type Config struct {
@@ -11,13 +16,45 @@ type Config struct {
 	AwsRegion                   string
 	DatabaseUrl                 string
 	ImagesApiDomain             string
+	ImagesApiServiceToken       string
 	AwsAccessKeyId              string
 	AwsSecretAccessKey          string
 	SqsPostAuthUrl              string
 	SqsPostAuthIntervalSec      uint
 	SqsPostAuthConsumerDisabled bool
+	ImageSlugMode               slug.Mode
+	WebhookRetries              uint
+	OutboxRelayIntervalSec      uint
+	OutboxMaxAttempts           uint
+	TrashRetentionDays          uint
+	ImagesResizer               ImagesResizer
+	ImagesLocalDir              string
+	ImagesS3Endpoint            string
+	ImagesS3Bucket              string
+	ImagesS3AccessKeyId         string
+	ImagesS3SecretAccessKey     string
+	ImagesDomain                string
+	ImagesBreakpoints           pipeline.Breakpoints
+	ImagesAspectRatios          []AspectRatio
+	ImagesMinDimension          uint
+	ImagesMaxDimension          uint
+	ImagesMaxPixels             uint
+	ImagesDuplicates            DuplicateMode
+	ImagesDuplicateDistance     uint
+	ImagesMetadataGps           bool
+	ImagesDefaultLocale         language.Tag
 }
 
+// ImagesResizer is where the images are resized and stored, the remote images API or the API itself with the
+// files on the local disk or in an S3 compatible bucket
+type ImagesResizer string
+
+const (
+	RemoteImagesResizer ImagesResizer = "remote"
+	LocalImagesResizer  ImagesResizer = "local"
+	S3ImagesResizer     ImagesResizer = "s3"
+)
+
 func NewConfigFromEnv() (Config, error) {
 	c := Config{}
 	err := c.LoadFromEnvironment()
@@ -41,11 +78,154 @@ func (c *Config) LoadFromEnvironment() error {
 		return errors.New("missing env DATABASE_URL")
 	}
 
+	c.ImagesResizer = ImagesResizer(os.Getenv("IMAGES_RESIZER"))
+	switch c.ImagesResizer {
+	case "":
+		c.ImagesResizer = RemoteImagesResizer
+	case RemoteImagesResizer:
+	case LocalImagesResizer:
+	case S3ImagesResizer:
+	default:
+		return fmt.Errorf(
+			"env IMAGES_RESIZER must be %s, %s or %s", RemoteImagesResizer, LocalImagesResizer, S3ImagesResizer,
+		)
+	}
+
 	c.ImagesApiDomain = os.Getenv("IMAGES_API_DOMAIN")
-	if c.ImagesApiDomain == "" {
+	if c.ImagesApiDomain == "" && c.ImagesResizer == RemoteImagesResizer {
 		return errors.New("missing env IMAGES_API_DOMAIN")
 	}
 
+	c.ImagesLocalDir = os.Getenv("IMAGES_LOCAL_DIR")
+	if c.ImagesLocalDir == "" {
+		c.ImagesLocalDir = "data"
+	}
+
+	c.ImagesS3Endpoint = os.Getenv("IMAGES_S3_ENDPOINT")
+	c.ImagesS3Bucket = os.Getenv("IMAGES_S3_BUCKET")
+	if c.ImagesS3Bucket == "" && c.ImagesResizer == S3ImagesResizer {
+		return errors.New("missing env IMAGES_S3_BUCKET")
+	}
+	c.ImagesS3AccessKeyId = os.Getenv("IMAGES_S3_ACCESS_KEY_ID")
+	c.ImagesS3SecretAccessKey = os.Getenv("IMAGES_S3_SECRET_ACCESS_KEY")
+
+	c.ImagesDomain = os.Getenv("IMAGES_DOMAIN")
+	if c.ImagesDomain == "" && c.ImagesResizer != RemoteImagesResizer {
+		return errors.New("missing env IMAGES_DOMAIN")
+	}
+
+	c.ImagesBreakpoints = pipeline.DefaultBreakpoints()
+	if breakpoints := os.Getenv("IMAGES_BREAKPOINTS"); breakpoints != "" {
+		var widths []int
+		for _, width := range strings.Split(breakpoints, ",") {
+			parsedWidth, err := strconv.Atoi(strings.TrimSpace(width))
+			if err != nil {
+				return err
+			}
+			widths = append(widths, parsedWidth)
+		}
+		var err error
+		if c.ImagesBreakpoints, err = pipeline.NewBreakpoints(widths); err != nil {
+			return fmt.Errorf("env IMAGES_BREAKPOINTS: %w", err)
+		}
+	}
+
+	c.ImagesAspectRatios = DefaultAspectRatios()
+	if ratios := os.Getenv("IMAGES_ASPECT_RATIOS"); ratios != "" {
+		c.ImagesAspectRatios = nil
+		for _, ratio := range strings.Split(ratios, ",") {
+			parsedRatio, err := ParseAspectRatio(ratio)
+			if err != nil {
+				return fmt.Errorf("env IMAGES_ASPECT_RATIOS: %w", err)
+			}
+			c.ImagesAspectRatios = append(c.ImagesAspectRatios, parsedRatio)
+		}
+	}
+
+	if pixels := os.Getenv("IMAGES_MIN_DIMENSION"); pixels != "" {
+		parsedPixels, err := strconv.Atoi(pixels)
+		if err != nil {
+			return err
+		}
+		if parsedPixels < 0 {
+			return errors.New("env IMAGES_MIN_DIMENSION must not be negative")
+		}
+		c.ImagesMinDimension = uint(parsedPixels)
+	} else {
+		c.ImagesMinDimension = 100
+	}
+
+	if pixels := os.Getenv("IMAGES_MAX_DIMENSION"); pixels != "" {
+		parsedPixels, err := strconv.Atoi(pixels)
+		if err != nil {
+			return err
+		}
+		if parsedPixels < 0 {
+			return errors.New("env IMAGES_MAX_DIMENSION must not be negative")
+		}
+		c.ImagesMaxDimension = uint(parsedPixels)
+	} else {
+		c.ImagesMaxDimension = 10000
+	}
+	if c.ImagesMaxDimension != 0 && c.ImagesMaxDimension < c.ImagesMinDimension {
+		return errors.New("env IMAGES_MAX_DIMENSION must not be less than IMAGES_MIN_DIMENSION")
+	}
+
+	if pixels := os.Getenv("IMAGES_MAX_PIXELS"); pixels != "" {
+		parsedPixels, err := strconv.Atoi(pixels)
+		if err != nil {
+			return err
+		}
+		if parsedPixels <= 0 {
+			return errors.New("env IMAGES_MAX_PIXELS must be greater than 0")
+		}
+		c.ImagesMaxPixels = uint(parsedPixels)
+	} else {
+		c.ImagesMaxPixels = 50000000
+	}
+
+	c.ImagesDuplicates = DuplicateMode(os.Getenv("IMAGES_DUPLICATES"))
+	switch c.ImagesDuplicates {
+	case "":
+		c.ImagesDuplicates = WarnDuplicates
+	case WarnDuplicates:
+	case RejectDuplicates:
+	case AllowDuplicates:
+	default:
+		return fmt.Errorf(
+			"env IMAGES_DUPLICATES must be %s, %s or %s", WarnDuplicates, RejectDuplicates, AllowDuplicates,
+		)
+	}
+
+	if distance := os.Getenv("IMAGES_DUPLICATE_DISTANCE"); distance != "" {
+		parsedDistance, err := strconv.Atoi(distance)
+		if err != nil {
+			return err
+		}
+		if parsedDistance < 0 || parsedDistance > 64 {
+			return errors.New("env IMAGES_DUPLICATE_DISTANCE must be between 0 and 64")
+		}
+		c.ImagesDuplicateDistance = uint(parsedDistance)
+	} else {
+		c.ImagesDuplicateDistance = 5
+	}
+
+	if os.Getenv("IMAGES_METADATA_GPS") == "true" {
+		c.ImagesMetadataGps = true
+	}
+
+	c.ImagesDefaultLocale = language.English
+	if locale := os.Getenv("IMAGES_DEFAULT_LOCALE"); locale != "" {
+		parsedLocale, err := language.Parse(locale)
+		if err != nil {
+			return fmt.Errorf("env IMAGES_DEFAULT_LOCALE: %w", err)
+		}
+		c.ImagesDefaultLocale = parsedLocale
+	}
+
+	// Purging the trash and recovering interrupted uploads call the images API outside of user requests
+	c.ImagesApiServiceToken = os.Getenv("IMAGES_API_SERVICE_TOKEN")
+
 	c.AwsAccessKeyId = os.Getenv("AWS_ACCESS_KEY_ID")
 	if c.AwsAccessKeyId == "" {
 		return errors.New("missing env AWS_ACCESS_KEY_ID")
@@ -78,5 +258,95 @@ func (c *Config) LoadFromEnvironment() error {
 		c.SqsPostAuthConsumerDisabled = true
 	}
 
+	c.ImageSlugMode = slug.Mode(os.Getenv("IMAGE_SLUG_MODE"))
+	if c.ImageSlugMode == "" {
+		c.ImageSlugMode = slug.AutoSuffix
+	} else if !c.ImageSlugMode.IsValid() {
+		return fmt.Errorf("env IMAGE_SLUG_MODE must be %s or %s", slug.Strict, slug.AutoSuffix)
+	}
+
+	if seconds := os.Getenv("OUTBOX_RELAY_INTERVAL_SEC"); seconds != "" {
+		parsedSeconds, err := strconv.Atoi(seconds)
+		if err != nil {
+			return err
+		}
+		if parsedSeconds <= 0 {
+			return errors.New("env OUTBOX_RELAY_INTERVAL_SEC must be greater than 0")
+		}
+		c.OutboxRelayIntervalSec = uint(parsedSeconds)
+	} else {
+		c.OutboxRelayIntervalSec = 5
+	}
+
+	if attempts := os.Getenv("OUTBOX_MAX_ATTEMPTS"); attempts != "" {
+		parsedAttempts, err := strconv.Atoi(attempts)
+		if err != nil {
+			return err
+		}
+		if parsedAttempts <= 0 {
+			return errors.New("env OUTBOX_MAX_ATTEMPTS must be greater than 0")
+		}
+		c.OutboxMaxAttempts = uint(parsedAttempts)
+	} else {
+		c.OutboxMaxAttempts = 10
+	}
+
+	if retries := os.Getenv("WEBHOOK_RETRIES"); retries != "" {
+		parsedRetries, err := strconv.Atoi(retries)
+		if err != nil {
+			return err
+		}
+		if parsedRetries < 0 {
+			return errors.New("env WEBHOOK_RETRIES must not be negative")
+		}
+		c.WebhookRetries = uint(parsedRetries)
+	} else {
+		c.WebhookRetries = 3
+	}
+
+	if days := os.Getenv("TRASH_RETENTION_DAYS"); days != "" {
+		parsedDays, err := strconv.Atoi(days)
+		if err != nil {
+			return err
+		}
+		if parsedDays <= 0 {
+			return errors.New("env TRASH_RETENTION_DAYS must be greater than 0")
+		}
+		c.TrashRetentionDays = uint(parsedDays)
+	} else {
+		c.TrashRetentionDays = 30
+	}
+
 	return nil
 }
+
+// ImagesApiServiceAuthHeader is the authorization header of the API's own calls to the images API
+func (c Config) ImagesApiServiceAuthHeader() string {
+	if c.ImagesApiServiceToken == "" {
+		return ""
+	}
+	return "Bearer " + c.ImagesApiServiceToken
+}
+
+// HasImagesApiServiceAccess tells whether the files can be changed outside of user requests, the remote resizer
+// needs the service token for it
+func (c Config) HasImagesApiServiceAccess() bool {
+	return c.ImagesResizer != RemoteImagesResizer || c.ImagesApiServiceToken != ""
+}
+
+// ImagesUploadRules are the checks of the uploaded files, webp files are limited when the API resizes them itself
+func (c Config) ImagesUploadRules() UploadRules {
+	rules := UploadRules{
+		AspectRatios: c.ImagesAspectRatios,
+		MinDimension: int(c.ImagesMinDimension),
+		MaxDimension: int(c.ImagesMaxDimension),
+		MaxPixels:    int(c.ImagesMaxPixels),
+		Duplicates:   c.ImagesDuplicates,
+		MaxDistance:  int(c.ImagesDuplicateDistance),
+		KeepGps:      c.ImagesMetadataGps,
+	}
+	if c.ImagesResizer != RemoteImagesResizer {
+		rules.WebpMaxPixels = pipeline.WebpMaxPixels
+	}
+	return rules
+}
@@ -2,28 +2,83 @@ package core
 
 import (
 	"api/auth"
+	"api/core/exception"
 	"api/image"
+	"api/pkg/slug"
 	"api/storage"
+	"context"
+	"errors"
+	"fmt"
 	"github.com/rs/zerolog"
+	"golang.org/x/text/language"
 )
 
 type ImagesService struct {
 	resizeApi        image.Resizer
 	imagesRepository storage.ImagesRepository
+	tagsRepository   storage.TagRepository
+	uploadSagas      storage.UploadSagaRepository
 	authenticator    auth.Authenticator
+	audit            *AuditLogger
 	logger           *zerolog.Logger
+	slugMode         slug.Mode
+	uploadRules      UploadRules
+	// defaultLocale is the locale of the description of images, other locales are in their translations
+	defaultLocale language.Tag
+	// serviceAuthHeader authorizes the background calls to the images API that are not made for a user request
+	serviceAuthHeader string
 }
 
 func NewImagesService(
+	config Config,
 	resizeApi image.Resizer,
 	imagesRepository storage.ImagesRepository,
+	tagsRepository storage.TagRepository,
+	uploadSagas storage.UploadSagaRepository,
 	authenticator auth.Authenticator,
+	audit *AuditLogger,
 	logger *zerolog.Logger,
 ) *ImagesService {
 	return &ImagesService{
-		resizeApi:        resizeApi,
-		imagesRepository: imagesRepository,
-		authenticator:    authenticator,
-		logger:           logger,
+		resizeApi:         resizeApi,
+		imagesRepository:  imagesRepository,
+		tagsRepository:    tagsRepository,
+		uploadSagas:       uploadSagas,
+		authenticator:     authenticator,
+		audit:             audit,
+		logger:            logger,
+		slugMode:          config.ImageSlugMode,
+		uploadRules:       config.ImagesUploadRules(),
+		defaultLocale:     config.ImagesDefaultLocale,
+		serviceAuthHeader: config.ImagesApiServiceAuthHeader(),
 	}
 }
+
+// generateImageName turns the requested name into a slug that is not used by another image, currentName belongs
+// to the image being named, so it is free for it
+func (service *ImagesService) generateImageName(ctx context.Context, imageName, currentName string) (string, error) {
+	exists := func(ctx context.Context, name string) (bool, error) {
+		if name == currentName {
+			return false, nil
+		}
+		return service.imagesRepository.DoesImageExist(ctx, name)
+	}
+
+	name, err := slug.NewGenerator(exists, slug.DefaultMaxLength).Generate(ctx, imageName, service.slugMode)
+	switch {
+	case errors.Is(err, slug.ErrEmpty):
+		return "", exception.InvalidArgument{
+			Reason: fmt.Sprintf("Invalid image name of %s", imageName),
+		}
+	case errors.Is(err, slug.ErrTaken):
+		return "", exception.InvalidArgument{
+			Reason: fmt.Sprintf(
+				"Image name: '%s' already exists, please use another", slug.Make(imageName, slug.DefaultMaxLength),
+			),
+		}
+	case err != nil:
+		return "", err
+	}
+
+	return name, nil
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"api/image"
+	"api/image/content"
+	"api/image/placeholder"
+	"api/storage"
+	"fmt"
+	"mime/multipart"
+)
+
+// fileAnalysis is computed from the files of every upload. The perceptual hash of the cropped file finds the look
+// alikes of the image, its placeholder is shown while the sizes load and the metadata comes from the original file.
+type fileAnalysis struct {
+	hash        storage.PerceptualHash
+	placeholder placeholder.Placeholder
+	metadata    storage.ImageMetadata
+}
+
+func (analysis fileAnalysis) applyTo(img storage.Image) storage.Image {
+	img.PerceptualHash = &analysis.hash
+	img.BlurHash = &analysis.placeholder.BlurHash
+	img.DominantColor = &analysis.placeholder.Color
+	img.Metadata = &analysis.metadata
+	return img
+}
+
+// analyzeFiles decodes the cropped file once for both the hash and the placeholder. It is analyzed in every
+// duplicate mode so that the look alikes of the image can be listed later on.
+func (service *ImagesService) analyzeFiles(
+	format image.Format, originalFile, croppedFile *multipart.FileHeader,
+) (fileAnalysis, error) {
+	data, err := content.ReadFile(croppedFile, format, service.uploadRules.MaxPixels)
+	if err != nil {
+		return fileAnalysis{}, fmt.Errorf("failed reading cropped file: %w", err)
+	}
+	img, err := content.Decode(data, service.uploadRules.MaxPixels)
+	if err != nil {
+		return fileAnalysis{}, fmt.Errorf("failed decoding cropped file: %w", err)
+	}
+
+	return fileAnalysis{
+		hash:        storage.PerceptualHash(content.DHash(img)),
+		placeholder: placeholder.Compute(img),
+		metadata:    service.readMetadata(format, originalFile),
+	}, nil
+}
+
+// readMetadata keeps the part of the EXIF of the original file that photographers care about. An EXIF that can not
+// be read only leaves the metadata empty, the file itself was already checked.
+func (service *ImagesService) readMetadata(
+	format image.Format, originalFile *multipart.FileHeader,
+) storage.ImageMetadata {
+	exif, err := content.ReadFileExif(originalFile, format)
+	if err != nil {
+		service.logger.Warn().Err(err).Msg("failed reading the EXIF of the original file")
+		return storage.ImageMetadata{}
+	}
+
+	metadata := storage.ImageMetadata{
+		CameraMake:   exif.Make,
+		CameraModel:  exif.Model,
+		LensModel:    exif.LensModel,
+		ExposureTime: exif.ExposureTime,
+		FNumber:      exif.FNumber,
+		FocalLength:  exif.FocalLength,
+		Iso:          exif.Iso,
+		CapturedAt:   exif.CapturedAt,
+		Orientation:  exif.Orientation,
+	}
+	if service.uploadRules.KeepGps && exif.Gps != nil {
+		metadata.Gps = &storage.GpsPosition{Latitude: exif.Gps.Latitude, Longitude: exif.Gps.Longitude}
+	}
+
+	return metadata
+}
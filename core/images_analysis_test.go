@@ -0,0 +1,93 @@
+package core
+
+import (
+	"api/image"
+	"api/image/pipeline"
+	"encoding/binary"
+	"github.com/rs/zerolog"
+	"hash/crc32"
+	stdimage "image"
+	"testing"
+)
+
+// newTestPngWithExif encodes a png whose eXIf holds the orientation and the GPS position 51° 30' N, 7' 30" W
+func newTestPngWithExif(t *testing.T) []byte {
+	data, err := pipeline.Encode(stdimage.NewRGBA(stdimage.Rect(0, 0, 600, 400)), image.PngFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	entry := func(tag, fieldType uint16, count, value uint32) []byte {
+		e := make([]byte, 12)
+		binary.LittleEndian.PutUint16(e, tag)
+		binary.LittleEndian.PutUint16(e[2:], fieldType)
+		binary.LittleEndian.PutUint32(e[4:], count)
+		binary.LittleEndian.PutUint32(e[8:], value)
+		return e
+	}
+	rationals := func(values ...uint32) []byte {
+		r := make([]byte, 4*len(values))
+		for i, value := range values {
+			binary.LittleEndian.PutUint32(r[4*i:], value)
+		}
+		return r
+	}
+
+	tiff = append(tiff, 2, 0)
+	tiff = append(tiff, entry(0x0112, 3, 1, 6)...)
+	tiff = append(tiff, entry(0x8825, 4, 1, 38)...)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, 4, 0)
+	tiff = append(tiff, entry(0x0001, 2, 2, 'N')...)
+	tiff = append(tiff, entry(0x0002, 5, 3, 92)...)
+	tiff = append(tiff, entry(0x0003, 2, 2, 'W')...)
+	tiff = append(tiff, entry(0x0004, 5, 3, 116)...)
+	tiff = append(tiff, 0, 0, 0, 0)
+	tiff = append(tiff, rationals(51, 1, 30, 1, 0, 1)...)
+	tiff = append(tiff, rationals(0, 1, 7, 1, 30, 1)...)
+
+	chunk := make([]byte, 4, 12+len(tiff))
+	binary.BigEndian.PutUint32(chunk, uint32(len(tiff)))
+	chunk = append(append(chunk, "eXIf"...), tiff...)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(chunk[4:]))
+	chunk = append(chunk, checksum...)
+
+	// The chunk goes right after IHDR, which ends 33 bytes in
+	return append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+}
+
+func TestReadMetadata(t *testing.T) {
+	logger := zerolog.Nop()
+	values := []struct {
+		Name      string
+		KeepGps   bool
+		IsGpsKept bool
+	}{
+		{Name: "GPS removed by default"},
+		{Name: "GPS kept when allowed", KeepGps: true, IsGpsKept: true},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service := ImagesService{uploadRules: UploadRules{KeepGps: data.KeepGps}, logger: &logger}
+
+			file := newTestFileHeaderOf(t, image.PngFormat, newTestPngWithExif(t))
+
+			metadata := service.readMetadata(image.PngFormat, file)
+			if metadata.Orientation != 6 {
+				t.Fatalf("Expected orientation 6, got %+v", metadata)
+			}
+			if !data.IsGpsKept {
+				if metadata.Gps != nil {
+					t.Fatalf("Expected the position to be removed, got %+v", metadata.Gps)
+				}
+				return
+			}
+			if metadata.Gps == nil || metadata.Gps.Latitude != 51.5 || metadata.Gps.Longitude != -0.125 {
+				t.Fatalf("Expected the position 51.5, -0.125, got %+v", metadata.Gps)
+			}
+		})
+	}
+}
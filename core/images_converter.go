@@ -41,6 +41,10 @@ func convertStorageSizesToDimensions(imageSizes storage.ImageSizes) []image.Dime
 }
 
 func fromImageResizeDimensions(img *image.Dimensions) *storage.Dimensions {
+	if img == nil {
+		return nil
+	}
+
 	return &storage.Dimensions{
 		Width:  img.Width,
 		Height: img.Height,
@@ -48,6 +52,10 @@ func fromImageResizeDimensions(img *image.Dimensions) *storage.Dimensions {
 }
 
 func fromStorageDimensions(img *storage.Dimensions) *image.Dimensions {
+	if img == nil {
+		return nil
+	}
+
 	return &image.Dimensions{
 		Width:  img.Width,
 		Height: img.Height,
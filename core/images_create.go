@@ -2,10 +2,10 @@ package core
 
 import (
 	"api/auth"
-	"api/core/exception"
 	"api/image"
 	"api/storage"
 	"context"
+	"errors"
 	"fmt"
 	"golang.org/x/sync/errgroup"
 	"mime/multipart"
@@ -76,76 +76,65 @@ func (service *ImagesService) UploadAndResize(
 	ctx context.Context,
 	authorization auth.AuthorizationDto,
 	imageName string,
+	description storage.ImageDescription,
 	format image.Format,
 	originalFile *multipart.FileHeader,
 	croppedFile *multipart.FileHeader,
 ) (storage.Image, error) {
-	seoImageName := FormatForSeo(imageName)
-	if seoImageName == "" {
-		return storage.Image{}, exception.InvalidArgument{
-			Reason: fmt.Sprintf("Invalid image name of %s", imageName),
-		}
-	}
-
-	currentUser, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	description, err := validateDescription(description)
 	if err != nil {
 		return storage.Image{}, err
 	}
-
-	isNameTaken, err := service.imagesRepository.DoesImageExist(ctx, seoImageName)
-	if err != nil {
-		return storage.Image{}, exception.InvalidArgument{
-			Reason: fmt.Sprintf("Image '%s' already exists", seoImageName),
-		}
+	if err = service.uploadRules.validateFiles(format, originalFile, croppedFile); err != nil {
+		return storage.Image{}, err
 	}
-	if isNameTaken {
-		return storage.Image{}, exception.InvalidArgument{
-			Reason: fmt.Sprintf("Image name: '%s' already exists, please use another", seoImageName),
-		}
+	analysis, err := service.analyzeFiles(format, originalFile, croppedFile)
+	if err != nil {
+		return storage.Image{}, err
 	}
 
-	originalSigned, croppedSigned, err := service.getMultipleSignUrls(ctx, authorization.Header, format)
+	currentUser, err := service.authenticator.GetOrSyncUser(ctx, authorization)
 	if err != nil {
-		return storage.Image{},
-			fmt.Errorf("error creating multiple sign urls: %w", err)
+		return storage.Image{}, err
 	}
 
-	err = service.uploadBothFiles(
-		ctx,
-		originalSigned.SignedUrl,
-		croppedSigned.SignedUrl,
-		format,
-		originalFile,
-		croppedFile,
-	)
+	duplicates, err := service.checkDuplicates(ctx, analysis.hash, "")
 	if err != nil {
-		return storage.Image{}, fmt.Errorf("error uploading files: %w", err)
+		return storage.Image{}, err
 	}
 
-	resizeRequest := image.ResizeRequest{
-		Name:             seoImageName,
-		FilePath:         croppedSigned.FileName,
-		OriginalFilePath: originalSigned.FileName,
-	}
-	res, err := service.resizeApi.Resize(ctx, authorization.Header, resizeRequest)
+	seoImageName, err := service.generateImageName(ctx, imageName, "")
 	if err != nil {
-		return storage.Image{}, fmt.Errorf("error resizing: %w", err)
+		return storage.Image{}, err
 	}
 
-	newImage := storage.Image{
-		Name:     res.Name,
-		Format:   storage.ImageFormat(res.Format),
-		Original: res.Original,
-		Domain:   res.Domain,
-		Path:     res.Path,
-		Sizes:    convertImageSizesToStorageSizes(res.Sizes),
-		AuthorId: currentUser.Id,
+	// The saga reserves the name before anything is stored under it, a concurrent upload of the same name fails here
+	saga, err := service.uploadSagas.Create(ctx, storage.UploadSaga{
+		ImageName: seoImageName,
+		Format:    storage.ImageFormat(format),
+		AuthorId:  currentUser.Id,
+		Step:      storage.UploadSagaStarted,
+	})
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return storage.Image{}, toImageError(err)
+		}
+		return storage.Image{}, fmt.Errorf("error starting upload saga: %w", err)
 	}
 
-	createdImg, err := service.imagesRepository.Create(ctx, newImage)
+	createdImg, err := service.runUploadSaga(
+		ctx, authorization.Header, &saga, format, description, analysis, originalFile, croppedFile,
+	)
 	if err != nil {
-		return storage.Image{}, fmt.Errorf("err saving new image to database: %w", err)
+		service.abortUploadSaga(authorization.Header, saga, err)
+		if errors.Is(err, storage.ErrDuplicate) {
+			return storage.Image{}, toImageError(err)
+		}
+		return storage.Image{}, err
 	}
+	service.completeUploadSaga(saga)
+	service.audit.Log(ctx, currentUser.Id, storage.AuditImageCreated, createdImg.Id, nil, createdImg)
+	createdImg.Duplicates = duplicates
 
 	return createdImg, nil
 }
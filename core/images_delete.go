@@ -3,12 +3,13 @@ package core
 import (
 	"api/auth"
 	"api/core/exception"
-	"api/image"
 	"api/storage"
 	"context"
 	"github.com/google/uuid"
 )
 
+// DeleteOne moves the image to the trash, its files stay on the CDN until the trash purger removes them after the
+// retention period
 func (service *ImagesService) DeleteOne(
 	ctx context.Context,
 	auth auth.AuthorizationDto,
@@ -29,21 +30,13 @@ func (service *ImagesService) DeleteOne(
 
 	img, err := service.imagesRepository.GetOne(ctx, imageId)
 	if err != nil {
-		return err
+		return toImageError(err)
 	}
 
-	deleteRequest := image.DeleteRequest{
-		Name:       img.Name,
-		Format:     image.Format(img.Format),
-		Dimensions: convertStorageSizesToDimensions(img.Sizes),
-	}
-	if err = service.resizeApi.Delete(ctx, auth.Header, deleteRequest); err != nil {
-		service.logger.Error().Msg("failed deleting image " + imageId)
-		return err
-	}
-	if err = service.resizeApi.Invalidate(ctx, auth.Header, deleteRequest); err != nil {
-		service.logger.Error().Msgf("failed invalidating image %s: %w", imageId, err)
+	if err = service.imagesRepository.DeleteOne(ctx, parsedId.String()); err != nil {
+		return toImageError(err)
 	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageDeleted, img.Id, img, nil)
 
-	return service.imagesRepository.DeleteOne(ctx, parsedId.String())
+	return nil
 }
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// The texts describing an image are limited in characters, the database columns have the same limits
+const (
+	maxAltTextLength = 250
+	maxTitleLength   = 200
+	maxCaptionLength = 1000
+	maxCreditLength  = 200
+)
+
+// licenses are the codes an image can be licensed under, Creative Commons licenses are named by their elements
+var licenses = []string{
+	"all-rights-reserved",
+	"public-domain",
+	"cc0",
+	"cc-by",
+	"cc-by-sa",
+	"cc-by-nd",
+	"cc-by-nc",
+	"cc-by-nc-sa",
+	"cc-by-nc-nd",
+}
+
+// validateDescription trims the texts and checks their length and the license code, all of them may be empty
+func validateDescription(description storage.ImageDescription) (storage.ImageDescription, error) {
+	fields := []struct {
+		name      string
+		value     *string
+		maxLength int
+	}{
+		{"altText", &description.AltText, maxAltTextLength},
+		{"title", &description.Title, maxTitleLength},
+		{"caption", &description.Caption, maxCaptionLength},
+		{"credit", &description.Credit, maxCreditLength},
+	}
+	for _, field := range fields {
+		*field.value = strings.TrimSpace(*field.value)
+		if utf8.RuneCountInString(*field.value) > field.maxLength {
+			return storage.ImageDescription{}, exception.InvalidArgument{
+				Reason: fmt.Sprintf("%s can not be longer than %d characters", field.name, field.maxLength),
+			}
+		}
+	}
+
+	description.License = strings.ToLower(strings.TrimSpace(description.License))
+	if description.License == "" {
+		return description, nil
+	}
+	for _, license := range licenses {
+		if description.License == license {
+			return description, nil
+		}
+	}
+
+	return storage.ImageDescription{}, exception.InvalidArgument{
+		Reason: fmt.Sprintf("Unknown license %s, expected one of %s", description.License, strings.Join(licenses, ", ")),
+	}
+}
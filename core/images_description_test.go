@@ -0,0 +1,64 @@
+package core
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestValidateDescription(t *testing.T) {
+	values := []struct {
+		Name        string
+		Description storage.ImageDescription
+		Expected    storage.ImageDescription
+		IsInvalid   bool
+	}{
+		{Name: "Empty", Description: storage.ImageDescription{}},
+		{
+			Name:        "Trims texts and normalizes license",
+			Description: storage.ImageDescription{Title: " Spitfire ", Credit: "John Doe ", License: " CC-BY-SA"},
+			Expected:    storage.ImageDescription{Title: "Spitfire", Credit: "John Doe", License: "cc-by-sa"},
+		},
+		{
+			Name:        "Alt text at the limit",
+			Description: storage.ImageDescription{AltText: strings.Repeat("ž", maxAltTextLength)},
+			Expected:    storage.ImageDescription{AltText: strings.Repeat("ž", maxAltTextLength)},
+		},
+		{
+			Name:        "Alt text too long",
+			Description: storage.ImageDescription{AltText: strings.Repeat("a", maxAltTextLength+1)},
+			IsInvalid:   true,
+		},
+		{
+			Name:        "Caption too long",
+			Description: storage.ImageDescription{Caption: strings.Repeat("a", maxCaptionLength+1)},
+			IsInvalid:   true,
+		},
+		{
+			Name:        "Unknown license",
+			Description: storage.ImageDescription{License: "gpl"},
+			IsInvalid:   true,
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			result, err := validateDescription(data.Description)
+			if data.IsInvalid {
+				var invalidArgument exception.InvalidArgument
+				if !errors.As(err, &invalidArgument) {
+					t.Fatalf("Expected invalid argument, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != data.Expected {
+				t.Fatalf("Expected %+v, got %+v", data.Expected, result)
+			}
+		})
+	}
+}
@@ -4,22 +4,97 @@ import (
 	"api/core/exception"
 	"api/storage"
 	"context"
+	"errors"
 	"fmt"
 	"github.com/google/uuid"
+	"golang.org/x/text/language"
+	"strings"
 )
 
+func validateImageFilter(filter storage.ImageFilter) (storage.ImageFilter, error) {
+	if filter.Format != "" && !filter.Format.IsSupported() {
+		return storage.ImageFilter{}, exception.InvalidArgument{
+			Reason: fmt.Sprintf("Unsupported format %s", filter.Format),
+		}
+	}
+
+	if filter.AuthorId != "" {
+		parsedId, err := uuid.Parse(filter.AuthorId)
+		if err != nil {
+			return storage.ImageFilter{}, exception.InvalidArgument{Reason: "Invalid authorId uuid"}
+		}
+		filter.AuthorId = parsedId.String()
+	}
+
+	if filter.CreatedAfter != nil && filter.CreatedBefore != nil &&
+		!filter.CreatedAfter.Before(*filter.CreatedBefore) {
+		return storage.ImageFilter{}, exception.InvalidArgument{
+			Reason: "createdAfter must be before createdBefore",
+		}
+	}
+	if filter.CapturedAfter != nil && filter.CapturedBefore != nil &&
+		!filter.CapturedAfter.Before(*filter.CapturedBefore) {
+		return storage.ImageFilter{}, exception.InvalidArgument{
+			Reason: "capturedAfter must be before capturedBefore",
+		}
+	}
+
+	filter.Tag = normalizeTag(filter.Tag)
+
+	return filter, nil
+}
+
+// Get returns a page of images with their alt texts and captions in the locales that match the preferences best
 func (service *ImagesService) Get(
-	ctx context.Context, limit, offset int, order storage.Order,
-) (storage.ImageList, error) {
-	images, err := service.imagesRepository.Get(ctx, limit, offset, order)
+	ctx context.Context, paging storage.Paging, filter storage.ImageFilter, preferences []language.Tag,
+) (storage.ImagePage, error) {
+	filter, err := validateImageFilter(filter)
+	if err != nil {
+		return storage.ImagePage{}, err
+	}
+
+	if paging.Cursor != nil {
+		if _, err = uuid.Parse(paging.Cursor.Id); err != nil {
+			return storage.ImagePage{}, exception.InvalidArgument{Reason: "Invalid cursor"}
+		}
+	}
+
+	page, err := service.imagesRepository.Get(ctx, paging, filter)
 	if err != nil {
-		return storage.ImageList{}, fmt.Errorf("failed fetching images: %w", err)
+		return storage.ImagePage{}, fmt.Errorf("failed fetching images: %w", err)
+	}
+	if err = service.localize(ctx, page.Images, preferences); err != nil {
+		return storage.ImagePage{}, err
 	}
 
-	return images, nil
+	return page, nil
 }
 
-func (service *ImagesService) GetOne(ctx context.Context, imageId string) (storage.Image, error) {
+const searchQueryMaxLength = 200
+
+func (service *ImagesService) Search(ctx context.Context, text string, limit, offset int) (storage.ImagePage, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return storage.ImagePage{}, exception.InvalidArgument{Reason: "Search query is required"}
+	}
+	if len(text) > searchQueryMaxLength {
+		return storage.ImagePage{}, exception.InvalidArgument{
+			Reason: fmt.Sprintf("Search query can not be longer than %d characters", searchQueryMaxLength),
+		}
+	}
+
+	page, err := service.imagesRepository.Search(ctx, text, limit, offset)
+	if err != nil {
+		return storage.ImagePage{}, fmt.Errorf("failed searching images: %w", err)
+	}
+
+	return page, nil
+}
+
+// GetOne returns the image with its alt text and caption in the locale that matches the preferences best
+func (service *ImagesService) GetOne(
+	ctx context.Context, imageId string, preferences []language.Tag,
+) (storage.Image, error) {
 	parsedImageId, err := uuid.Parse(imageId)
 	if err != nil {
 		return storage.Image{}, exception.InvalidArgument{Reason: "Invalid uuid"}
@@ -27,8 +102,53 @@ func (service *ImagesService) GetOne(ctx context.Context, imageId string) (stora
 
 	image, err := service.imagesRepository.GetOne(ctx, parsedImageId.String())
 	if err != nil {
+		return storage.Image{}, toImageError(err)
+	}
+	images := []storage.Image{image}
+	if err = service.localize(ctx, images, preferences); err != nil {
 		return storage.Image{}, err
 	}
+	image = images[0]
 
 	return image, nil
 }
+
+// GetMetadata returns the metadata read from the original file of the image
+func (service *ImagesService) GetMetadata(ctx context.Context, imageId string) (storage.ImageMetadata, error) {
+	if err := parseUuids(imageId); err != nil {
+		return storage.ImageMetadata{}, err
+	}
+
+	metadata, err := service.imagesRepository.GetMetadata(ctx, imageId)
+	if err != nil {
+		return storage.ImageMetadata{}, toImageError(err)
+	}
+
+	return metadata, nil
+}
+
+// GetOneBySlug resolves both the current and previous names of an image, isRedirect tells that the slug is an old
+// one and clients should move to the current name
+func (service *ImagesService) GetOneBySlug(
+	ctx context.Context, slug string,
+) (image storage.Image, isRedirect bool, err error) {
+	if slug == "" {
+		return storage.Image{}, false, exception.InvalidArgument{Reason: "Slug is required"}
+	}
+
+	image, err = service.imagesRepository.GetOneByName(ctx, slug)
+	if err == nil {
+		return image, false, nil
+	}
+	var notFound storage.NotFound
+	if !errors.As(err, &notFound) {
+		return storage.Image{}, false, err
+	}
+
+	image, err = service.imagesRepository.GetOneByOldName(ctx, slug)
+	if err != nil {
+		return storage.Image{}, false, toImageError(err)
+	}
+
+	return image, true, nil
+}
@@ -0,0 +1,167 @@
+package core
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateImageFilter(t *testing.T) {
+	after := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	values := []struct {
+		Name      string
+		Filter    storage.ImageFilter
+		Expected  storage.ImageFilter
+		IsInvalid bool
+	}{
+		{
+			Name:     "Empty filter",
+			Filter:   storage.ImageFilter{},
+			Expected: storage.ImageFilter{},
+		},
+		{
+			Name: "Normalizes tag and author",
+			Filter: storage.ImageFilter{
+				Tag:      " World  War ",
+				AuthorId: "3C47D736-6C4E-4A1C-A04B-3744CC30B263",
+			},
+			Expected: storage.ImageFilter{
+				Tag:      "world war",
+				AuthorId: "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
+			},
+		},
+		{
+			Name:      "Unsupported format",
+			Filter:    storage.ImageFilter{Format: "gif"},
+			IsInvalid: true,
+		},
+		{
+			Name:      "Invalid author",
+			Filter:    storage.ImageFilter{AuthorId: "john"},
+			IsInvalid: true,
+		},
+		{
+			Name:      "Inverted date range",
+			Filter:    storage.ImageFilter{CreatedAfter: &before, CreatedBefore: &after},
+			IsInvalid: true,
+		},
+		{
+			Name:      "Inverted capture date range",
+			Filter:    storage.ImageFilter{CapturedAfter: &before, CapturedBefore: &after},
+			IsInvalid: true,
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			result, err := validateImageFilter(data.Filter)
+			if data.IsInvalid {
+				var invalidArgument exception.InvalidArgument
+				if !errors.As(err, &invalidArgument) {
+					t.Fatalf("Expected invalid argument, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Tag != data.Expected.Tag || result.AuthorId != data.Expected.AuthorId {
+				t.Fatalf("Expected %+v, got %+v", data.Expected, result)
+			}
+		})
+	}
+}
+
+func TestSearch_InvalidQuery(t *testing.T) {
+	service := ImagesService{imagesRepository: storage.ImageRepoMock{}}
+
+	values := []struct {
+		Name string
+		Text string
+	}{
+		{Name: "Empty", Text: ""},
+		{Name: "Whitespace", Text: "   "},
+		{Name: "Too long", Text: strings.Repeat("a", searchQueryMaxLength+1)},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			_, err := service.Search(context.Background(), data.Text, 10, 0)
+			var invalidArgument exception.InvalidArgument
+			if !errors.As(err, &invalidArgument) {
+				t.Fatalf("Expected invalid argument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGet_InvalidCursor(t *testing.T) {
+	service := ImagesService{imagesRepository: storage.ImageRepoMock{}}
+	paging := storage.Paging{Limit: 10, Order: storage.OrderDescending, Cursor: &storage.Cursor{Id: "john"}}
+
+	_, err := service.Get(context.Background(), paging, storage.ImageFilter{}, nil)
+	var invalidArgument exception.InvalidArgument
+	if !errors.As(err, &invalidArgument) {
+		t.Fatalf("Expected invalid argument, got %v", err)
+	}
+}
+
+// slugRepoStub knows a single image by its current and one previous name
+type slugRepoStub struct {
+	storage.ImageRepoMock
+}
+
+func (repo slugRepoStub) GetOneByName(_ context.Context, name string) (storage.Image, error) {
+	if name != "my-new-plane" {
+		return storage.Image{}, storage.NotFound{Msg: "Image not found " + name}
+	}
+	return storage.Image{Name: "my-new-plane"}, nil
+}
+
+func (repo slugRepoStub) GetOneByOldName(_ context.Context, slug string) (storage.Image, error) {
+	if slug != "my-plane" {
+		return storage.Image{}, storage.NotFound{Msg: "Image not found " + slug}
+	}
+	return storage.Image{Name: "my-new-plane"}, nil
+}
+
+func TestGetOneBySlug(t *testing.T) {
+	service := ImagesService{imagesRepository: slugRepoStub{}}
+
+	values := []struct {
+		Name               string
+		Slug               string
+		ExpectedIsRedirect bool
+		ExpectedErr        error
+	}{
+		{Name: "Current slug", Slug: "my-new-plane"},
+		{Name: "Old slug", Slug: "my-plane", ExpectedIsRedirect: true},
+		{Name: "Unknown slug", Slug: "my-ship", ExpectedErr: exception.NotFound{}},
+		{Name: "Empty slug", Slug: "", ExpectedErr: exception.InvalidArgument{}},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			img, isRedirect, err := service.GetOneBySlug(context.Background(), data.Slug)
+			if data.ExpectedErr != nil {
+				if reflect.TypeOf(err) != reflect.TypeOf(data.ExpectedErr) {
+					t.Fatalf("Expected %T, got %v", data.ExpectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Name != "my-new-plane" || isRedirect != data.ExpectedIsRedirect {
+				t.Fatalf("Expected my-new-plane with redirect %t, got %s with %t", data.ExpectedIsRedirect, img.Name, isRedirect)
+			}
+		})
+	}
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"api/image"
+	"api/pkg/concurrency"
+	"api/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"mime/multipart"
+	"time"
+)
+
+// uploadSagaTimeout bounds the saga bookkeeping and compensation, they run detached from the request so that
+// a cancelled request is still rolled back
+const uploadSagaTimeout = 30 * time.Second
+
+// uploadSagaStaleAfter is longer than any upload request, so that recovery does not touch sagas in progress
+const uploadSagaStaleAfter = 10 * time.Minute
+
+// uploadSagaRecoveryIntervalSec is how often the sagas that became stale are recovered
+const uploadSagaRecoveryIntervalSec = 5 * 60
+
+var errUploadSagaInterrupted = errors.New("upload interrupted")
+
+// runUploadSaga uploads, resizes and saves the image, recording the resized step on the saga
+func (service *ImagesService) runUploadSaga(
+	ctx context.Context,
+	authHeader string,
+	saga *storage.UploadSaga,
+	format image.Format,
+	description storage.ImageDescription,
+	analysis fileAnalysis,
+	originalFile *multipart.FileHeader,
+	croppedFile *multipart.FileHeader,
+) (storage.Image, error) {
+	originalSigned, croppedSigned, err := service.getMultipleSignUrls(ctx, authHeader, format)
+	if err != nil {
+		return storage.Image{},
+			fmt.Errorf("error creating multiple sign urls: %w", err)
+	}
+
+	err = service.uploadBothFiles(
+		ctx,
+		originalSigned.SignedUrl,
+		croppedSigned.SignedUrl,
+		format,
+		originalFile,
+		croppedFile,
+	)
+	if err != nil {
+		return storage.Image{}, fmt.Errorf("error uploading files: %w", err)
+	}
+
+	resizeRequest := image.ResizeRequest{
+		Name:             saga.ImageName,
+		FilePath:         croppedSigned.FileName,
+		OriginalFilePath: originalSigned.FileName,
+	}
+	res, err := service.resizeApi.Resize(ctx, authHeader, resizeRequest)
+	if err != nil {
+		return storage.Image{}, fmt.Errorf("error resizing: %w", err)
+	}
+
+	saga.Step = storage.UploadSagaResized
+	resized := analysis.applyTo(storage.Image{
+		Name:     res.Name,
+		Format:   storage.ImageFormat(res.Format),
+		Original: res.Original,
+		Domain:   res.Domain,
+		Path:     res.Path,
+		Sizes:    convertImageSizesToStorageSizes(res.Sizes),
+		AuthorId: saga.AuthorId,
+		// The description is kept with the saga, so that the recovery saves the image as it was uploaded
+		ImageDescription: description,
+	})
+	saga.Image = &resized
+	if err = service.uploadSagas.Update(ctx, *saga); err != nil {
+		return storage.Image{}, fmt.Errorf("error recording resized upload: %w", err)
+	}
+
+	createdImg, err := service.imagesRepository.Create(ctx, *saga.Image)
+	if err != nil {
+		return storage.Image{}, fmt.Errorf("err saving new image to database: %w", err)
+	}
+
+	return createdImg, nil
+}
+
+// completeUploadSaga marks the saga completed, when it fails the recovery finds the saved image and completes it
+func (service *ImagesService) completeUploadSaga(saga storage.UploadSaga) {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadSagaTimeout)
+	defer cancel()
+
+	saga.Step = storage.UploadSagaCompleted
+	if err := service.uploadSagas.Update(ctx, saga); err != nil {
+		service.logger.Error().Err(err).Str("sagaId", saga.Id).Msg("failed completing upload saga")
+	}
+}
+
+// abortUploadSaga compensates the failed saga, when that fails as well the saga is left to the recovery
+func (service *ImagesService) abortUploadSaga(authHeader string, saga storage.UploadSaga, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), uploadSagaTimeout)
+	defer cancel()
+
+	if err := service.compensateUploadSaga(ctx, authHeader, saga, cause); err != nil {
+		service.logger.Error().Err(err).Str("sagaId", saga.Id).Msg("failed compensating upload saga")
+	}
+}
+
+// compensateUploadSaga removes the stored files from the CDN and marks the saga compensated. A started saga knows only
+// the name and the format, so the original is removed in case the resize stored it before the saga was updated. When
+// the image name is taken by another image the files are left in place, as they are stored under the same name.
+func (service *ImagesService) compensateUploadSaga(
+	ctx context.Context, authHeader string, saga storage.UploadSaga, cause error,
+) error {
+	var request *image.DeleteRequest
+	switch {
+	case errors.Is(cause, storage.ErrDuplicate):
+	case saga.Step == storage.UploadSagaResized && saga.Image != nil:
+		request = &image.DeleteRequest{
+			Name:       saga.Image.Name,
+			Format:     image.Format(saga.Image.Format),
+			Dimensions: convertStorageSizesToDimensions(saga.Image.Sizes),
+		}
+	case saga.Step == storage.UploadSagaStarted && saga.Format != "":
+		request = &image.DeleteRequest{Name: saga.ImageName, Format: image.Format(saga.Format)}
+	}
+
+	if request != nil {
+		if err := service.resizeApi.Delete(ctx, authHeader, *request); err != nil {
+			return fmt.Errorf("error deleting resized files: %w", err)
+		}
+		if err := service.resizeApi.Invalidate(ctx, authHeader, *request); err != nil {
+			return fmt.Errorf("error invalidating resized files: %w", err)
+		}
+	}
+
+	saga.Step = storage.UploadSagaCompensated
+	saga.Error = cause.Error()
+
+	return service.uploadSagas.Update(ctx, saga)
+}
+
+// RecoverUploadSagas finishes or rolls back the uploads that were interrupted by a crash, resized images are
+// saved when their name is still free, the rest is compensated
+func (service *ImagesService) RecoverUploadSagas(ctx context.Context) {
+	sagas, err := service.uploadSagas.GetUnfinished(ctx, uploadSagaStaleAfter)
+	if err != nil {
+		service.logger.Error().Err(err).Msg("failed fetching unfinished upload sagas")
+		return
+	}
+
+	for _, saga := range sagas {
+		if err = service.recoverUploadSaga(ctx, saga); err != nil {
+			service.logger.Error().Err(err).Str("sagaId", saga.Id).Msg("failed recovering upload saga")
+			continue
+		}
+		service.logger.Info().Str("sagaId", saga.Id).Msg("recovered upload saga")
+	}
+}
+
+func (service *ImagesService) recoverUploadSaga(ctx context.Context, saga storage.UploadSaga) error {
+	if saga.Step != storage.UploadSagaResized || saga.Image == nil {
+		return service.compensateUploadSaga(ctx, service.serviceAuthHeader, saga, errUploadSagaInterrupted)
+	}
+
+	existing, err := service.imagesRepository.GetOneByName(ctx, saga.Image.Name)
+	var notFound storage.NotFound
+	switch {
+	case err == nil && existing.Original == saga.Image.Original:
+		// Saved before the crash, only the completion was lost
+		saga.Step = storage.UploadSagaCompleted
+		return service.uploadSagas.Update(ctx, saga)
+	case err == nil:
+		return service.compensateUploadSaga(ctx, service.serviceAuthHeader, saga, storage.ErrDuplicate)
+	case !errors.As(err, &notFound):
+		return err
+	}
+
+	_, err = service.imagesRepository.Create(ctx, *saga.Image)
+	if err != nil {
+		if errors.Is(err, storage.ErrDuplicate) {
+			return service.compensateUploadSaga(ctx, service.serviceAuthHeader, saga, err)
+		}
+		return err
+	}
+
+	saga.Step = storage.UploadSagaCompleted
+	return service.uploadSagas.Update(ctx, saga)
+}
+
+// UploadSagaRecoverer periodically recovers the uploads interrupted by a crash, of this instance or of another one
+type UploadSagaRecoverer struct {
+	images *ImagesService
+	// enabled is false when the files of the rolled back uploads could not be deleted
+	enabled bool
+	logger  *zerolog.Logger
+	closed  chan error
+	cancel  context.CancelFunc
+}
+
+func NewUploadSagaRecoverer(config Config, images *ImagesService, logger *zerolog.Logger) *UploadSagaRecoverer {
+	return &UploadSagaRecoverer{
+		images:  images,
+		enabled: config.HasImagesApiServiceAccess(),
+		logger:  logger,
+		closed:  make(chan error, 1),
+	}
+}
+
+func (recoverer *UploadSagaRecoverer) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			recoverer.images.RecoverUploadSagas(ctx)
+
+			if err := concurrency.SleepSecondsWithContext(ctx, uploadSagaRecoveryIntervalSec); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (recoverer *UploadSagaRecoverer) StartAsync(ctx context.Context) {
+	if !recoverer.enabled {
+		recoverer.logger.Warn().
+			Msg("Not recovering upload sagas, env IMAGES_API_SERVICE_TOKEN is missing to delete the files")
+		return
+	}
+	recoverer.logger.Info().Msg("Started recovering upload sagas")
+
+	derivedCtx, cancel := context.WithCancel(ctx)
+	recoverer.cancel = cancel
+	go func() {
+		recoverer.closed <- recoverer.Start(derivedCtx)
+		close(recoverer.closed)
+	}()
+}
+
+func (recoverer *UploadSagaRecoverer) Shutdown() error {
+	if recoverer.cancel == nil {
+		return nil
+	}
+
+	recoverer.logger.Info().Msg("Shutting down upload saga recoverer")
+	recoverer.cancel()
+	if err := <-recoverer.closed; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
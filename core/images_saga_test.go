@@ -0,0 +1,277 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/storage"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"testing"
+	"time"
+)
+
+// sagaResizerStub resizes every image unless resizeErr is set and records the files it resizes and removes
+type sagaResizerStub struct {
+	image.Mock
+	resizeErr   error
+	resized     []string
+	deleted     []string
+	invalidated []string
+}
+
+func (resizer *sagaResizerStub) Resize(
+	_ context.Context, _ string, request image.ResizeRequest,
+) (image.ResizeResponse, error) {
+	if resizer.resizeErr != nil {
+		return image.ResizeResponse{}, resizer.resizeErr
+	}
+	resizer.resized = append(resizer.resized, request.Name)
+	return image.ResizeResponse{
+		Name:     request.Name,
+		Format:   image.PngFormat,
+		Original: "images/" + request.Name + ".png",
+		Sizes:    image.Sizes{Original: image.Dimensions{Width: 300, Height: 200}},
+	}, nil
+}
+
+func (resizer *sagaResizerStub) Delete(_ context.Context, _ string, request image.DeleteRequest) error {
+	resizer.deleted = append(resizer.deleted, request.Name)
+	return nil
+}
+
+func (resizer *sagaResizerStub) Invalidate(_ context.Context, _ string, request image.DeleteRequest) error {
+	resizer.invalidated = append(resizer.invalidated, request.Name)
+	return nil
+}
+
+type sagaRepoStub struct {
+	sagas map[string]storage.UploadSaga
+}
+
+func (repo *sagaRepoStub) Create(_ context.Context, saga storage.UploadSaga) (storage.UploadSaga, error) {
+	for _, existing := range repo.sagas {
+		if existing.ImageName == saga.ImageName && !existing.Step.IsFinished() {
+			return storage.UploadSaga{}, storage.ErrDuplicate
+		}
+	}
+	saga.Id = "saga-1"
+	repo.sagas[saga.Id] = saga
+	return saga, nil
+}
+
+func (repo *sagaRepoStub) Update(_ context.Context, saga storage.UploadSaga) error {
+	repo.sagas[saga.Id] = saga
+	return nil
+}
+
+func (repo *sagaRepoStub) GetUnfinished(_ context.Context, _ time.Duration) ([]storage.UploadSaga, error) {
+	var sagas []storage.UploadSaga
+	for _, saga := range repo.sagas {
+		if !saga.Step.IsFinished() {
+			sagas = append(sagas, saga)
+		}
+	}
+	return sagas, nil
+}
+
+// sagaImagesRepoStub stores images by name, failing creation with createErr when set
+type sagaImagesRepoStub struct {
+	storage.ImageRepoMock
+	images    map[string]storage.Image
+	createErr error
+}
+
+func (repo *sagaImagesRepoStub) DoesImageExist(_ context.Context, name string) (bool, error) {
+	_, ok := repo.images[name]
+	return ok, nil
+}
+
+func (repo *sagaImagesRepoStub) GetOneByName(_ context.Context, name string) (storage.Image, error) {
+	img, ok := repo.images[name]
+	if !ok {
+		return storage.Image{}, storage.NotFound{Msg: "Image not found " + name}
+	}
+	return img, nil
+}
+
+func (repo *sagaImagesRepoStub) Create(_ context.Context, img storage.Image) (storage.Image, error) {
+	if repo.createErr != nil {
+		return storage.Image{}, repo.createErr
+	}
+	repo.images[img.Name] = img
+	return img, nil
+}
+
+func newSagaTestService() (*ImagesService, *sagaResizerStub, *sagaImagesRepoStub, *sagaRepoStub) {
+	logger := zerolog.Nop()
+	resizer := &sagaResizerStub{}
+	images := &sagaImagesRepoStub{images: map[string]storage.Image{}}
+	sagas := &sagaRepoStub{sagas: map[string]storage.UploadSaga{}}
+	service := &ImagesService{
+		resizeApi:        resizer,
+		imagesRepository: images,
+		uploadSagas:      sagas,
+		authenticator:    &auth.Mock{},
+		audit:            NewAuditLogger(storage.AuditRepoMock{}, &logger),
+		logger:           &logger,
+	}
+
+	return service, resizer, images, sagas
+}
+
+func uploadTestImage(t *testing.T, service *ImagesService) (storage.Image, error) {
+	return service.UploadAndResize(
+		context.Background(),
+		auth.AuthorizationDto{},
+		"my plane",
+		storage.ImageDescription{},
+		image.PngFormat,
+		newTestFileHeader(t, image.PngFormat, 600, 400),
+		newTestFileHeader(t, image.PngFormat, 300, 200),
+	)
+}
+
+func TestUploadAndResize_Saga(t *testing.T) {
+	resizeErr := errors.New("resize timed out")
+
+	values := []struct {
+		Name            string
+		CreateErr       error
+		ResizeErr       error
+		ExpectedStep    storage.UploadSagaStep
+		ExpectedDeleted int
+	}{
+		{Name: "Completed", ExpectedStep: storage.UploadSagaCompleted},
+		{
+			Name:            "Failed save removes the resized files",
+			CreateErr:       errors.New("connection lost"),
+			ExpectedStep:    storage.UploadSagaCompensated,
+			ExpectedDeleted: 1,
+		},
+		{
+			Name:            "Failed resize removes the original",
+			ResizeErr:       resizeErr,
+			ExpectedStep:    storage.UploadSagaCompensated,
+			ExpectedDeleted: 1,
+		},
+		{
+			Name:         "Files of a duplicate name are kept",
+			CreateErr:    storage.ErrDuplicate,
+			ExpectedStep: storage.UploadSagaCompensated,
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service, resizer, images, sagas := newSagaTestService()
+			images.createErr = data.CreateErr
+			resizer.resizeErr = data.ResizeErr
+			expectedErr := data.CreateErr
+			if data.ResizeErr != nil {
+				expectedErr = data.ResizeErr
+			}
+
+			_, err := uploadTestImage(t, service)
+			var invalidArgument exception.InvalidArgument
+			switch {
+			case expectedErr == nil && err != nil:
+				t.Fatal(err)
+			case expectedErr == storage.ErrDuplicate && !errors.As(err, &invalidArgument):
+				t.Fatalf("Expected invalid argument, got %v", err)
+			case expectedErr != storage.ErrDuplicate && !errors.Is(err, expectedErr):
+				t.Fatalf("Expected error %v, got %v", expectedErr, err)
+			}
+
+			saga := sagas.sagas["saga-1"]
+			if saga.Step != data.ExpectedStep {
+				t.Fatalf("Expected saga step %s, got %s", data.ExpectedStep, saga.Step)
+			}
+			if len(resizer.deleted) != data.ExpectedDeleted || len(resizer.invalidated) != data.ExpectedDeleted {
+				t.Fatalf(
+					"Expected %d deleted and invalidated, got %v and %v",
+					data.ExpectedDeleted, resizer.deleted, resizer.invalidated,
+				)
+			}
+		})
+	}
+}
+
+func TestUploadAndResize_NameReservedByUpload(t *testing.T) {
+	service, resizer, _, sagas := newSagaTestService()
+	sagas.sagas["saga-0"] = storage.UploadSaga{Id: "saga-0", ImageName: "my-plane", Step: storage.UploadSagaStarted}
+
+	_, err := uploadTestImage(t, service)
+	var invalidArgument exception.InvalidArgument
+	if !errors.As(err, &invalidArgument) {
+		t.Fatalf("Expected invalid argument, got %v", err)
+	}
+	if len(resizer.resized) != 0 || len(resizer.deleted) != 0 {
+		t.Fatalf("Expected the files of the other upload untouched, got %v and %v", resizer.resized, resizer.deleted)
+	}
+}
+
+func TestRecoverUploadSagas(t *testing.T) {
+	resized := &storage.Image{Name: "my-plane", Format: storage.PngFormat, Original: "images/my-plane.png"}
+
+	values := []struct {
+		Name            string
+		Saga            storage.UploadSaga
+		Existing        *storage.Image
+		ExpectedStep    storage.UploadSagaStep
+		ExpectedStored  bool
+		ExpectedDeleted int
+	}{
+		{
+			Name: "Started saga is compensated",
+			Saga: storage.UploadSaga{
+				Id: "saga-1", ImageName: "my-plane", Format: storage.PngFormat, Step: storage.UploadSagaStarted,
+			},
+			ExpectedStep:    storage.UploadSagaCompensated,
+			ExpectedDeleted: 1,
+		},
+		{
+			Name:           "Resized saga is saved",
+			Saga:           storage.UploadSaga{Id: "saga-1", Step: storage.UploadSagaResized, Image: resized},
+			ExpectedStep:   storage.UploadSagaCompleted,
+			ExpectedStored: true,
+		},
+		{
+			Name:           "Already saved saga is completed",
+			Saga:           storage.UploadSaga{Id: "saga-1", Step: storage.UploadSagaResized, Image: resized},
+			Existing:       resized,
+			ExpectedStep:   storage.UploadSagaCompleted,
+			ExpectedStored: true,
+		},
+		{
+			Name:           "Name taken by another image keeps its files",
+			Saga:           storage.UploadSaga{Id: "saga-1", Step: storage.UploadSagaResized, Image: resized},
+			Existing:       &storage.Image{Name: "my-plane", Original: "images/other.png"},
+			ExpectedStep:   storage.UploadSagaCompensated,
+			ExpectedStored: true,
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service, resizer, images, sagas := newSagaTestService()
+			sagas.sagas[data.Saga.Id] = data.Saga
+			if data.Existing != nil {
+				images.images[data.Existing.Name] = *data.Existing
+			}
+
+			service.RecoverUploadSagas(context.Background())
+
+			if step := sagas.sagas[data.Saga.Id].Step; step != data.ExpectedStep {
+				t.Fatalf("Expected saga step %s, got %s", data.ExpectedStep, step)
+			}
+			if _, ok := images.images["my-plane"]; ok != data.ExpectedStored {
+				t.Fatalf("Expected image stored %t, got %t", data.ExpectedStored, ok)
+			}
+			if len(resizer.deleted) != data.ExpectedDeleted {
+				t.Fatalf("Expected %d deleted, got %v", data.ExpectedDeleted, resizer.deleted)
+			}
+		})
+	}
+}
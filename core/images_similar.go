@@ -0,0 +1,85 @@
+package core
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"fmt"
+)
+
+// similarImagesLimit bounds the look alikes returned for an image, the closest ones come first
+const similarImagesLimit = 20
+
+// DuplicateMode decides about uploads whose cropped file looks like an image that is already stored
+type DuplicateMode string
+
+const (
+	WarnDuplicates   DuplicateMode = "warn"
+	RejectDuplicates DuplicateMode = "reject"
+	AllowDuplicates  DuplicateMode = "allow"
+)
+
+// checkDuplicates finds the stored images that look like the upload, other than the image being updated. They are
+// refused in the reject mode and returned to be shown to the uploader in the warn mode.
+func (service *ImagesService) checkDuplicates(
+	ctx context.Context, hash storage.PerceptualHash, imageId string,
+) ([]storage.SimilarImage, error) {
+	if service.uploadRules.Duplicates == AllowDuplicates {
+		return nil, nil
+	}
+
+	duplicates, err := service.imagesRepository.GetSimilar(
+		ctx, hash, service.uploadRules.MaxDistance, imageId, similarImagesLimit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed finding duplicates: %w", err)
+	}
+	if len(duplicates) == 0 {
+		return nil, nil
+	}
+
+	if service.uploadRules.Duplicates == RejectDuplicates {
+		return nil, exception.InvalidArgument{
+			Reason: fmt.Sprintf("Image looks like a duplicate of the existing image %s", duplicates[0].Name),
+		}
+	}
+	service.logger.Warn().
+		Str("duplicateOf", duplicates[0].Id).
+		Int("distance", duplicates[0].Distance).
+		Msg("uploaded image looks like an existing one")
+
+	return duplicates, nil
+}
+
+// GetSimilarImages lists the images that look like the image, within maxDistance differing bits of their perceptual
+// hashes, or the configured distance when negative
+func (service *ImagesService) GetSimilarImages(
+	ctx context.Context, imageId string, maxDistance int,
+) ([]storage.SimilarImage, error) {
+	if err := parseUuids(imageId); err != nil {
+		return nil, err
+	}
+	if maxDistance > 64 {
+		return nil, exception.InvalidArgument{Reason: "Distance must be between 0 and 64"}
+	}
+	if maxDistance < 0 {
+		maxDistance = service.uploadRules.MaxDistance
+	}
+
+	img, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return nil, toImageError(err)
+	}
+	if img.PerceptualHash == nil {
+		return []storage.SimilarImage{}, nil
+	}
+
+	similar, err := service.imagesRepository.GetSimilar(
+		ctx, *img.PerceptualHash, maxDistance, img.Id, similarImagesLimit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching images similar to %s: %w", imageId, err)
+	}
+
+	return similar, nil
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/storage"
+	"context"
+	"errors"
+	"testing"
+)
+
+// similarImagesRepoStub finds the look alikes among the images stored by name
+type similarImagesRepoStub struct {
+	*sagaImagesRepoStub
+}
+
+func (repo similarImagesRepoStub) GetSimilar(
+	_ context.Context, hash storage.PerceptualHash, maxDistance int, excludeId string, _ int,
+) ([]storage.SimilarImage, error) {
+	similar := make([]storage.SimilarImage, 0)
+	for _, img := range repo.images {
+		if img.PerceptualHash == nil || (excludeId != "" && img.Id == excludeId) {
+			continue
+		}
+		if distance := hash.Distance(*img.PerceptualHash); distance <= maxDistance {
+			similar = append(similar, storage.SimilarImage{Image: img, Distance: distance})
+		}
+	}
+	return similar, nil
+}
+
+func (repo similarImagesRepoStub) GetOne(_ context.Context, imageId string) (storage.Image, error) {
+	for _, img := range repo.images {
+		if img.Id == imageId {
+			return img, nil
+		}
+	}
+	return storage.Image{}, storage.NotFound{Msg: "Image not found " + imageId}
+}
+
+func TestUploadAndResize_Duplicates(t *testing.T) {
+	values := []struct {
+		Mode               DuplicateMode
+		IsRejected         bool
+		ExpectedDuplicates int
+	}{
+		{Mode: WarnDuplicates, ExpectedDuplicates: 1},
+		{Mode: RejectDuplicates, IsRejected: true},
+		{Mode: AllowDuplicates},
+	}
+
+	for _, data := range values {
+		t.Run(string(data.Mode), func(t *testing.T) {
+			service, _, images, sagas := newSagaTestService()
+			service.imagesRepository = similarImagesRepoStub{images}
+			service.uploadRules = UploadRules{Duplicates: data.Mode, MaxDistance: 5}
+			if _, err := uploadTestImage(t, service); err != nil {
+				t.Fatal(err)
+			}
+
+			uploaded, err := service.UploadAndResize(
+				context.Background(),
+				auth.AuthorizationDto{},
+				"my plane copy",
+				storage.ImageDescription{},
+				image.PngFormat,
+				newTestFileHeader(t, image.PngFormat, 600, 400),
+				newTestFileHeader(t, image.PngFormat, 300, 200),
+			)
+			if data.IsRejected {
+				var invalidArgument exception.InvalidArgument
+				if !errors.As(err, &invalidArgument) {
+					t.Fatalf("Expected invalid argument, got %v", err)
+				}
+				if len(sagas.sagas) != 1 {
+					t.Fatalf("Expected only the first upload to start, got %d sagas", len(sagas.sagas))
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if uploaded.PerceptualHash == nil || uploaded.BlurHash == nil || uploaded.DominantColor == nil {
+				t.Fatalf("Expected the perceptual hash and the placeholders to be stored, got %+v", uploaded)
+			}
+			if len(uploaded.Duplicates) != data.ExpectedDuplicates {
+				t.Fatalf("Expected %d duplicates, got %d", data.ExpectedDuplicates, len(uploaded.Duplicates))
+			}
+		})
+	}
+}
+
+func TestGetSimilarImages(t *testing.T) {
+	hash := storage.PerceptualHash(0x0f0f)
+	farHash := storage.PerceptualHash(-1)
+	images := &sagaImagesRepoStub{images: map[string]storage.Image{
+		"plane":      {Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263", Name: "plane", PerceptualHash: &hash},
+		"plane-copy": {Id: "5e9b0f33-1d55-4d6e-9a0c-6f1e43b3c1a2", Name: "plane-copy", PerceptualHash: &hash},
+		"boat":       {Id: "9a3c8f21-4b7e-4f0a-8d2b-2c7e5d1f0b94", Name: "boat", PerceptualHash: &farHash},
+		"not-hashed": {Id: "c1b2a3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", Name: "not-hashed"},
+	}}
+	service := ImagesService{
+		imagesRepository: similarImagesRepoStub{images},
+		uploadRules:      UploadRules{MaxDistance: 5},
+	}
+
+	similar, err := service.GetSimilarImages(context.Background(), "3c47d736-6c4e-4a1c-a04b-3744cc30b263", -1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 1 || similar[0].Name != "plane-copy" || similar[0].Distance != 0 {
+		t.Fatalf("Expected only the copy of the plane, got %+v", similar)
+	}
+
+	similar, err = service.GetSimilarImages(context.Background(), "c1b2a3d4-e5f6-4a7b-8c9d-0e1f2a3b4c5d", 64)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 0 {
+		t.Fatalf("Expected no look alikes of an image without a hash, got %+v", similar)
+	}
+
+	_, err = service.GetSimilarImages(context.Background(), "3c47d736-6c4e-4a1c-a04b-3744cc30b263", 65)
+	var invalidArgument exception.InvalidArgument
+	if !errors.As(err, &invalidArgument) {
+		t.Fatalf("Expected invalid argument, got %v", err)
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"fmt"
+	"golang.org/x/text/language"
+	"strings"
+)
+
+// parseLocale turns the locale into its canonical BCP 47 form, so that de-de and de-DE are the same translation
+func parseLocale(locale string) (language.Tag, error) {
+	tag, err := language.Parse(strings.TrimSpace(locale))
+	if err != nil || tag == language.Und {
+		return language.Und, exception.InvalidArgument{Reason: fmt.Sprintf("Invalid locale %s", locale)}
+	}
+	return tag, nil
+}
+
+// GetTranslations returns the translations of the image ordered by locale
+func (service *ImagesService) GetTranslations(ctx context.Context, imageId string) ([]storage.ImageTranslation, error) {
+	if err := parseUuids(imageId); err != nil {
+		return nil, err
+	}
+	if _, err := service.imagesRepository.GetOne(ctx, imageId); err != nil {
+		return nil, toImageError(err)
+	}
+
+	translations, err := service.imagesRepository.GetTranslations(ctx, imageId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching translations: %w", err)
+	}
+
+	return translations, nil
+}
+
+// SetTranslation creates or overwrites the alt text and the caption of the image in the locale. The texts in the
+// default locale are the description of the image, so they can not be translated.
+func (service *ImagesService) SetTranslation(
+	ctx context.Context, authorization auth.AuthorizationDto, imageId, locale, altText, caption string,
+) (storage.ImageTranslation, error) {
+	if err := parseUuids(imageId); err != nil {
+		return storage.ImageTranslation{}, err
+	}
+	tag, err := parseLocale(locale)
+	if err != nil {
+		return storage.ImageTranslation{}, err
+	}
+	if tag == service.defaultLocale {
+		return storage.ImageTranslation{}, exception.InvalidArgument{
+			Reason: fmt.Sprintf("Texts in the default locale %s are set on the image itself", tag),
+		}
+	}
+	description, err := validateDescription(storage.ImageDescription{AltText: altText, Caption: caption})
+	if err != nil {
+		return storage.ImageTranslation{}, err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.ImageTranslation{}, err
+	}
+	previous, err := service.findTranslation(ctx, imageId, tag.String())
+	if err != nil {
+		return storage.ImageTranslation{}, err
+	}
+
+	translation, err := service.imagesRepository.SetTranslation(ctx, storage.ImageTranslation{
+		ImageId: imageId,
+		Locale:  tag.String(),
+		AltText: description.AltText,
+		Caption: description.Caption,
+	})
+	if err != nil {
+		return storage.ImageTranslation{}, toImageError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageTranslated, imageId, previous, translation)
+
+	return translation, nil
+}
+
+func (service *ImagesService) DeleteTranslation(
+	ctx context.Context, authorization auth.AuthorizationDto, imageId, locale string,
+) error {
+	if err := parseUuids(imageId); err != nil {
+		return err
+	}
+	tag, err := parseLocale(locale)
+	if err != nil {
+		return err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return err
+	}
+	previous, err := service.findTranslation(ctx, imageId, tag.String())
+	if err != nil {
+		return err
+	}
+
+	if err = service.imagesRepository.DeleteTranslation(ctx, imageId, tag.String()); err != nil {
+		return toImageError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageTranslated, imageId, previous, nil)
+
+	return nil
+}
+
+// findTranslation returns nil when the image has no translation in the locale
+func (service *ImagesService) findTranslation(
+	ctx context.Context, imageId, locale string,
+) (*storage.ImageTranslation, error) {
+	translations, err := service.imagesRepository.GetTranslations(ctx, imageId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching translations: %w", err)
+	}
+	for _, translation := range translations {
+		if translation.Locale == locale {
+			return &translation, nil
+		}
+	}
+	return nil, nil
+}
+
+// localize sets the alt text and the caption of every image to the translation in the locale that matches the
+// preferences best, images without a matching translation keep the texts of the default locale
+func (service *ImagesService) localize(
+	ctx context.Context, images []storage.Image, preferences []language.Tag,
+) error {
+	for i := range images {
+		images[i].Locale = service.defaultLocale.String()
+	}
+	if len(images) == 0 || len(preferences) == 0 {
+		return nil
+	}
+
+	imageIds := make([]string, len(images))
+	for i, img := range images {
+		imageIds[i] = img.Id
+	}
+	translations, err := service.imagesRepository.GetTranslations(ctx, imageIds...)
+	if err != nil {
+		return fmt.Errorf("failed fetching translations: %w", err)
+	}
+	byImage := make(map[string][]storage.ImageTranslation)
+	for _, translation := range translations {
+		byImage[translation.ImageId] = append(byImage[translation.ImageId], translation)
+	}
+
+	for i, img := range images {
+		if translation := service.matchTranslation(byImage[img.Id], preferences); translation != nil {
+			images[i].AltText = translation.AltText
+			images[i].Caption = translation.Caption
+			images[i].Locale = translation.Locale
+		}
+	}
+
+	return nil
+}
+
+// matchTranslation returns nil when the default locale matches the preferences best
+func (service *ImagesService) matchTranslation(
+	translations []storage.ImageTranslation, preferences []language.Tag,
+) *storage.ImageTranslation {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	// The default locale comes first, it is what the matcher falls back to
+	supported := []language.Tag{service.defaultLocale}
+	candidates := []*storage.ImageTranslation{nil}
+	for i, translation := range translations {
+		tag, err := language.Parse(translation.Locale)
+		if err != nil {
+			continue
+		}
+		supported = append(supported, tag)
+		candidates = append(candidates, &translations[i])
+	}
+
+	_, index, confidence := language.NewMatcher(supported).Match(preferences...)
+	if confidence == language.No {
+		return nil
+	}
+	return candidates[index]
+}
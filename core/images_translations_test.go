@@ -0,0 +1,100 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"errors"
+	"golang.org/x/text/language"
+	"strings"
+	"testing"
+)
+
+// translationsRepoStub has the first image translated to German and Brazilian Portuguese
+type translationsRepoStub struct {
+	storage.ImageRepoMock
+}
+
+func (repo translationsRepoStub) GetTranslations(
+	_ context.Context, _ ...string,
+) ([]storage.ImageTranslation, error) {
+	return []storage.ImageTranslation{
+		{ImageId: "image-one", Locale: "de", AltText: "Ein Flugzeug", Caption: "Über den Klippen"},
+		{ImageId: "image-one", Locale: "pt-BR", AltText: "Um avião"},
+	}, nil
+}
+
+func TestLocalize(t *testing.T) {
+	service := ImagesService{imagesRepository: translationsRepoStub{}, defaultLocale: language.English}
+
+	values := []struct {
+		Name            string
+		Preferences     string
+		ExpectedLocale  string
+		ExpectedAltText string
+	}{
+		{Name: "No preferences", Preferences: "", ExpectedLocale: "en", ExpectedAltText: "A plane"},
+		{Name: "Translated", Preferences: "de", ExpectedLocale: "de", ExpectedAltText: "Ein Flugzeug"},
+		{Name: "Regional variant", Preferences: "de-AT", ExpectedLocale: "de", ExpectedAltText: "Ein Flugzeug"},
+		{Name: "Region of the translation", Preferences: "pt", ExpectedLocale: "pt-BR", ExpectedAltText: "Um avião"},
+		{Name: "Default preferred", Preferences: "en, de;q=0.5", ExpectedLocale: "en", ExpectedAltText: "A plane"},
+		{Name: "Second choice", Preferences: "fr, de;q=0.5", ExpectedLocale: "de", ExpectedAltText: "Ein Flugzeug"},
+		{Name: "Not translated", Preferences: "fr", ExpectedLocale: "en", ExpectedAltText: "A plane"},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			preferences, _, err := language.ParseAcceptLanguage(data.Preferences)
+			if err != nil {
+				t.Fatal(err)
+			}
+			images := []storage.Image{
+				{Id: "image-one", ImageDescription: storage.ImageDescription{AltText: "A plane", Title: "Spitfire"}},
+				{Id: "image-two", ImageDescription: storage.ImageDescription{AltText: "A tank"}},
+			}
+
+			if err = service.localize(context.Background(), images, preferences); err != nil {
+				t.Fatal(err)
+			}
+			if images[0].Locale != data.ExpectedLocale || images[0].AltText != data.ExpectedAltText {
+				t.Fatalf("Expected %s in %s, got %+v", data.ExpectedAltText, data.ExpectedLocale, images[0])
+			}
+			if images[0].Title != "Spitfire" {
+				t.Fatalf("Expected the title to be kept, got %s", images[0].Title)
+			}
+			if images[1].Locale != "en" || images[1].AltText != "A tank" {
+				t.Fatalf("Expected the untranslated image in the default locale, got %+v", images[1])
+			}
+		})
+	}
+}
+
+func TestSetTranslation_Invalid(t *testing.T) {
+	service := ImagesService{imagesRepository: translationsRepoStub{}, defaultLocale: language.English}
+	imageId := "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	values := []struct {
+		Name    string
+		ImageId string
+		Locale  string
+		AltText string
+	}{
+		{Name: "Invalid image", ImageId: "john", Locale: "de"},
+		{Name: "Invalid locale", ImageId: imageId, Locale: "12345"},
+		{Name: "Default locale", ImageId: imageId, Locale: "EN"},
+		{Name: "Alt text too long", ImageId: imageId, Locale: "de", AltText: strings.Repeat("a", 251)},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			_, err := service.SetTranslation(
+				context.Background(), auth.AuthorizationDto{}, data.ImageId, data.Locale, data.AltText, "",
+			)
+			var invalidArgument exception.InvalidArgument
+			if !errors.As(err, &invalidArgument) {
+				t.Fatalf("Expected invalid argument, got %v", err)
+			}
+		})
+	}
+}
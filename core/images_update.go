@@ -6,187 +6,282 @@ import (
 	"api/image"
 	"api/storage"
 	"context"
+	"errors"
 	"fmt"
+	"github.com/google/uuid"
 	"mime/multipart"
 )
 
+func toImageError(err error) error {
+	var notFound storage.NotFound
+	if errors.As(err, &notFound) {
+		return exception.NotFound{Msg: notFound.Msg}
+	}
+	if errors.Is(err, storage.ErrDuplicate) {
+		return exception.InvalidArgument{Reason: "Image name already exists, please use another"}
+	}
+	return err
+}
+
+// Update supports three modes: name only, files only and files with a new name. The description can be changed
+// along with any of them or on its own.
 func (service *ImagesService) Update(
 	ctx context.Context,
 	imageId string,
 	authorization auth.AuthorizationDto,
 	imageName string,
+	description storage.ImageDescriptionUpdate,
 	format image.Format,
 	originalFile *multipart.FileHeader,
 	croppedFile *multipart.FileHeader,
 ) (storage.Image, error) {
 	isFileUpload := string(format) != "" && originalFile != nil && croppedFile != nil
-	if !isFileUpload && imageName == "" {
+	if !isFileUpload && imageName == "" && description.IsEmpty() {
 		return storage.Image{}, exception.InvalidArgument{
-			Reason: "Expected at least a name change or a file change, got all empty",
+			Reason: "Expected at least a name, file or description change, got all empty",
+		}
+	}
+	if err := parseUuids(imageId); err != nil {
+		return storage.Image{}, err
+	}
+	var analysis fileAnalysis
+	if isFileUpload {
+		if err := service.uploadRules.validateFiles(format, originalFile, croppedFile); err != nil {
+			return storage.Image{}, err
+		}
+		var err error
+		if analysis, err = service.analyzeFiles(format, originalFile, croppedFile); err != nil {
+			return storage.Image{}, err
 		}
 	}
 
-	_, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
 	if err != nil {
 		return storage.Image{}, err
 	}
 
 	img, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toImageError(err)
+	}
+
+	var duplicates []storage.SimilarImage
+	if isFileUpload {
+		if duplicates, err = service.checkDuplicates(ctx, analysis.hash, img.Id); err != nil {
+			return storage.Image{}, err
+		}
+	}
+
+	var seoImageName string
+	if imageName != "" {
+		if seoImageName, err = service.validateNewImageName(ctx, img, imageName); err != nil {
+			return storage.Image{}, err
+		}
+	}
+	newDescription, err := validateDescription(description.ApplyTo(img.ImageDescription))
 	if err != nil {
 		return storage.Image{}, err
 	}
+	isDescribed := newDescription != img.ImageDescription
 
-	if isFileUpload && imageName != "" {
-		return service.updateImageAndName(
-			ctx, authorization.Header, imageName, format, img, originalFile, croppedFile,
-		)
-	} else if imageName == "" {
-		return service.updateImageOnly(
-			ctx, authorization, imageId, format, originalFile, croppedFile,
+	var updated storage.Image
+	switch {
+	case isFileUpload:
+		newName := seoImageName
+		if newName == "" {
+			newName = img.Name
+		}
+		updated, err = service.updateFiles(
+			ctx, authorization.Header, newName, format, img, analysis, originalFile, croppedFile,
 		)
+	case seoImageName != "":
+		updated, err = service.updateNameOnly(ctx, authorization.Header, img, seoImageName)
+	case isDescribed:
+		updated = img
+	default:
+		// Renaming to the current name without files or a new description changes nothing
+		return img, nil
+	}
+	if err != nil {
+		return storage.Image{}, toImageError(err)
 	}
+	if isDescribed {
+		metadata := updated.Metadata
+		if updated, err = service.imagesRepository.SetDescription(ctx, img.Id, newDescription); err != nil {
+			return storage.Image{}, toImageError(err)
+		}
+		updated.Metadata = metadata
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageUpdated, img.Id, img, updated)
+	updated.Duplicates = duplicates
+
+	return updated, nil
+}
 
-	_, err = service.updateNameOnly(ctx, authorization.Header, img, imageName)
+// validateNewImageName returns the SEO name, or empty when it equals the current one
+func (service *ImagesService) validateNewImageName(
+	ctx context.Context, img storage.Image, imageName string,
+) (string, error) {
+	seoImageName, err := service.generateImageName(ctx, imageName, img.Name)
 	if err != nil {
-		return storage.Image{}, err
+		return "", err
+	}
+	if seoImageName == img.Name {
+		return "", nil
 	}
 
-	return service.imagesRepository.SetNameById(ctx, imageId, imageName)
+	return seoImageName, nil
 }
 
 func (service *ImagesService) updateNameOnly(
 	ctx context.Context,
 	authHeader string,
 	img storage.Image,
-	newImageName string,
-) (image.ResizeResponse, error) {
+	seoImageName string,
+) (storage.Image, error) {
 	request := image.RenameRequest{
 		Name:    img.Name,
-		NewName: newImageName,
+		NewName: seoImageName,
 		Format:  image.Format(img.Format),
 		SizeMap: fromStorageImageSizesToImageSizes(img.Sizes),
 	}
 
 	response, err := service.resizeApi.Rename(ctx, authHeader, request)
 	if err != nil {
-		return image.ResizeResponse{}, err
+		return storage.Image{}, err
+	}
+
+	// When the files are moved the paths change as well, otherwise only the name is stored
+	if response.Original == "" {
+		return service.imagesRepository.SetNameById(ctx, img.Id, seoImageName)
 	}
 
-	return response, nil
+	return service.saveResized(ctx, img, response, nil)
 }
 
-func (service *ImagesService) updateImageOnly(
+// updateFiles resizes the uploaded files under the name, the current files of the image are first moved to a
+// version so that resizing under an unchanged name does not overwrite them
+func (service *ImagesService) updateFiles(
 	ctx context.Context,
-	authDto auth.AuthorizationDto,
-	imageId string,
+	authHeader string,
+	name string,
 	format image.Format,
+	img storage.Image,
+	analysis fileAnalysis,
 	originalFile *multipart.FileHeader,
 	croppedFile *multipart.FileHeader,
 ) (storage.Image, error) {
-	img, err := service.imagesRepository.GetOne(ctx, imageId)
+	original, cropped, err := service.getMultipleSignUrls(ctx, authHeader, format)
 	if err != nil {
 		return storage.Image{}, err
 	}
 
-	originalSignedUrl, croppedSignedUrl, err := service.
-		getMultipleSignUrls(ctx, authDto.Header, format)
-	if err != nil {
+	if err = service.uploadBothFiles(
+		ctx, original.SignedUrl, cropped.SignedUrl, format, originalFile, croppedFile,
+	); err != nil {
 		return storage.Image{}, err
 	}
 
-	request := image.DeleteRequest{
-		Name:       img.Name,
-		Format:     format,
-		Dimensions: convertStorageSizesToDimensions(img.Sizes),
-	}
-	err = service.resizeApi.Delete(ctx, authDto.Header, request)
+	replaced, err := service.keepFiles(ctx, authHeader, img)
 	if err != nil {
 		return storage.Image{}, err
 	}
 
-	err = service.uploadBothFiles(
-		ctx,
-		originalSignedUrl.SignedUrl,
-		croppedSignedUrl.SignedUrl,
-		format,
-		originalFile,
-		croppedFile,
-	)
+	resizeRequest := image.ResizeRequest{
+		Name:             name,
+		FilePath:         cropped.FileName,
+		OriginalFilePath: original.FileName,
+	}
+	res, err := service.resizeApi.Resize(ctx, authHeader, resizeRequest)
 	if err != nil {
+		service.moveFilesBack(ctx, authHeader, replaced.FileName, img.Name, img.Format, img.Sizes)
 		return storage.Image{}, err
 	}
 
-	imageResizeRequest := image.ResizeRequest{
-		Name:             img.Name,
-		FilePath:         croppedSignedUrl.FileName,
-		OriginalFilePath: originalSignedUrl.FileName,
-	}
-	_, err = service.resizeApi.Resize(ctx, authDto.Header, imageResizeRequest)
+	saved, err := service.saveResized(ctx, analysis.applyTo(img), res, &replaced)
 	if err != nil {
+		service.moveFilesBack(ctx, authHeader, replaced.FileName, img.Name, img.Format, img.Sizes)
 		return storage.Image{}, err
 	}
 
-	return img, nil
+	return saved, nil
 }
 
-func (service *ImagesService) updateImageAndName(
-	ctx context.Context,
-	authHeader string,
-	imageName string,
-	format image.Format,
-	img storage.Image,
-	originalFile *multipart.FileHeader,
-	croppedFile *multipart.FileHeader,
-) (storage.Image, error) {
-	seoImageName := FormatForSeo(imageName)
-	if seoImageName == "" {
-		return storage.Image{}, exception.InvalidArgument{
-			Reason: fmt.Sprintf("Invalid image name of %s", imageName),
-		}
-	}
+// versionFileName is a name no image can take since slugs have no slashes, so uploads never overwrite the files
+// of a version
+func versionFileName(imageId string) string {
+	return fmt.Sprintf("versions/%s/%s", imageId, uuid.NewString())
+}
 
-	original, cropped, err := service.getMultipleSignUrls(ctx, authHeader, format)
+// keepFiles moves the current files of the image under a new version name, the returned version has the paths
+// the files were moved to
+func (service *ImagesService) keepFiles(
+	ctx context.Context, authHeader string, img storage.Image,
+) (storage.ImageVersion, error) {
+	fileName := versionFileName(img.Id)
+	res, err := service.moveFiles(ctx, authHeader, img.Name, fileName, img.Format, img.Sizes)
 	if err != nil {
-		return storage.Image{}, err
+		return storage.ImageVersion{}, fmt.Errorf("failed keeping the files of image %s: %w", img.Id, err)
 	}
 
-	if err = service.uploadBothFiles(
-		ctx, original.SignedUrl, cropped.SignedUrl, format, originalFile, croppedFile,
-	); err != nil {
-		return storage.Image{}, err
-	}
+	return storage.ImageVersion{FileName: fileName, Original: res.Original, Domain: res.Domain, Path: res.Path}, nil
+}
 
-	request := image.DeleteRequest{
-		Name:       imageName,
-		Format:     format,
-		Dimensions: convertStorageSizesToDimensions(img.Sizes),
+func (service *ImagesService) moveFiles(
+	ctx context.Context, authHeader, name, newName string, format storage.ImageFormat, sizes storage.ImageSizes,
+) (image.ResizeResponse, error) {
+	request := image.RenameRequest{
+		Name:    name,
+		NewName: newName,
+		Format:  image.Format(format),
+		SizeMap: fromStorageImageSizesToImageSizes(sizes),
 	}
-	if err = service.resizeApi.Delete(ctx, authHeader, request); err != nil {
-		return storage.Image{}, err
+	return service.resizeApi.Rename(ctx, authHeader, request)
+}
+
+// moveFilesBack undoes a move after the update failed, a failure is only logged as the error of the update is the
+// one returned
+func (service *ImagesService) moveFilesBack(
+	ctx context.Context, authHeader, name, newName string, format storage.ImageFormat, sizes storage.ImageSizes,
+) {
+	if _, err := service.moveFiles(ctx, authHeader, name, newName, format, sizes); err != nil {
+		service.logger.Error().Err(err).Str("name", name).Str("newName", newName).Msg("failed moving back files")
 	}
+}
 
-	resizeRequest := image.ResizeRequest{
-		Name:             imageName,
-		FilePath:         cropped.FileName,
-		OriginalFilePath: original.FileName,
+// saveResized stores the files described by the resize response together with what was computed from the cropped
+// file of the image, keeping the replaced files as a version when they were moved to one
+func (service *ImagesService) saveResized(
+	ctx context.Context, img storage.Image, res image.ResizeResponse, replaced *storage.ImageVersion,
+) (storage.Image, error) {
+	newImage := storage.Image{
+		Id:             img.Id,
+		Name:           res.Name,
+		Format:         storage.ImageFormat(res.Format),
+		Original:       res.Original,
+		Domain:         res.Domain,
+		Path:           res.Path,
+		Sizes:          convertImageSizesToStorageSizes(res.Sizes),
+		AuthorId:       img.AuthorId,
+		PerceptualHash: img.PerceptualHash,
+		BlurHash:       img.BlurHash,
+		DominantColor:  img.DominantColor,
+		Metadata:       img.Metadata,
+	}
+	var err error
+	if replaced != nil {
+		err = service.imagesRepository.ReplaceFiles(ctx, newImage, *replaced)
+	} else {
+		err = service.imagesRepository.UpdateOne(ctx, newImage)
 	}
-	res, err := service.resizeApi.Resize(ctx, authHeader, resizeRequest)
 	if err != nil {
 		return storage.Image{}, err
 	}
 
-	newImage := storage.Image{
-		Name:     res.Name,
-		Format:   storage.ImageFormat(res.Format),
-		Original: res.Original,
-		Domain:   res.Domain,
-		Path:     res.Path,
-		Sizes:    convertImageSizesToStorageSizes(res.Sizes),
-		AuthorId: img.AuthorId,
-	}
-	if err = service.imagesRepository.UpdateOne(ctx, newImage); err != nil {
+	saved, err := service.imagesRepository.GetOne(ctx, img.Id)
+	if err != nil {
 		return storage.Image{}, err
 	}
-
-	return newImage, nil
+	saved.Metadata = newImage.Metadata
+	return saved, nil
 }
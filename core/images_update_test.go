@@ -0,0 +1,236 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/pkg/slug"
+	"api/storage"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+const updateImageIdMock = "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+// updateRepoStub keeps a single image and records the writes made by the update
+type updateRepoStub struct {
+	storage.ImageRepoMock
+	image       storage.Image
+	newName     string
+	updateId    string
+	keptVersion bool
+	description *storage.ImageDescription
+}
+
+func (repo *updateRepoStub) GetOne(_ context.Context, imageId string) (storage.Image, error) {
+	if imageId != repo.image.Id {
+		return storage.Image{}, storage.NotFound{Msg: "Image not found " + imageId}
+	}
+	return repo.image, nil
+}
+
+func (repo *updateRepoStub) DoesImageExist(_ context.Context, name string) (bool, error) {
+	return name == "taken-name", nil
+}
+
+func (repo *updateRepoStub) SetNameById(_ context.Context, _, newName string) (storage.Image, error) {
+	repo.newName = newName
+	renamed := repo.image
+	renamed.Name = newName
+	return renamed, nil
+}
+
+func (repo *updateRepoStub) UpdateOne(_ context.Context, updates storage.Image) error {
+	repo.updateId = updates.Id
+	return nil
+}
+
+func (repo *updateRepoStub) ReplaceFiles(_ context.Context, updates storage.Image, _ storage.ImageVersion) error {
+	repo.updateId = updates.Id
+	repo.keptVersion = true
+	return nil
+}
+
+func (repo *updateRepoStub) SetDescription(
+	_ context.Context, _ string, description storage.ImageDescription,
+) (storage.Image, error) {
+	repo.description = &description
+	described := repo.image
+	described.ImageDescription = description
+	return described, nil
+}
+
+func newUpdateTestService() (*ImagesService, *updateRepoStub) {
+	logger := zerolog.Nop()
+	repo := &updateRepoStub{image: storage.Image{Id: updateImageIdMock, Name: "my-plane", Format: storage.PngFormat}}
+	service := &ImagesService{
+		resizeApi:        image.Mock{},
+		imagesRepository: repo,
+		authenticator:    &auth.Mock{},
+		audit:            NewAuditLogger(storage.AuditRepoMock{}, &logger),
+		logger:           &logger,
+		slugMode:         slug.Strict,
+	}
+
+	return service, repo
+}
+
+func TestUpdate_Invalid(t *testing.T) {
+	values := []struct {
+		Name      string
+		ImageId   string
+		ImageName string
+	}{
+		{Name: "Nothing to update", ImageId: updateImageIdMock},
+		{Name: "Invalid id", ImageId: "john", ImageName: "new name"},
+		{Name: "Invalid name", ImageId: updateImageIdMock, ImageName: "!!!"},
+		{Name: "Taken name", ImageId: updateImageIdMock, ImageName: "taken name"},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service, _ := newUpdateTestService()
+			_, err := service.Update(
+				context.Background(), data.ImageId, auth.AuthorizationDto{}, data.ImageName,
+				storage.ImageDescriptionUpdate{}, "", nil, nil,
+			)
+			var invalidArgument exception.InvalidArgument
+			if !errors.As(err, &invalidArgument) {
+				t.Fatalf("Expected invalid argument, got %v", err)
+			}
+		})
+	}
+}
+
+func TestUpdate_NotFound(t *testing.T) {
+	service, _ := newUpdateTestService()
+
+	_, err := service.Update(
+		context.Background(), "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10", auth.AuthorizationDto{}, "new name",
+		storage.ImageDescriptionUpdate{}, "", nil, nil,
+	)
+	var notFound exception.NotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
+
+func TestUpdate_NameOnly(t *testing.T) {
+	service, repo := newUpdateTestService()
+
+	updated, err := service.Update(
+		context.Background(), updateImageIdMock, auth.AuthorizationDto{}, "my new plane",
+		storage.ImageDescriptionUpdate{}, "", nil, nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.newName != "my-new-plane" || updated.Name != "my-new-plane" {
+		t.Fatalf("Expected image renamed to my-new-plane, got %s", updated.Name)
+	}
+	if repo.updateId != "" {
+		t.Fatalf("Expected files to stay untouched, got update of %s", repo.updateId)
+	}
+}
+
+func TestUpdate_NameAutoSuffix(t *testing.T) {
+	values := []struct {
+		Name      string
+		ImageName string
+		Expected  string
+	}{
+		{Name: "Taken name gets a suffix", ImageName: "Taken Name", Expected: "taken-name-2"},
+		{Name: "Transliterated name", ImageName: "Мој Авион", Expected: "moj-avion"},
+		{Name: "Current name is kept", ImageName: "My Plane!", Expected: ""},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service, repo := newUpdateTestService()
+			service.slugMode = slug.AutoSuffix
+
+			_, err := service.Update(
+				context.Background(), updateImageIdMock, auth.AuthorizationDto{}, data.ImageName,
+				storage.ImageDescriptionUpdate{}, "", nil, nil,
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if repo.newName != data.Expected {
+				t.Fatalf("Expected new name %q, got %q", data.Expected, repo.newName)
+			}
+		})
+	}
+}
+
+func TestUpdate_FilesOnly(t *testing.T) {
+	service, repo := newUpdateTestService()
+
+	_, err := service.Update(
+		context.Background(),
+		updateImageIdMock,
+		auth.AuthorizationDto{},
+		"",
+		storage.ImageDescriptionUpdate{},
+		image.JpgFormat,
+		newTestFileHeader(t, image.JpgFormat, 600, 400),
+		newTestFileHeader(t, image.JpgFormat, 300, 200),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.updateId != updateImageIdMock || !repo.keptVersion {
+		t.Fatalf("Expected update of %s keeping a version, got %s", updateImageIdMock, repo.updateId)
+	}
+	if repo.newName != "" {
+		t.Fatalf("Expected name to stay untouched, got %s", repo.newName)
+	}
+}
+
+func TestUpdate_DescriptionOnly(t *testing.T) {
+	service, repo := newUpdateTestService()
+	repo.image.Credit = "John Doe"
+	altText := "  A fighter plane flying over white cliffs "
+	license := "CC-BY"
+
+	updated, err := service.Update(
+		context.Background(),
+		updateImageIdMock,
+		auth.AuthorizationDto{},
+		"",
+		storage.ImageDescriptionUpdate{AltText: &altText, License: &license},
+		"",
+		nil,
+		nil,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := storage.ImageDescription{
+		AltText: "A fighter plane flying over white cliffs", Credit: "John Doe", License: "cc-by",
+	}
+	if repo.description == nil || *repo.description != expected || updated.ImageDescription != expected {
+		t.Fatalf("Expected description %+v, got %+v", expected, updated.ImageDescription)
+	}
+	if repo.newName != "" || repo.updateId != "" {
+		t.Fatalf("Expected name and files to stay untouched, got %s and %s", repo.newName, repo.updateId)
+	}
+
+	unknown := "wtfpl"
+	_, err = service.Update(
+		context.Background(),
+		updateImageIdMock,
+		auth.AuthorizationDto{},
+		"",
+		storage.ImageDescriptionUpdate{License: &unknown},
+		"",
+		nil,
+		nil,
+	)
+	var invalidArgument exception.InvalidArgument
+	if !errors.As(err, &invalidArgument) {
+		t.Fatalf("Expected invalid argument, got %v", err)
+	}
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"api/core/exception"
+	"api/image"
+	"api/image/content"
+	"errors"
+	"fmt"
+	"math"
+	"mime/multipart"
+	"strconv"
+	"strings"
+)
+
+// aspectRatioTolerance allows the cropper rounding a side by a few pixels
+const aspectRatioTolerance = 0.01
+
+// AspectRatio is the ratio of the width to the height of a cropped image, like 16:9
+type AspectRatio struct {
+	Width  int
+	Height int
+}
+
+func DefaultAspectRatios() []AspectRatio {
+	return []AspectRatio{{1, 1}, {3, 2}, {4, 3}, {5, 8}, {16, 9}}
+}
+
+// ParseAspectRatio parses the ratio written as width:height
+func ParseAspectRatio(value string) (AspectRatio, error) {
+	parts := strings.Split(strings.TrimSpace(value), ":")
+	if len(parts) != 2 {
+		return AspectRatio{}, fmt.Errorf("invalid aspect ratio %s, expected width:height", value)
+	}
+
+	width, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return AspectRatio{}, fmt.Errorf("invalid aspect ratio %s: %w", value, err)
+	}
+	height, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return AspectRatio{}, fmt.Errorf("invalid aspect ratio %s: %w", value, err)
+	}
+	if width <= 0 || height <= 0 {
+		return AspectRatio{}, fmt.Errorf("invalid aspect ratio %s, both sides must be greater than 0", value)
+	}
+
+	return AspectRatio{Width: width, Height: height}, nil
+}
+
+func (ratio AspectRatio) String() string {
+	return fmt.Sprintf("%d:%d", ratio.Width, ratio.Height)
+}
+
+func (ratio AspectRatio) matches(dimensions image.Dimensions) bool {
+	expected := float64(ratio.Width) / float64(ratio.Height)
+	actual := float64(dimensions.Width) / float64(dimensions.Height)
+	return math.Abs(actual-expected) <= expected*aspectRatioTolerance
+}
+
+// UploadRules are checked on the uploaded files before they are sent to the resizer, empty aspect ratios and zero
+// limits are not enforced. Duplicates decides about cropped files within MaxDistance bits of a stored image.
+// KeepGps keeps the position the original file was taken at in the metadata of the image. WebpMaxPixels limits the
+// cropped webp files, which the API encodes itself when it resizes the images.
+type UploadRules struct {
+	AspectRatios  []AspectRatio
+	MinDimension  int
+	MaxDimension  int
+	MaxPixels     int
+	WebpMaxPixels int
+	Duplicates    DuplicateMode
+	MaxDistance   int
+	KeepGps       bool
+}
+
+// validateFiles checks that both files are images in the format within the dimensions, and that the cropped one is
+// in one of the aspect ratios. Only the headers of the files are decoded.
+func (rules UploadRules) validateFiles(format image.Format, originalFile, croppedFile *multipart.FileHeader) error {
+	original, err := readImageHeader("Original", format, originalFile)
+	if err != nil {
+		return err
+	}
+	if err = rules.validateDimensions("Original", original); err != nil {
+		return err
+	}
+
+	cropped, err := readImageHeader("Cropped", format, croppedFile)
+	if err != nil {
+		return err
+	}
+	if err = rules.validateDimensions("Cropped", cropped); err != nil {
+		return err
+	}
+	if format == image.WebpFormat {
+		if err = content.CheckPixels(cropped, rules.WebpMaxPixels); errors.Is(err, content.ErrTooManyPixels) {
+			return exception.InvalidArgument{
+				Reason: fmt.Sprintf(
+					"Cropped webp image of %dx%d must not have more than %d pixels",
+					cropped.Width,
+					cropped.Height,
+					rules.WebpMaxPixels,
+				),
+			}
+		}
+	}
+
+	if len(rules.AspectRatios) == 0 {
+		return nil
+	}
+	ratios := make([]string, len(rules.AspectRatios))
+	for i, ratio := range rules.AspectRatios {
+		if ratio.matches(cropped) {
+			return nil
+		}
+		ratios[i] = ratio.String()
+	}
+	return exception.InvalidArgument{
+		Reason: fmt.Sprintf(
+			"Cropped image of %dx%d must be in one of the aspect ratios %s",
+			cropped.Width,
+			cropped.Height,
+			strings.Join(ratios, ", "),
+		),
+	}
+}
+
+func (rules UploadRules) validateDimensions(file string, dimensions image.Dimensions) error {
+	if dimensions.Width < rules.MinDimension || dimensions.Height < rules.MinDimension {
+		return exception.InvalidArgument{
+			Reason: fmt.Sprintf(
+				"%s image of %dx%d must be at least %d pixels wide and high",
+				file,
+				dimensions.Width,
+				dimensions.Height,
+				rules.MinDimension,
+			),
+		}
+	}
+	if rules.MaxDimension > 0 && (dimensions.Width > rules.MaxDimension || dimensions.Height > rules.MaxDimension) {
+		return exception.InvalidArgument{
+			Reason: fmt.Sprintf(
+				"%s image of %dx%d must be at most %d pixels wide and high",
+				file,
+				dimensions.Width,
+				dimensions.Height,
+				rules.MaxDimension,
+			),
+		}
+	}
+	if err := content.CheckPixels(dimensions, rules.MaxPixels); errors.Is(err, content.ErrTooManyPixels) {
+		return exception.InvalidArgument{
+			Reason: fmt.Sprintf(
+				"%s image of %dx%d must not have more than %d pixels",
+				file,
+				dimensions.Width,
+				dimensions.Height,
+				rules.MaxPixels,
+			),
+		}
+	}
+	return nil
+}
+
+// readImageHeader returns the dimensions of the file after checking from its magic bytes that it is in the format
+func readImageHeader(file string, format image.Format, fileHeader *multipart.FileHeader) (image.Dimensions, error) {
+	f, err := fileHeader.Open()
+	if err != nil {
+		return image.Dimensions{}, fmt.Errorf("failed opening %s file: %w", strings.ToLower(file), err)
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	contentFormat, dimensions, err := content.Inspect(f)
+	if err != nil {
+		return image.Dimensions{}, exception.InvalidArgument{
+			Reason: fmt.Sprintf("%s file is not a jpg, png or webp image", file),
+		}
+	}
+	if contentFormat != format {
+		return image.Dimensions{}, exception.InvalidArgument{
+			Reason: fmt.Sprintf("%s file is a %s image, expected %s", file, contentFormat, format),
+		}
+	}
+
+	return dimensions, nil
+}
@@ -0,0 +1,147 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/image/pipeline"
+	"api/storage"
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"mime/multipart"
+	"testing"
+)
+
+// newTestFileHeader encodes a blank image of the dimensions in the format and reads it back as an uploaded file
+func newTestFileHeader(t *testing.T, format image.Format, width, height int) *multipart.FileHeader {
+	data, err := pipeline.Encode(stdimage.NewRGBA(stdimage.Rect(0, 0, width, height)), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestFileHeaderOf(t, format, data)
+}
+
+func newTestFileHeaderOf(t *testing.T, format image.Format, data []byte) *multipart.FileHeader {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "file."+string(format))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = part.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err = writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	form, err := multipart.NewReader(&body, writer.Boundary()).ReadForm(int64(body.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = form.RemoveAll()
+	})
+	return form.File["file"][0]
+}
+
+func TestParseAspectRatio(t *testing.T) {
+	ratio, err := ParseAspectRatio(" 16:9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ratio != (AspectRatio{Width: 16, Height: 9}) {
+		t.Fatalf("Expected 16:9, got %s", ratio)
+	}
+
+	for _, value := range []string{"16", "16:0", "a:9", "1:2:3"} {
+		if _, err = ParseAspectRatio(value); err == nil {
+			t.Fatalf("Expected %s to be invalid", value)
+		}
+	}
+}
+
+func TestUploadRules_ValidateFiles(t *testing.T) {
+	rules := UploadRules{
+		AspectRatios:  DefaultAspectRatios(),
+		MinDimension:  100,
+		MaxDimension:  2000,
+		MaxPixels:     1500000,
+		WebpMaxPixels: 1450000,
+	}
+
+	values := []struct {
+		Name           string
+		Format         image.Format
+		OriginalFormat image.Format
+		Cropped        image.Dimensions
+		IsValid        bool
+	}{
+		{Name: "3:2", Format: image.PngFormat, Cropped: image.Dimensions{Width: 300, Height: 200}, IsValid: true},
+		{Name: "5:8", Format: image.JpgFormat, Cropped: image.Dimensions{Width: 500, Height: 800}, IsValid: true},
+		{Name: "16:9 rounded", Format: image.WebpFormat, Cropped: image.Dimensions{Width: 1601, Height: 900}, IsValid: true},
+		{Name: "Other ratio", Format: image.PngFormat, Cropped: image.Dimensions{Width: 300, Height: 100}},
+		{Name: "Too small", Format: image.PngFormat, Cropped: image.Dimensions{Width: 90, Height: 90}},
+		{Name: "Too large", Format: image.PngFormat, Cropped: image.Dimensions{Width: 2100, Height: 2100}},
+		{Name: "Too many pixels", Format: image.PngFormat, Cropped: image.Dimensions{Width: 1280, Height: 1280}},
+		{
+			Name:    "Png over webp pixels",
+			Format:  image.PngFormat,
+			Cropped: image.Dimensions{Width: 1210, Height: 1210},
+			IsValid: true,
+		},
+		{Name: "Too many webp pixels", Format: image.WebpFormat, Cropped: image.Dimensions{Width: 1210, Height: 1210}},
+		{
+			Name:           "Other content",
+			Format:         image.PngFormat,
+			OriginalFormat: image.JpgFormat,
+			Cropped:        image.Dimensions{Width: 300, Height: 200},
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			originalFormat := data.OriginalFormat
+			if originalFormat == "" {
+				originalFormat = data.Format
+			}
+			original := newTestFileHeader(t, originalFormat, 1000, 800)
+			cropped := newTestFileHeader(t, data.Format, data.Cropped.Width, data.Cropped.Height)
+
+			err := rules.validateFiles(data.Format, original, cropped)
+			if data.IsValid && err != nil {
+				t.Fatalf("Expected files to be valid, got %v", err)
+			}
+			if !data.IsValid {
+				var invalidArgument exception.InvalidArgument
+				if !errors.As(err, &invalidArgument) {
+					t.Fatalf("Expected invalid argument, got %v", err)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadAndResize_RejectsBeforeUpload(t *testing.T) {
+	service, _, _, sagas := newSagaTestService()
+	service.uploadRules = UploadRules{AspectRatios: DefaultAspectRatios()}
+
+	_, err := service.UploadAndResize(
+		context.Background(),
+		auth.AuthorizationDto{},
+		"my plane",
+		storage.ImageDescription{},
+		image.PngFormat,
+		newTestFileHeader(t, image.PngFormat, 600, 400),
+		newTestFileHeader(t, image.PngFormat, 300, 100),
+	)
+	var invalidArgument exception.InvalidArgument
+	if !errors.As(err, &invalidArgument) {
+		t.Fatalf("Expected invalid argument, got %v", err)
+	}
+	if len(sagas.sagas) != 0 {
+		t.Fatalf("Expected no upload to start, got %d sagas", len(sagas.sagas))
+	}
+}
@@ -0,0 +1,85 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"fmt"
+)
+
+func (service *ImagesService) GetImageVersions(ctx context.Context, imageId string) ([]storage.ImageVersion, error) {
+	if err := parseUuids(imageId); err != nil {
+		return nil, err
+	}
+
+	if _, err := service.imagesRepository.GetOne(ctx, imageId); err != nil {
+		return nil, toImageError(err)
+	}
+
+	versions, err := service.imagesRepository.GetVersions(ctx, imageId)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching versions of image %s: %w", imageId, err)
+	}
+
+	return versions, nil
+}
+
+// RestoreImageVersion moves the files of a previous version back under the name of the image, the replaced files
+// are kept as the next version so that the restore can be undone as well
+func (service *ImagesService) RestoreImageVersion(
+	ctx context.Context, authorization auth.AuthorizationDto, imageId string, version int,
+) (storage.Image, error) {
+	if err := parseUuids(imageId); err != nil {
+		return storage.Image{}, err
+	}
+	if version < 1 {
+		return storage.Image{}, exception.InvalidArgument{Reason: "Version must be a positive number"}
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.Image{}, err
+	}
+
+	img, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toImageError(err)
+	}
+	previous, err := service.imagesRepository.GetVersion(ctx, imageId, version)
+	if err != nil {
+		return storage.Image{}, toImageError(err)
+	}
+
+	replaced, err := service.keepFiles(ctx, authorization.Header, img)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	res, err := service.moveFiles(
+		ctx, authorization.Header, previous.FileName, img.Name, previous.Format, previous.Sizes,
+	)
+	if err != nil {
+		service.moveFilesBack(ctx, authorization.Header, replaced.FileName, img.Name, img.Format, img.Sizes)
+		return storage.Image{}, fmt.Errorf("failed moving back version %d of image %s: %w", version, imageId, err)
+	}
+
+	updates := previous.ApplyTo(img)
+	// When the files are moved the paths change as well, otherwise the ones of the version are kept
+	if res.Original != "" {
+		updates.Original = res.Original
+		updates.Domain = res.Domain
+		updates.Path = res.Path
+	}
+	if err = service.imagesRepository.RestoreVersion(ctx, updates, replaced, previous.Version); err != nil {
+		service.moveFilesBack(ctx, authorization.Header, img.Name, previous.FileName, previous.Format, previous.Sizes)
+		service.moveFilesBack(ctx, authorization.Header, replaced.FileName, img.Name, img.Format, img.Sizes)
+		return storage.Image{}, toImageError(err)
+	}
+	restored, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toImageError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageUpdated, img.Id, img, restored)
+
+	return restored, nil
+}
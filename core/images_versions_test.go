@@ -0,0 +1,221 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/image/local"
+	"api/image/pipeline"
+	"api/storage"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	stdimage "image"
+	"image/color"
+	"mime/multipart"
+	"testing"
+)
+
+// versionsRepoStub keeps a single image with its previous versions
+type versionsRepoStub struct {
+	updateRepoStub
+	versions []storage.ImageVersion
+	replaced *storage.Image
+}
+
+func (repo *versionsRepoStub) GetVersions(_ context.Context, _ string) ([]storage.ImageVersion, error) {
+	return repo.versions, nil
+}
+
+func (repo *versionsRepoStub) GetVersion(_ context.Context, imageId string, version int) (storage.ImageVersion, error) {
+	for _, found := range repo.versions {
+		if found.Version == version {
+			return found, nil
+		}
+	}
+	return storage.ImageVersion{}, storage.NotFound{
+		Msg: fmt.Sprintf("Version %d of image %s not found", version, imageId),
+	}
+}
+
+func (repo *versionsRepoStub) ReplaceFiles(
+	_ context.Context, updates storage.Image, replaced storage.ImageVersion,
+) error {
+	kept := storage.ImageVersion{
+		ImageId:  repo.image.Id,
+		Version:  1,
+		Name:     repo.image.Name,
+		FileName: replaced.FileName,
+		Format:   repo.image.Format,
+		Original: repo.image.Original,
+		Domain:   repo.image.Domain,
+		Path:     repo.image.Path,
+		Sizes:    repo.image.Sizes,
+	}
+	if replaced.Original != "" {
+		kept.Original = replaced.Original
+	}
+	for _, found := range repo.versions {
+		if found.Version >= kept.Version {
+			kept.Version = found.Version + 1
+		}
+	}
+	repo.versions = append(repo.versions, kept)
+	repo.replaced = &updates
+	repo.image = updates
+	return nil
+}
+
+func (repo *versionsRepoStub) RestoreVersion(
+	ctx context.Context, updates storage.Image, replaced storage.ImageVersion, version int,
+) error {
+	if err := repo.ReplaceFiles(ctx, updates, replaced); err != nil {
+		return err
+	}
+	kept := make([]storage.ImageVersion, 0, len(repo.versions))
+	for _, found := range repo.versions {
+		if found.Version != version {
+			kept = append(kept, found)
+		}
+	}
+	repo.versions = kept
+	return nil
+}
+
+func newFilledFileHeader(t *testing.T, width, height int, fill color.Color) *multipart.FileHeader {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, fill)
+		}
+	}
+	data, err := pipeline.Encode(img, image.PngFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newTestFileHeaderOf(t, image.PngFormat, data)
+}
+
+func TestRestoreImageVersion(t *testing.T) {
+	service, updateRepo := newUpdateTestService()
+	repo := &versionsRepoStub{
+		updateRepoStub: *updateRepo,
+		versions: []storage.ImageVersion{{
+			ImageId:  updateImageIdMock,
+			Version:  1,
+			Name:     "my-plane",
+			FileName: "my-plane",
+			Format:   storage.JpgFormat,
+			Original: "images/my-plane.jpg",
+			Sizes:    storage.ImageSizes{Original: storage.Dimensions{Width: 300, Height: 200}},
+		}},
+	}
+	service.imagesRepository = repo
+
+	restored, err := service.RestoreImageVersion(context.Background(), auth.AuthorizationDto{}, updateImageIdMock, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.replaced == nil || repo.replaced.Id != updateImageIdMock {
+		t.Fatalf("Expected files of %s to be replaced, got %+v", updateImageIdMock, repo.replaced)
+	}
+	if restored.Format != storage.JpgFormat || restored.Original != "images/my-plane.jpg" {
+		t.Fatalf("Expected files of version 1, got %+v", restored)
+	}
+	if len(repo.versions) != 1 || repo.versions[0].Version != 2 {
+		t.Fatalf("Expected the replaced files as version 2 only, got %+v", repo.versions)
+	}
+
+	_, err = service.RestoreImageVersion(context.Background(), auth.AuthorizationDto{}, updateImageIdMock, 1)
+	var notFound exception.NotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+
+	_, err = service.RestoreImageVersion(context.Background(), auth.AuthorizationDto{}, updateImageIdMock, 0)
+	var invalidArgument exception.InvalidArgument
+	if !errors.As(err, &invalidArgument) {
+		t.Fatalf("Expected invalid argument, got %v", err)
+	}
+}
+
+func TestRestoreImageVersion_SameNameUpdate(t *testing.T) {
+	ctx := context.Background()
+	service, updateRepo := newUpdateTestService()
+	repo := &versionsRepoStub{updateRepoStub: *updateRepo}
+	service.imagesRepository = repo
+	store := local.NewFileStore(t.TempDir())
+	resizer := local.NewResizer(
+		store, "http://localhost:3000/files", pipeline.Breakpoints{Xs: 40, S: 120}, 0, service.logger,
+	)
+	service.resizeApi = resizer
+
+	red := color.NRGBA{R: 255, A: 255}
+	original, cropped := newFilledFileHeader(t, 600, 400, red), newFilledFileHeader(t, 300, 200, red)
+	uploads := map[string]*multipart.FileHeader{"uploads/red.png": original, "uploads/red-crop.png": cropped}
+	for key, file := range uploads {
+		if err := resizer.UploadFile(ctx, key, image.PngFormat, file); err != nil {
+			t.Fatal(err)
+		}
+	}
+	res, err := resizer.Resize(ctx, "", image.ResizeRequest{
+		Name: "my-plane", FilePath: "uploads/red-crop.png", OriginalFilePath: "uploads/red.png",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.image.Original = res.Original
+	repo.image.Domain = res.Domain
+	repo.image.Path = res.Path
+	repo.image.Sizes = convertImageSizesToStorageSizes(res.Sizes)
+	redFile, err := store.Get(ctx, res.Original)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	blue := color.NRGBA{B: 255, A: 255}
+	updated, err := service.Update(
+		ctx, updateImageIdMock, auth.AuthorizationDto{}, "", storage.ImageDescriptionUpdate{}, image.PngFormat,
+		newFilledFileHeader(t, 600, 400, blue), newFilledFileHeader(t, 300, 200, blue),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Name != "my-plane" || len(repo.versions) != 1 {
+		t.Fatalf("Expected the files replaced under the same name keeping a version, got %+v", repo.versions)
+	}
+	version := repo.versions[0]
+	if version.Original == updated.Original {
+		t.Fatalf("Expected the version files apart from the image files, both are %s", updated.Original)
+	}
+	versionFile, err := store.Get(ctx, version.Original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blueFile, err := store.Get(ctx, updated.Original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(versionFile, redFile) || bytes.Equal(blueFile, redFile) {
+		t.Fatal("Expected the version to keep the replaced file")
+	}
+
+	restored, err := service.RestoreImageVersion(ctx, auth.AuthorizationDto{}, updateImageIdMock, version.Version)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restoredFile, err := store.Get(ctx, restored.Original)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.Name != "my-plane" || restored.Original != res.Original || !bytes.Equal(restoredFile, redFile) {
+		t.Fatalf("Expected the replaced file back under the name of the image, got %+v", restored)
+	}
+	if len(repo.versions) != 1 {
+		t.Fatalf("Expected the restored version replaced by the blue files, got %+v", repo.versions)
+	}
+	if keptFile, err := store.Get(ctx, repo.versions[0].Original); err != nil || !bytes.Equal(keptFile, blueFile) {
+		t.Fatalf("Expected the blue files kept as a version, got %v", err)
+	}
+}
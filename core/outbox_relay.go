@@ -0,0 +1,181 @@
+package core
+
+import (
+	"api/events"
+	"api/pkg/concurrency"
+	"api/storage"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+const (
+	outboxRelayBatchSize = 100
+	// outboxRelayLease keeps a claimed batch from the other instances, long enough for the webhooks to retry every event
+	outboxRelayLease = 10 * time.Minute
+	// outboxRetryMaxBackoff caps the wait before a failed event is retried, it doubles with every attempt until then
+	outboxRetryMaxBackoff = time.Hour
+)
+
+// OutboxRelay publishes the events that the images repository writes to the outbox
+type OutboxRelay struct {
+	outbox      storage.OutboxRepository
+	publisher   events.EventPublisher
+	logger      *zerolog.Logger
+	intervalSec uint
+	maxAttempts uint
+	closed      chan error
+	cancel      context.CancelFunc
+}
+
+func NewOutboxRelay(
+	config Config,
+	outbox storage.OutboxRepository,
+	publisher events.EventPublisher,
+	logger *zerolog.Logger,
+) *OutboxRelay {
+	return &OutboxRelay{
+		outbox:      outbox,
+		publisher:   publisher,
+		logger:      logger,
+		intervalSec: config.OutboxRelayIntervalSec,
+		maxAttempts: config.OutboxMaxAttempts,
+		closed:      make(chan error, 1),
+	}
+}
+
+func (relay *OutboxRelay) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// Full batches are followed by the next one right away
+			count, err := relay.RelayBatch(ctx)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				relay.logger.Error().Err(err).Msg("failed relaying outbox events")
+			}
+			if err == nil && count == outboxRelayBatchSize {
+				continue
+			}
+
+			if err = concurrency.SleepSecondsWithContext(ctx, relay.intervalSec); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (relay *OutboxRelay) StartAsync(ctx context.Context) {
+	relay.logger.Info().Msg("Started relaying outbox events")
+
+	derivedCtx, cancel := context.WithCancel(ctx)
+	relay.cancel = cancel
+	go func() {
+		relay.closed <- relay.Start(derivedCtx)
+		close(relay.closed)
+	}()
+}
+
+func (relay *OutboxRelay) Shutdown() error {
+	if relay.cancel == nil {
+		return nil
+	}
+
+	relay.logger.Info().Msg("Shutting down outbox relay")
+	relay.cancel()
+	if err := <-relay.closed; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
+
+// RelayBatch publishes a batch of events in the order they were written and returns how many were published. After a
+// failure the later events of the image wait for the event to be retried, so that the events of an image are never
+// published out of order, while the events of the other images go on. An event failing maxAttempts times is given up
+// on, the first failure of the batch is returned.
+func (relay *OutboxRelay) RelayBatch(ctx context.Context) (int, error) {
+	outboxEvents, err := relay.outbox.ClaimUnpublished(ctx, outboxRelayBatchSize, outboxRelayLease)
+	if err != nil {
+		return 0, err
+	}
+
+	published := 0
+	var failure error
+	var waiting []string
+	failedImages := make(map[string]bool)
+	for i, outboxEvent := range outboxEvents {
+		if ctx.Err() != nil {
+			// The lease of the rest runs out on its own, releasing needs the context
+			return published, ctx.Err()
+		}
+		if failedImages[outboxEvent.ImageId] {
+			waiting = append(waiting, outboxEvent.Id)
+			continue
+		}
+
+		event := events.Event{
+			Id:        outboxEvent.Id,
+			Type:      string(outboxEvent.Type),
+			ImageId:   outboxEvent.ImageId,
+			Payload:   outboxEvent.Payload,
+			CreatedAt: outboxEvent.CreatedAt,
+		}
+
+		if err = relay.publisher.Publish(ctx, event); err != nil {
+			failedImages[event.ImageId] = true
+			if failure == nil {
+				failure = err
+			}
+			relay.markFailed(ctx, outboxEvent, err)
+			continue
+		}
+
+		if err = relay.outbox.MarkPublished(ctx, event.Id); err != nil {
+			for _, unpublished := range outboxEvents[i:] {
+				waiting = append(waiting, unpublished.Id)
+			}
+			failure = err
+			break
+		}
+		published++
+	}
+
+	if err = relay.outbox.Release(ctx, waiting...); err != nil {
+		relay.logger.Error().Err(err).Msg("failed releasing outbox events")
+	}
+
+	return published, failure
+}
+
+// markFailed leaves the event to be retried with a backoff or, once it has failed maxAttempts times, gives up on it
+// and lets the later events of its image go on
+func (relay *OutboxRelay) markFailed(ctx context.Context, outboxEvent storage.OutboxEvent, publishErr error) {
+	attempts := uint(outboxEvent.Attempts) + 1
+	if attempts >= relay.maxAttempts {
+		relay.logger.Error().Err(publishErr).Str("eventId", outboxEvent.Id).Uint("attempts", attempts).
+			Msg("giving up on outbox event")
+		if err := relay.outbox.MarkDead(ctx, outboxEvent.Id, publishErr.Error()); err != nil {
+			relay.logger.Error().Err(err).Str("eventId", outboxEvent.Id).Msg("failed marking outbox event")
+		}
+		return
+	}
+
+	if err := relay.outbox.MarkFailed(ctx, outboxEvent.Id, publishErr.Error(), relay.retryBackoff(attempts)); err != nil {
+		relay.logger.Error().Err(err).Str("eventId", outboxEvent.Id).Msg("failed marking outbox event")
+	}
+}
+
+// retryBackoff starts at the relay interval and doubles with every attempt
+func (relay *OutboxRelay) retryBackoff(attempts uint) time.Duration {
+	backoff := time.Duration(relay.intervalSec) * time.Second
+	for i := uint(1); i < attempts && backoff < outboxRetryMaxBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > outboxRetryMaxBackoff {
+		return outboxRetryMaxBackoff
+	}
+	return backoff
+}
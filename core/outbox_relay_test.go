@@ -0,0 +1,161 @@
+package core
+
+import (
+	"api/events"
+	"api/storage"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"testing"
+	"time"
+)
+
+type outboxRepoStub struct {
+	events     []storage.OutboxEvent
+	published  map[string]bool
+	failed     map[string]time.Duration
+	dead       map[string]string
+	released   []string
+	claimLease time.Duration
+}
+
+func (repo *outboxRepoStub) ClaimUnpublished(
+	_ context.Context, limit int, lease time.Duration,
+) ([]storage.OutboxEvent, error) {
+	repo.claimLease = lease
+	var unpublished []storage.OutboxEvent
+	for _, event := range repo.events {
+		if !repo.published[event.Id] && len(unpublished) < limit {
+			unpublished = append(unpublished, event)
+		}
+	}
+	return unpublished, nil
+}
+
+func (repo *outboxRepoStub) MarkPublished(_ context.Context, eventId string) error {
+	repo.published[eventId] = true
+	return nil
+}
+
+func (repo *outboxRepoStub) MarkFailed(_ context.Context, eventId string, _ string, retryAfter time.Duration) error {
+	repo.failed[eventId] = retryAfter
+	return nil
+}
+
+func (repo *outboxRepoStub) MarkDead(_ context.Context, eventId string, reason string) error {
+	repo.dead[eventId] = reason
+	return nil
+}
+
+func (repo *outboxRepoStub) Release(_ context.Context, eventIds ...string) error {
+	repo.released = append(repo.released, eventIds...)
+	return nil
+}
+
+// failingPublisher fails publishing the event with the given id
+type failingPublisher struct {
+	events.MemoryPublisher
+	failId string
+}
+
+func (publisher *failingPublisher) Publish(ctx context.Context, event events.Event) error {
+	if event.Id == publisher.failId {
+		return errors.New("webhook unavailable")
+	}
+	return publisher.MemoryPublisher.Publish(ctx, event)
+}
+
+func newOutboxRepoStub() *outboxRepoStub {
+	return &outboxRepoStub{
+		events: []storage.OutboxEvent{
+			{Id: "1", Type: storage.EventImageCreated, ImageId: "a"},
+			{Id: "2", Type: storage.EventImageUpdated, ImageId: "a"},
+			{Id: "3", Type: storage.EventImageDeleted, ImageId: "a"},
+			{Id: "4", Type: storage.EventImageCreated, ImageId: "b"},
+		},
+		published: map[string]bool{},
+		failed:    map[string]time.Duration{},
+		dead:      map[string]string{},
+	}
+}
+
+func TestOutboxRelay_RelayBatch(t *testing.T) {
+	logger := zerolog.Nop()
+	repo := newOutboxRepoStub()
+	publisher := events.NewMemoryPublisher()
+	relay := NewOutboxRelay(Config{OutboxMaxAttempts: 10}, repo, publisher, &logger)
+
+	count, err := relay.RelayBatch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 4 || len(repo.published) != 4 {
+		t.Fatalf("Expected 4 published events, got %d", count)
+	}
+	if repo.claimLease != outboxRelayLease {
+		t.Fatalf("Expected the batch leased for %s, got %s", outboxRelayLease, repo.claimLease)
+	}
+
+	published := publisher.Events()
+	for i, event := range published {
+		if event.Id != repo.events[i].Id || event.Type != string(repo.events[i].Type) {
+			t.Fatalf("Expected event %s at %d, got %s", repo.events[i].Id, i, event.Id)
+		}
+	}
+}
+
+func TestOutboxRelay_RelayBatchHoldsImageAfterFailure(t *testing.T) {
+	logger := zerolog.Nop()
+	repo := newOutboxRepoStub()
+	publisher := &failingPublisher{failId: "2"}
+	relay := NewOutboxRelay(Config{OutboxRelayIntervalSec: 5, OutboxMaxAttempts: 10}, repo, publisher, &logger)
+
+	count, err := relay.RelayBatch(context.Background())
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if count != 2 || !repo.published["1"] || repo.published["3"] || !repo.published["4"] {
+		t.Fatalf("Expected the events before the failure and of the other image published, got %v", repo.published)
+	}
+	if repo.failed["2"] != 5*time.Second {
+		t.Fatalf("Expected the failure to be retried after 5s, got %v", repo.failed)
+	}
+	if len(repo.released) != 1 || repo.released[0] != "3" {
+		t.Fatalf("Expected the later event of the image released, got %v", repo.released)
+	}
+}
+
+func TestOutboxRelay_RelayBatchGivesUp(t *testing.T) {
+	logger := zerolog.Nop()
+	repo := newOutboxRepoStub()
+	repo.events[1].Attempts = 9
+	publisher := &failingPublisher{failId: "2"}
+	relay := NewOutboxRelay(Config{OutboxRelayIntervalSec: 5, OutboxMaxAttempts: 10}, repo, publisher, &logger)
+
+	if _, err := relay.RelayBatch(context.Background()); err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if repo.dead["2"] == "" || len(repo.failed) != 0 {
+		t.Fatalf("Expected the event given up on, got dead %v and failed %v", repo.dead, repo.failed)
+	}
+}
+
+func TestOutboxRelay_RetryBackoff(t *testing.T) {
+	relay := OutboxRelay{intervalSec: 5}
+
+	values := []struct {
+		Attempts uint
+		Expected time.Duration
+	}{
+		{Attempts: 1, Expected: 5 * time.Second},
+		{Attempts: 2, Expected: 10 * time.Second},
+		{Attempts: 4, Expected: 40 * time.Second},
+		{Attempts: 20, Expected: outboxRetryMaxBackoff},
+	}
+
+	for _, data := range values {
+		if backoff := relay.retryBackoff(data.Attempts); backoff != data.Expected {
+			t.Errorf("Expected %s after %d attempts, got %s", data.Expected, data.Attempts, backoff)
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package core
+
+import (
+	"api/image/content"
+	"api/image/placeholder"
+	"api/storage"
+	"context"
+	"fmt"
+	"github.com/rs/zerolog"
+	"io/ioutil"
+	"net/http"
+	"strings"
+)
+
+const placeholderBackfillBatchSize = 100
+
+// PlaceholderBackfill computes the placeholders of the images stored before they were computed on upload. The
+// smallest size of each image is downloaded from its domain, placeholders carry no detail so it is enough.
+type PlaceholderBackfill struct {
+	imagesRepository storage.ImagesRepository
+	client           *http.Client
+	maxPixels        int
+	logger           *zerolog.Logger
+}
+
+func NewPlaceholderBackfill(
+	imagesRepository storage.ImagesRepository, client *http.Client, maxPixels int, logger *zerolog.Logger,
+) *PlaceholderBackfill {
+	return &PlaceholderBackfill{
+		imagesRepository: imagesRepository,
+		client:           client,
+		maxPixels:        maxPixels,
+		logger:           logger,
+	}
+}
+
+// Run goes through every image without placeholders once, images whose file can not be read are logged and skipped
+// so that they are retried by the next run. It returns the number of images filled and skipped.
+func (backfill *PlaceholderBackfill) Run(ctx context.Context) (filled, skipped int, err error) {
+	afterId := ""
+	for {
+		images, err := backfill.imagesRepository.GetWithoutPlaceholders(ctx, afterId, placeholderBackfillBatchSize)
+		if err != nil {
+			return filled, skipped, err
+		}
+
+		for _, img := range images {
+			afterId = img.Id
+			computed, err := backfill.compute(ctx, img)
+			if err != nil {
+				backfill.logger.Warn().Err(err).Str("imageId", img.Id).Msg("skipped image without placeholders")
+				skipped++
+				continue
+			}
+			if err = backfill.imagesRepository.SetPlaceholders(ctx, img.Id, computed.BlurHash, computed.Color); err != nil {
+				return filled, skipped, fmt.Errorf("failed storing placeholders of image %s: %w", img.Id, err)
+			}
+			filled++
+		}
+
+		if len(images) < placeholderBackfillBatchSize {
+			return filled, skipped, nil
+		}
+	}
+}
+
+func (backfill *PlaceholderBackfill) compute(ctx context.Context, img storage.Image) (placeholder.Placeholder, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, smallestFileUrl(img), nil)
+	if err != nil {
+		return placeholder.Placeholder{}, err
+	}
+	res, err := backfill.client.Do(request)
+	if err != nil {
+		return placeholder.Placeholder{}, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		return placeholder.Placeholder{}, fmt.Errorf("failed downloading %s, got status %d", request.URL, res.StatusCode)
+	}
+
+	data, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return placeholder.Placeholder{}, err
+	}
+	decoded, err := content.Decode(data, backfill.maxPixels)
+	if err != nil {
+		return placeholder.Placeholder{}, err
+	}
+
+	return placeholder.Compute(decoded), nil
+}
+
+// smallestFileUrl is where the smallest size of the image is served from, the full size when it has no other
+func smallestFileUrl(img storage.Image) string {
+	dimensions := convertStorageSizesToDimensions(img.Sizes)
+	smallest := dimensions[0]
+	if len(dimensions) > 1 {
+		smallest = dimensions[1]
+	}
+
+	return fmt.Sprintf(
+		"%s/%s/%s-%dx%d.%s",
+		strings.TrimSuffix(img.Domain, "/"),
+		img.Path,
+		img.Name,
+		smallest.Width,
+		smallest.Height,
+		img.Format,
+	)
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"api/image"
+	"api/image/pipeline"
+	"api/storage"
+	"context"
+	"github.com/rs/zerolog"
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// placeholderRepoStub returns the images without placeholders once and records the stored ones
+type placeholderRepoStub struct {
+	storage.ImageRepoMock
+	images       storage.ImageList
+	placeholders map[string][2]string
+}
+
+func (repo *placeholderRepoStub) GetWithoutPlaceholders(
+	_ context.Context, afterId string, limit int,
+) (storage.ImageList, error) {
+	var images storage.ImageList
+	for _, img := range repo.images {
+		if img.Id > afterId && len(images) < limit {
+			images = append(images, img)
+		}
+	}
+	return images, nil
+}
+
+func (repo *placeholderRepoStub) SetPlaceholders(_ context.Context, imageId, blurHash, dominantColor string) error {
+	repo.placeholders[imageId] = [2]string{blurHash, dominantColor}
+	return nil
+}
+
+func TestPlaceholderBackfill_Run(t *testing.T) {
+	green := stdimage.NewNRGBA(stdimage.Rect(0, 0, 100, 75))
+	draw.Draw(green, green.Bounds(), &stdimage.Uniform{C: color.NRGBA{G: 255, A: 255}}, stdimage.Point{}, draw.Src)
+	data, err := pipeline.Encode(green, image.PngFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/files/images/my-plane-100x75.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write(data)
+	}))
+	defer server.Close()
+
+	repo := &placeholderRepoStub{
+		images: storage.ImageList{
+			{
+				Id:     "1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed",
+				Name:   "my-plane",
+				Format: storage.PngFormat,
+				Domain: server.URL + "/files/",
+				Path:   "images",
+				Sizes: storage.ImageSizes{
+					Original: storage.Dimensions{Width: 400, Height: 300},
+					Xs:       &storage.Dimensions{Width: 100, Height: 75},
+				},
+			},
+			{
+				Id:     "6ec0bd7f-11c0-43da-975e-2a8ad9ebae0b",
+				Name:   "missing-file",
+				Format: storage.PngFormat,
+				Domain: server.URL + "/files",
+				Path:   "images",
+				Sizes:  storage.ImageSizes{Original: storage.Dimensions{Width: 400, Height: 300}},
+			},
+		},
+		placeholders: map[string][2]string{},
+	}
+	logger := zerolog.Nop()
+
+	filled, skipped, err := NewPlaceholderBackfill(repo, server.Client(), 0, &logger).Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filled != 1 || skipped != 1 {
+		t.Fatalf("Expected 1 image filled and 1 skipped, got %d and %d", filled, skipped)
+	}
+	if placeholders := repo.placeholders["1b9d6bcd-bbfd-4b2d-9b5d-ab8dfbbd4bed"]; placeholders[1] != "#00ff00" {
+		t.Fatalf("Expected the green of the smallest size, got %v", placeholders)
+	}
+}
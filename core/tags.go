@@ -0,0 +1,185 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+)
+
+// normalizeTag lowercases the tag and collapses whitespace, so "World  War" and "world war" are the same tag
+func normalizeTag(value string) string {
+	return strings.ToLower(strings.Join(strings.Fields(value), " "))
+}
+
+func toTagError(err error) error {
+	var notFound storage.NotFound
+	if errors.As(err, &notFound) {
+		return exception.NotFound{Msg: notFound.Msg}
+	}
+	if errors.Is(err, storage.ErrDuplicate) {
+		return exception.InvalidArgument{Reason: "Tag already exists"}
+	}
+	return err
+}
+
+func parseUuids(ids ...string) error {
+	for _, id := range ids {
+		if _, err := uuid.Parse(id); err != nil {
+			return exception.InvalidArgument{Reason: fmt.Sprintf("Invalid uuid %s", id)}
+		}
+	}
+	return nil
+}
+
+func (service *ImagesService) GetTags(ctx context.Context) (storage.TagUsageList, error) {
+	tags, err := service.tagsRepository.Get(ctx)
+	if err != nil {
+		return storage.TagUsageList{}, fmt.Errorf("failed fetching tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+func (service *ImagesService) CreateTag(
+	ctx context.Context, authorization auth.AuthorizationDto, value string,
+) (storage.Tag, error) {
+	normalized := normalizeTag(value)
+	if normalized == "" {
+		return storage.Tag{}, exception.InvalidArgument{Reason: "Tag value must not be empty"}
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.Tag{}, err
+	}
+
+	tag, err := service.tagsRepository.Create(ctx, normalized, user.Id)
+	if err != nil {
+		return storage.Tag{}, toTagError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditTagCreated, tag.Id, nil, tag)
+
+	return tag, nil
+}
+
+func (service *ImagesService) RenameTag(
+	ctx context.Context, authorization auth.AuthorizationDto, tagId, value string,
+) (storage.Tag, error) {
+	if err := parseUuids(tagId); err != nil {
+		return storage.Tag{}, err
+	}
+	normalized := normalizeTag(value)
+	if normalized == "" {
+		return storage.Tag{}, exception.InvalidArgument{Reason: "Tag value must not be empty"}
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.Tag{}, err
+	}
+	if user.Role != storage.AuthRoleAdmin {
+		return storage.Tag{}, exception.Forbidden{}
+	}
+
+	previous, err := service.tagsRepository.GetOne(ctx, tagId)
+	if err != nil {
+		return storage.Tag{}, toTagError(err)
+	}
+	tag, err := service.tagsRepository.Rename(ctx, tagId, normalized)
+	if err != nil {
+		return storage.Tag{}, toTagError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditTagUpdated, tag.Id, previous, tag)
+
+	return tag, nil
+}
+
+func (service *ImagesService) DeleteTag(
+	ctx context.Context, authorization auth.AuthorizationDto, tagId string,
+) error {
+	if err := parseUuids(tagId); err != nil {
+		return err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return err
+	}
+	if user.Role != storage.AuthRoleAdmin {
+		return exception.Forbidden{}
+	}
+
+	previous, err := service.tagsRepository.GetOne(ctx, tagId)
+	if err != nil {
+		return toTagError(err)
+	}
+	if err = service.tagsRepository.DeleteOne(ctx, tagId); err != nil {
+		return toTagError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditTagDeleted, tagId, previous, nil)
+
+	return nil
+}
+
+func (service *ImagesService) AttachTag(
+	ctx context.Context, authorization auth.AuthorizationDto, imageId, tagId string,
+) (storage.Image, error) {
+	if err := parseUuids(imageId, tagId); err != nil {
+		return storage.Image{}, err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.Image{}, err
+	}
+
+	previous, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toTagError(err)
+	}
+	if err = service.tagsRepository.Attach(ctx, imageId, tagId); err != nil {
+		return storage.Image{}, toTagError(err)
+	}
+
+	img, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toTagError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageUpdated, img.Id, previous, img)
+
+	return img, nil
+}
+
+func (service *ImagesService) DetachTag(
+	ctx context.Context, authorization auth.AuthorizationDto, imageId, tagId string,
+) (storage.Image, error) {
+	if err := parseUuids(imageId, tagId); err != nil {
+		return storage.Image{}, err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.Image{}, err
+	}
+
+	previous, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toTagError(err)
+	}
+	if err = service.tagsRepository.Detach(ctx, imageId, tagId); err != nil {
+		return storage.Image{}, toTagError(err)
+	}
+
+	img, err := service.imagesRepository.GetOne(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toTagError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageUpdated, img.Id, previous, img)
+
+	return img, nil
+}
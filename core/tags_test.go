@@ -0,0 +1,24 @@
+package core
+
+import "testing"
+
+func TestNormalizeTag(t *testing.T) {
+	values := []struct {
+		Name     string
+		Value    string
+		Expected string
+	}{
+		{Name: "Already normalized", Value: "planes", Expected: "planes"},
+		{Name: "Uppercase with spaces", Value: "  World   War 2 ", Expected: "world war 2"},
+		{Name: "Only whitespace", Value: " \t ", Expected: ""},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			result := normalizeTag(data.Value)
+			if result != data.Expected {
+				t.Fatalf("Expected %s, got %s\n", data.Expected, result)
+			}
+		})
+	}
+}
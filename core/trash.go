@@ -0,0 +1,190 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/pkg/concurrency"
+	"api/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"time"
+)
+
+const (
+	trashPurgeBatchSize   = 50
+	trashPurgeIntervalSec = 60 * 60
+)
+
+func (service *ImagesService) GetTrash(ctx context.Context, limit, offset int) (storage.ImagePage, error) {
+	page, err := service.imagesRepository.GetDeleted(ctx, limit, offset)
+	if err != nil {
+		return storage.ImagePage{}, fmt.Errorf("failed fetching deleted images: %w", err)
+	}
+
+	return page, nil
+}
+
+func (service *ImagesService) RestoreImage(
+	ctx context.Context, authorization auth.AuthorizationDto, imageId string,
+) (storage.Image, error) {
+	if err := parseUuids(imageId); err != nil {
+		return storage.Image{}, err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	if user.Role != storage.AuthRoleAdmin {
+		return storage.Image{}, exception.Forbidden{}
+	}
+
+	img, err := service.imagesRepository.Restore(ctx, imageId)
+	if err != nil {
+		return storage.Image{}, toImageError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditImageRestored, img.Id, nil, img)
+
+	return img, nil
+}
+
+// PurgeTrash removes a batch of the images deleted before the retention period, files of every version first, so
+// that a failure leaves the image in the trash to be purged again. It returns how many images were purged.
+func (service *ImagesService) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	images, err := service.imagesRepository.GetDeletedBefore(ctx, time.Now().Add(-retention), trashPurgeBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, img := range images {
+		request := image.DeleteRequest{
+			Name:       img.Name,
+			Format:     image.Format(img.Format),
+			Dimensions: convertStorageSizesToDimensions(img.Sizes),
+		}
+		if err = service.purgeFiles(ctx, img.Id, request); err != nil {
+			return i, fmt.Errorf("failed deleting files of image %s: %w", img.Id, err)
+		}
+		if err = service.deleteVersionFiles(ctx, img.Id); err != nil {
+			return i, err
+		}
+
+		var notFound storage.NotFound
+		if err = service.imagesRepository.Purge(ctx, img.Id); err != nil && !errors.As(err, &notFound) {
+			return i, fmt.Errorf("failed purging image %s: %w", img.Id, err)
+		}
+	}
+
+	return len(images), nil
+}
+
+func (service *ImagesService) deleteVersionFiles(ctx context.Context, imageId string) error {
+	versions, err := service.imagesRepository.GetVersions(ctx, imageId)
+	if err != nil {
+		return fmt.Errorf("failed fetching versions of image %s: %w", imageId, err)
+	}
+
+	for _, version := range versions {
+		request := image.DeleteRequest{
+			Name:       version.FileName,
+			Format:     image.Format(version.Format),
+			Dimensions: convertStorageSizesToDimensions(version.Sizes),
+		}
+		if err = service.purgeFiles(ctx, imageId, request); err != nil {
+			return fmt.Errorf("failed deleting files of version %d of image %s: %w", version.Version, imageId, err)
+		}
+	}
+
+	return nil
+}
+
+// purgeFiles deletes the files and invalidates their cached copies, a failed invalidation is only logged as the
+// copies expire on their own
+func (service *ImagesService) purgeFiles(ctx context.Context, imageId string, request image.DeleteRequest) error {
+	if err := service.resizeApi.Delete(ctx, service.serviceAuthHeader, request); err != nil {
+		return err
+	}
+	if err := service.resizeApi.Invalidate(ctx, service.serviceAuthHeader, request); err != nil {
+		service.logger.Error().Err(err).Str("imageId", imageId).Str("name", request.Name).
+			Msg("failed invalidating purged files")
+	}
+	return nil
+}
+
+// TrashPurger periodically purges the images that stayed in the trash longer than the retention period
+type TrashPurger struct {
+	images    *ImagesService
+	retention time.Duration
+	// enabled is false when the files of the purged images could not be deleted
+	enabled bool
+	logger  *zerolog.Logger
+	closed  chan error
+	cancel  context.CancelFunc
+}
+
+func NewTrashPurger(config Config, images *ImagesService, logger *zerolog.Logger) *TrashPurger {
+	return &TrashPurger{
+		images:    images,
+		retention: time.Duration(config.TrashRetentionDays) * 24 * time.Hour,
+		enabled:   config.HasImagesApiServiceAccess(),
+		logger:    logger,
+		closed:    make(chan error, 1),
+	}
+}
+
+func (purger *TrashPurger) Start(ctx context.Context) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			// Full batches are followed by the next one right away
+			count, err := purger.images.PurgeTrash(ctx, purger.retention)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				purger.logger.Error().Err(err).Msg("failed purging trash")
+			}
+			if count > 0 {
+				purger.logger.Info().Msgf("Purged %d images from the trash", count)
+			}
+			if err == nil && count == trashPurgeBatchSize {
+				continue
+			}
+
+			if err = concurrency.SleepSecondsWithContext(ctx, trashPurgeIntervalSec); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (purger *TrashPurger) StartAsync(ctx context.Context) {
+	if !purger.enabled {
+		purger.logger.Warn().Msg("Not purging trash, env IMAGES_API_SERVICE_TOKEN is missing to delete the files")
+		return
+	}
+	purger.logger.Info().Msg("Started purging trash")
+
+	derivedCtx, cancel := context.WithCancel(ctx)
+	purger.cancel = cancel
+	go func() {
+		purger.closed <- purger.Start(derivedCtx)
+		close(purger.closed)
+	}()
+}
+
+func (purger *TrashPurger) Shutdown() error {
+	if purger.cancel == nil {
+		return nil
+	}
+
+	purger.logger.Info().Msg("Shutting down trash purger")
+	purger.cancel()
+	if err := <-purger.closed; err != nil && !errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	return nil
+}
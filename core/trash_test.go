@@ -0,0 +1,179 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/image"
+	"api/storage"
+	"context"
+	"errors"
+	"github.com/rs/zerolog"
+	"testing"
+	"time"
+)
+
+// trashImagesRepoStub keeps the deleted images and records the purged and restored ones
+type trashImagesRepoStub struct {
+	storage.ImageRepoMock
+	deleted  storage.ImageList
+	before   time.Time
+	purged   []string
+	restored []string
+	versions map[string][]storage.ImageVersion
+}
+
+func (repo *trashImagesRepoStub) GetVersions(_ context.Context, imageId string) ([]storage.ImageVersion, error) {
+	return repo.versions[imageId], nil
+}
+
+func (repo *trashImagesRepoStub) GetDeletedBefore(
+	_ context.Context, before time.Time, _ int,
+) (storage.ImageList, error) {
+	repo.before = before
+	return repo.deleted, nil
+}
+
+func (repo *trashImagesRepoStub) Purge(_ context.Context, id string) error {
+	repo.purged = append(repo.purged, id)
+	return nil
+}
+
+func (repo *trashImagesRepoStub) Restore(_ context.Context, id string) (storage.Image, error) {
+	repo.restored = append(repo.restored, id)
+	return storage.Image{Id: id, Name: "my-image-1"}, nil
+}
+
+// trashResizerStub fails deleting the files of the image named failing
+type trashResizerStub struct {
+	image.Mock
+	failing     string
+	deleted     []string
+	invalidated []string
+}
+
+func (resizer *trashResizerStub) Invalidate(_ context.Context, _ string, request image.DeleteRequest) error {
+	resizer.invalidated = append(resizer.invalidated, request.Name)
+	return nil
+}
+
+func (resizer *trashResizerStub) Delete(_ context.Context, _ string, request image.DeleteRequest) error {
+	if request.Name == resizer.failing {
+		return errors.New("bucket unavailable")
+	}
+	resizer.deleted = append(resizer.deleted, request.Name)
+	return nil
+}
+
+func TestImagesService_PurgeTrash(t *testing.T) {
+	logger := zerolog.Nop()
+	repo := &trashImagesRepoStub{deleted: storage.ImageList{
+		{Id: "1", Name: "my-image-1"},
+		{Id: "2", Name: "my-image-2"},
+		{Id: "3", Name: "my-image-3"},
+	}}
+	repo.versions = map[string][]storage.ImageVersion{
+		"1": {{ImageId: "1", Version: 1, Name: "my-image-1", FileName: "versions/1/a"}},
+	}
+	resizer := &trashResizerStub{failing: "my-image-2"}
+	service := &ImagesService{imagesRepository: repo, resizeApi: resizer, logger: &logger}
+
+	count, err := service.PurgeTrash(context.Background(), 24*time.Hour)
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if count != 1 || len(repo.purged) != 1 || repo.purged[0] != "1" {
+		t.Fatalf("Expected only the image before the failure to be purged, got %d %v", count, repo.purged)
+	}
+	expected := []string{"my-image-1", "versions/1/a"}
+	if len(resizer.deleted) != 2 || resizer.deleted[0] != expected[0] || resizer.deleted[1] != expected[1] {
+		t.Fatalf("Expected files of my-image-1 and its version to be deleted, got %v", resizer.deleted)
+	}
+	if len(resizer.invalidated) != 2 || resizer.invalidated[0] != expected[0] || resizer.invalidated[1] != expected[1] {
+		t.Fatalf("Expected files of my-image-1 and its version to be invalidated, got %v", resizer.invalidated)
+	}
+	if since := time.Since(repo.before); since < 24*time.Hour || since > 25*time.Hour {
+		t.Fatalf("Expected images deleted before the retention period, got %s", repo.before)
+	}
+}
+
+func TestImagesService_RestoreImage(t *testing.T) {
+	const imageId = "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	values := []struct {
+		Name string
+		Role storage.AuthRole
+	}{
+		{Name: "Admin", Role: storage.AuthRoleAdmin},
+		{Name: "Not admin", Role: storage.AuthRoleNone},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			logger := zerolog.Nop()
+			audit := &auditRepoStub{}
+			repo := &trashImagesRepoStub{}
+			service := &ImagesService{
+				imagesRepository: repo,
+				authenticator:    &auditAuthenticatorStub{user: storage.User{Id: "admin", Role: data.Role}},
+				audit:            NewAuditLogger(audit, &logger),
+				logger:           &logger,
+			}
+
+			img, err := service.RestoreImage(context.Background(), auth.AuthorizationDto{}, imageId)
+			if data.Role != storage.AuthRoleAdmin {
+				var forbidden exception.Forbidden
+				if !errors.As(err, &forbidden) {
+					t.Fatalf("Expected forbidden, got %v", err)
+				}
+				if len(repo.restored) != 0 || len(audit.events) != 0 {
+					t.Fatalf("Expected nothing restored, got %v", repo.restored)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+			if img.Id != imageId || len(repo.restored) != 1 {
+				t.Fatalf("Expected image %s to be restored, got %+v", imageId, img)
+			}
+			if len(audit.events) != 1 || audit.events[0].Action != storage.AuditImageRestored {
+				t.Fatalf("Expected the restore to be audited, got %+v", audit.events)
+			}
+		})
+	}
+}
+
+func TestTrashPurger_WithoutServiceToken(t *testing.T) {
+	logger := zerolog.Nop()
+	values := []struct {
+		Name            string
+		Config          Config
+		ExpectedEnabled bool
+	}{
+		{Name: "Remote without token", Config: Config{ImagesResizer: RemoteImagesResizer}},
+		{
+			Name:            "Remote with token",
+			Config:          Config{ImagesResizer: RemoteImagesResizer, ImagesApiServiceToken: "token"},
+			ExpectedEnabled: true,
+		},
+		{Name: "Local", Config: Config{ImagesResizer: LocalImagesResizer}, ExpectedEnabled: true},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			purger := NewTrashPurger(data.Config, &ImagesService{}, &logger)
+			recoverer := NewUploadSagaRecoverer(data.Config, &ImagesService{}, &logger)
+			if purger.enabled != data.ExpectedEnabled || recoverer.enabled != data.ExpectedEnabled {
+				t.Fatalf("Expected enabled %v, got %v and %v", data.ExpectedEnabled, purger.enabled, recoverer.enabled)
+			}
+		})
+	}
+
+	// Disabled ones never start, so shutting them down returns right away
+	purger := NewTrashPurger(Config{ImagesResizer: RemoteImagesResizer}, &ImagesService{}, &logger)
+	purger.StartAsync(context.Background())
+	if err := purger.Shutdown(); err != nil || purger.cancel != nil {
+		t.Fatalf("Expected the purger not to start, got %v", err)
+	}
+}
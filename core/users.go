@@ -0,0 +1,72 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"errors"
+	"fmt"
+)
+
+func toUserError(err error) error {
+	var notFound storage.NotFound
+	if errors.As(err, &notFound) {
+		return exception.NotFound{Msg: notFound.Msg}
+	}
+	return err
+}
+
+// UsersService changes the roles of the users, the role stored with a user is the one every admin check reads
+type UsersService struct {
+	repository    storage.UserRepository
+	authenticator auth.Authenticator
+	audit         *AuditLogger
+}
+
+func NewUsersService(
+	repository storage.UserRepository, authenticator auth.Authenticator, audit *AuditLogger,
+) *UsersService {
+	return &UsersService{repository: repository, authenticator: authenticator, audit: audit}
+}
+
+// SetUserRole gives the user the role, requires the admin role. Admins can not change their own role, so that the
+// last admin can not lock everyone out.
+func (service *UsersService) SetUserRole(
+	ctx context.Context, authorization auth.AuthorizationDto, userId, role string,
+) (storage.User, error) {
+	if err := parseUuids(userId); err != nil {
+		return storage.User{}, err
+	}
+	newRole, err := storage.NewAuthRole(role)
+	if err != nil {
+		return storage.User{}, exception.InvalidArgument{Reason: fmt.Sprintf("Invalid role %s", role)}
+	}
+
+	actor, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.User{}, err
+	}
+	if actor.Role != storage.AuthRoleAdmin {
+		return storage.User{}, exception.Forbidden{}
+	}
+	if actor.Id == userId {
+		return storage.User{}, exception.InvalidArgument{Reason: "Admins can not change their own role"}
+	}
+
+	previous, err := service.repository.GetById(ctx, userId)
+	if err != nil {
+		return storage.User{}, toUserError(err)
+	}
+	if previous.Role == newRole {
+		return previous, nil
+	}
+
+	updated, err := service.repository.UpdateRole(ctx, userId, newRole)
+	if err != nil {
+		return storage.User{}, toUserError(err)
+	}
+	service.audit.Log(ctx, actor.Id, storage.AuditUserRoleChanged, userId, previous, updated)
+
+	return updated, nil
+}
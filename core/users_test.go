@@ -0,0 +1,138 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/rs/zerolog"
+	"testing"
+)
+
+const (
+	usersAdminIdMock = "0c5a1e3d-7b9f-4d2a-8e6c-1f3b5d7a9c20"
+	usersUserIdMock  = "6f1d3b5a-9c7e-4a2b-8d0f-2e4c6a8b0d13"
+)
+
+// usersRepoStub keeps a single user without a role
+type usersRepoStub struct {
+	storage.UserRepository
+	user storage.User
+}
+
+func (repo *usersRepoStub) GetById(_ context.Context, userId string) (storage.User, error) {
+	if userId != repo.user.Id {
+		return storage.User{}, storage.NotFound{Msg: "User not found " + userId}
+	}
+	return repo.user, nil
+}
+
+func (repo *usersRepoStub) UpdateRole(_ context.Context, _ string, role storage.AuthRole) (storage.User, error) {
+	repo.user.Role = role
+	return repo.user, nil
+}
+
+func newUsersTestService(actorRole storage.AuthRole) (*UsersService, *usersRepoStub, *auditRepoStub) {
+	logger := zerolog.Nop()
+	repo := &usersRepoStub{user: storage.User{Id: usersUserIdMock, Email: "user@example.com"}}
+	audit := &auditRepoStub{}
+	service := NewUsersService(
+		repo,
+		&auditAuthenticatorStub{user: storage.User{Id: usersAdminIdMock, Role: actorRole}},
+		NewAuditLogger(audit, &logger),
+	)
+	return service, repo, audit
+}
+
+func TestSetUserRole_Audit(t *testing.T) {
+	service, repo, audit := newUsersTestService(storage.AuthRoleAdmin)
+
+	updated, err := service.SetUserRole(
+		context.Background(), auth.AuthorizationDto{}, usersUserIdMock, string(storage.AuthRoleAdmin),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Role != storage.AuthRoleAdmin || repo.user.Role != storage.AuthRoleAdmin {
+		t.Fatalf("Expected the user promoted to admin, got %+v", updated)
+	}
+
+	if len(audit.events) != 1 {
+		t.Fatalf("Expected a single event, got %d", len(audit.events))
+	}
+	event := audit.events[0]
+	if event.ActorId != usersAdminIdMock || event.Action != storage.AuditUserRoleChanged ||
+		event.TargetId != usersUserIdMock {
+		t.Fatalf("Expected role change of the user by the admin, got %+v", event)
+	}
+	var before, after storage.User
+	if err = json.Unmarshal(event.Before, &before); err != nil || before.Role != storage.AuthRoleNone {
+		t.Fatalf("Expected the user without a role before, got %s", event.Before)
+	}
+	if err = json.Unmarshal(event.After, &after); err != nil || after.Role != storage.AuthRoleAdmin {
+		t.Fatalf("Expected the admin role after, got %s", event.After)
+	}
+
+	// Setting the current role changes nothing, so nothing is recorded
+	if _, err = service.SetUserRole(
+		context.Background(), auth.AuthorizationDto{}, usersUserIdMock, string(storage.AuthRoleAdmin),
+	); err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.events) != 1 {
+		t.Fatalf("Expected no event for an unchanged role, got %d", len(audit.events))
+	}
+}
+
+func TestSetUserRole_Invalid(t *testing.T) {
+	values := []struct {
+		Name      string
+		ActorRole storage.AuthRole
+		UserId    string
+		Role      string
+		Expected  error
+	}{
+		{Name: "Not admin", UserId: usersUserIdMock, Role: "Administrators", Expected: exception.Forbidden{}},
+		{
+			Name: "Invalid user", ActorRole: storage.AuthRoleAdmin, UserId: "john", Role: "Administrators",
+			Expected: exception.InvalidArgument{},
+		},
+		{
+			Name: "Invalid role", ActorRole: storage.AuthRoleAdmin, UserId: usersUserIdMock, Role: "Owners",
+			Expected: exception.InvalidArgument{},
+		},
+		{
+			Name: "Own role", ActorRole: storage.AuthRoleAdmin, UserId: usersAdminIdMock, Role: "",
+			Expected: exception.InvalidArgument{},
+		},
+		{
+			Name: "Unknown user", ActorRole: storage.AuthRoleAdmin, UserId: "1b3d5f7a-9c2e-4b6d-8f0a-3c5e7a9b1d24",
+			Role: "Administrators", Expected: exception.NotFound{},
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service, _, audit := newUsersTestService(data.ActorRole)
+
+			_, err := service.SetUserRole(context.Background(), auth.AuthorizationDto{}, data.UserId, data.Role)
+			var matches bool
+			switch data.Expected.(type) {
+			case exception.Forbidden:
+				matches = errors.As(err, new(exception.Forbidden))
+			case exception.InvalidArgument:
+				matches = errors.As(err, new(exception.InvalidArgument))
+			case exception.NotFound:
+				matches = errors.As(err, new(exception.NotFound))
+			}
+			if !matches {
+				t.Fatalf("Expected %T, got %v", data.Expected, err)
+			}
+			if len(audit.events) != 0 {
+				t.Fatalf("Expected nothing recorded, got %+v", audit.events)
+			}
+		})
+	}
+}
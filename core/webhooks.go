@@ -0,0 +1,330 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/events"
+	"api/pkg/concurrency"
+	"api/storage"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"golang.org/x/sync/errgroup"
+	"net/url"
+	"time"
+)
+
+const (
+	webhookUrlMaxLength    = 2000
+	webhookSecretMinLength = 16
+	webhookSecretMaxLength = 255
+	// webhookMaxBackoff bounds the wait between attempts, a delivery holds the relay and replay requests meanwhile
+	webhookMaxBackoff = 5 * time.Second
+)
+
+var webhookEventTypes = []storage.EventType{
+	storage.EventImageCreated, storage.EventImageUpdated, storage.EventImageDeleted, storage.EventImageRestored,
+}
+
+// WebhookSender posts the event signed with the secret once, returning the status code of the response
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret string, event events.Event) (int, error)
+}
+
+func validateWebhookInput(input storage.WebhookInput, isSecretRequired bool) error {
+	parsed, err := url.Parse(input.Url)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return exception.InvalidArgument{Reason: "Webhook url must be an absolute http or https url"}
+	}
+	if len(input.Url) > webhookUrlMaxLength {
+		return exception.InvalidArgument{
+			Reason: fmt.Sprintf("Webhook url must not be longer than %d characters", webhookUrlMaxLength),
+		}
+	}
+
+	if input.Secret != "" || isSecretRequired {
+		if len(input.Secret) < webhookSecretMinLength || len(input.Secret) > webhookSecretMaxLength {
+			return exception.InvalidArgument{
+				Reason: fmt.Sprintf(
+					"Webhook secret should be between %d and %d characters",
+					webhookSecretMinLength,
+					webhookSecretMaxLength,
+				),
+			}
+		}
+	}
+
+	for _, event := range input.Events {
+		if !isWebhookEventType(event) {
+			return exception.InvalidArgument{Reason: fmt.Sprintf("Unsupported webhook event %s", event)}
+		}
+	}
+
+	return nil
+}
+
+func isWebhookEventType(value string) bool {
+	for _, eventType := range webhookEventTypes {
+		if string(eventType) == value {
+			return true
+		}
+	}
+	return false
+}
+
+func toWebhookError(err error) error {
+	var notFound storage.NotFound
+	if errors.As(err, &notFound) {
+		return exception.NotFound{Msg: notFound.Msg}
+	}
+	return err
+}
+
+// WebhooksService manages the webhook subscriptions and delivers the image events to them, as an
+// events.EventPublisher for the outbox relay
+type WebhooksService struct {
+	repository    storage.WebhookRepository
+	sender        WebhookSender
+	retry         *concurrency.Retry
+	authenticator auth.Authenticator
+	audit         *AuditLogger
+	logger        *zerolog.Logger
+}
+
+func NewWebhooksService(
+	config Config,
+	repository storage.WebhookRepository,
+	sender WebhookSender,
+	authenticator auth.Authenticator,
+	audit *AuditLogger,
+	logger *zerolog.Logger,
+) *WebhooksService {
+	return &WebhooksService{
+		repository:    repository,
+		sender:        sender,
+		retry:         concurrency.NewRetry(config.WebhookRetries, time.Second).WithBackoff(time.Second, webhookMaxBackoff),
+		authenticator: authenticator,
+		audit:         audit,
+		logger:        logger,
+	}
+}
+
+func (service *WebhooksService) GetWebhooks(ctx context.Context) ([]storage.WebhookSubscription, error) {
+	subscriptions, err := service.repository.GetSubscriptions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching webhooks: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (service *WebhooksService) GetWebhook(
+	ctx context.Context, subscriptionId string,
+) (storage.WebhookSubscription, error) {
+	if err := parseUuids(subscriptionId); err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+
+	subscription, err := service.repository.GetSubscription(ctx, subscriptionId)
+	if err != nil {
+		return storage.WebhookSubscription{}, toWebhookError(err)
+	}
+
+	return subscription, nil
+}
+
+func (service *WebhooksService) CreateWebhook(
+	ctx context.Context, authorization auth.AuthorizationDto, input storage.WebhookInput,
+) (storage.WebhookSubscription, error) {
+	if err := validateWebhookInput(input, true); err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+
+	created, err := service.repository.CreateSubscription(ctx, storage.WebhookSubscription{
+		Url:    input.Url,
+		Secret: input.Secret,
+		Events: input.Events,
+	})
+	if err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditWebhookCreated, created.Id, nil, created)
+
+	return created, nil
+}
+
+func (service *WebhooksService) UpdateWebhook(
+	ctx context.Context, authorization auth.AuthorizationDto, subscriptionId string, input storage.WebhookInput,
+) (storage.WebhookSubscription, error) {
+	if err := validateWebhookInput(input, false); err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+
+	subscription, err := service.GetWebhook(ctx, subscriptionId)
+	if err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+	previous := subscription
+
+	subscription.Url = input.Url
+	subscription.Events = input.Events
+	if input.Secret != "" {
+		subscription.Secret = input.Secret
+	}
+
+	updated, err := service.repository.UpdateSubscription(ctx, subscription)
+	if err != nil {
+		return storage.WebhookSubscription{}, toWebhookError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditWebhookUpdated, updated.Id, previous, updated)
+
+	return updated, nil
+}
+
+func (service *WebhooksService) DeleteWebhook(
+	ctx context.Context, authorization auth.AuthorizationDto, subscriptionId string,
+) error {
+	user, err := service.authenticator.GetOrSyncUser(ctx, authorization)
+	if err != nil {
+		return err
+	}
+
+	previous, err := service.GetWebhook(ctx, subscriptionId)
+	if err != nil {
+		return err
+	}
+	if err = service.repository.DeleteSubscription(ctx, subscriptionId); err != nil {
+		return toWebhookError(err)
+	}
+	service.audit.Log(ctx, user.Id, storage.AuditWebhookDeleted, subscriptionId, previous, nil)
+
+	return nil
+}
+
+func (service *WebhooksService) GetWebhookDeliveries(
+	ctx context.Context, filter storage.WebhookDeliveryFilter, limit, offset int,
+) (storage.WebhookDeliveryPage, error) {
+	if filter.SubscriptionId != "" {
+		if err := parseUuids(filter.SubscriptionId); err != nil {
+			return storage.WebhookDeliveryPage{}, err
+		}
+	}
+	if filter.EventId != "" {
+		if err := parseUuids(filter.EventId); err != nil {
+			return storage.WebhookDeliveryPage{}, err
+		}
+	}
+
+	page, err := service.repository.GetDeliveries(ctx, filter, limit, offset)
+	if err != nil {
+		return storage.WebhookDeliveryPage{}, fmt.Errorf("failed fetching webhook deliveries: %w", err)
+	}
+
+	return page, nil
+}
+
+// ReplayWebhookDelivery delivers the event of a previous delivery again, to the current url of its subscription,
+// and records it as a new delivery
+func (service *WebhooksService) ReplayWebhookDelivery(
+	ctx context.Context, deliveryId string,
+) (storage.WebhookDelivery, error) {
+	if err := parseUuids(deliveryId); err != nil {
+		return storage.WebhookDelivery{}, err
+	}
+
+	delivery, err := service.repository.GetDelivery(ctx, deliveryId)
+	if err != nil {
+		return storage.WebhookDelivery{}, toWebhookError(err)
+	}
+	subscription, err := service.repository.GetSubscription(ctx, delivery.SubscriptionId)
+	if err != nil {
+		return storage.WebhookDelivery{}, toWebhookError(err)
+	}
+
+	var event events.Event
+	if err = json.Unmarshal(delivery.Payload, &event); err != nil {
+		return storage.WebhookDelivery{}, fmt.Errorf("failed reading delivered event %s: %w", delivery.Id, err)
+	}
+
+	return service.deliver(ctx, subscription, event)
+}
+
+// Publish delivers the event to every subscription interested in it. Failed deliveries are only recorded, so that
+// the relay does not deliver the event again to the subscriptions that received it, admins can replay them.
+func (service *WebhooksService) Publish(ctx context.Context, event events.Event) error {
+	subscriptions, err := service.repository.GetSubscriptions(ctx)
+	if err != nil {
+		return fmt.Errorf("failed fetching webhooks: %w", err)
+	}
+
+	g, gCtx := errgroup.WithContext(ctx)
+	for _, subscription := range subscriptions {
+		if !subscription.IsSubscribedTo(storage.EventType(event.Type)) {
+			continue
+		}
+
+		subscription := subscription
+		g.Go(func() error {
+			delivery, err := service.deliver(gCtx, subscription, event)
+			if err != nil {
+				return err
+			}
+			if !delivery.Success {
+				service.logger.Warn().
+					Str("subscriptionId", subscription.Id).
+					Str("eventId", event.Id).
+					Msgf("failed delivering webhook: %s", delivery.Error)
+			}
+			return nil
+		})
+	}
+
+	return g.Wait()
+}
+
+// deliver sends the event with retries and records the outcome
+func (service *WebhooksService) deliver(
+	ctx context.Context, subscription storage.WebhookSubscription, event events.Event,
+) (storage.WebhookDelivery, error) {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return storage.WebhookDelivery{}, err
+	}
+
+	delivery := storage.WebhookDelivery{
+		SubscriptionId: subscription.Id,
+		EventId:        event.Id,
+		EventType:      storage.EventType(event.Type),
+		Payload:        payload,
+	}
+
+	err = service.retry.Execute(ctx, func(ctx context.Context, _ uint) error {
+		var sendErr error
+		delivery.Attempts++
+		delivery.StatusCode, sendErr = service.sender.Send(ctx, subscription.Url, subscription.Secret, event)
+		return sendErr
+	})
+	delivery.Success = err == nil
+	if err != nil {
+		delivery.Error = err.Error()
+	}
+
+	created, err := service.repository.CreateDelivery(ctx, delivery)
+	if err != nil {
+		return storage.WebhookDelivery{}, fmt.Errorf("failed recording webhook delivery: %w", err)
+	}
+
+	return created, nil
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/events"
+	"api/events/webhook"
+	"api/pkg/concurrency"
+	"api/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/rs/zerolog"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+const (
+	webhookSecretMock         = "a-very-secret-secret"
+	webhookSubscriptionIdMock = "5f0c2f7e-3c1b-4b8e-9a44-0d8c2f9e1a10"
+)
+
+type webhookRepoStub struct {
+	storage.WebhookRepository
+	mutex         sync.Mutex
+	subscriptions []storage.WebhookSubscription
+	deliveries    []storage.WebhookDelivery
+}
+
+func (repo *webhookRepoStub) GetSubscriptions(_ context.Context) ([]storage.WebhookSubscription, error) {
+	return repo.subscriptions, nil
+}
+
+func (repo *webhookRepoStub) GetSubscription(
+	_ context.Context, subscriptionId string,
+) (storage.WebhookSubscription, error) {
+	for _, subscription := range repo.subscriptions {
+		if subscription.Id == subscriptionId {
+			return subscription, nil
+		}
+	}
+	return storage.WebhookSubscription{}, storage.NotFound{Msg: "Webhook not found " + subscriptionId}
+}
+
+func (repo *webhookRepoStub) CreateDelivery(
+	_ context.Context, delivery storage.WebhookDelivery,
+) (storage.WebhookDelivery, error) {
+	repo.mutex.Lock()
+	defer repo.mutex.Unlock()
+
+	delivery.Id = fmt.Sprintf("00000000-0000-4000-8000-%012d", len(repo.deliveries)+1)
+	repo.deliveries = append(repo.deliveries, delivery)
+	return delivery, nil
+}
+
+func (repo *webhookRepoStub) GetDelivery(_ context.Context, deliveryId string) (storage.WebhookDelivery, error) {
+	for _, delivery := range repo.deliveries {
+		if delivery.Id == deliveryId {
+			return delivery, nil
+		}
+	}
+	return storage.WebhookDelivery{}, storage.NotFound{Msg: "Webhook delivery not found " + deliveryId}
+}
+
+// newWebhookReceiver counts the deliveries with a valid signature and answers with the given status
+func newWebhookReceiver(t *testing.T, status *int32) (*httptest.Server, *int32) {
+	var received int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		signature := r.Header.Get(webhook.SignatureHeader)
+		if !webhook.Verify(webhookSecretMock, r.Header.Get(webhook.TimestampHeader), body, signature) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		atomic.AddInt32(&received, 1)
+		w.WriteHeader(int(atomic.LoadInt32(status)))
+	}))
+	t.Cleanup(server.Close)
+
+	return server, &received
+}
+
+func newWebhooksTestService(subscriptions ...storage.WebhookSubscription) (*WebhooksService, *webhookRepoStub) {
+	logger := zerolog.Nop()
+	repo := &webhookRepoStub{subscriptions: subscriptions}
+
+	return &WebhooksService{
+		repository:    repo,
+		sender:        webhook.NewSender(),
+		retry:         concurrency.NewRetry(2, 0).WithBackoff(time.Millisecond, time.Millisecond),
+		authenticator: &auth.Mock{},
+		audit:         NewAuditLogger(storage.AuditRepoMock{}, &logger),
+		logger:        &logger,
+	}, repo
+}
+
+func TestWebhooksService_Publish(t *testing.T) {
+	okStatus := int32(http.StatusOK)
+	failingStatus := int32(http.StatusInternalServerError)
+	subscribed, subscribedReceived := newWebhookReceiver(t, &okStatus)
+	other, otherReceived := newWebhookReceiver(t, &okStatus)
+	failing, failingReceived := newWebhookReceiver(t, &failingStatus)
+
+	service, repo := newWebhooksTestService(
+		storage.WebhookSubscription{Id: "1", Url: subscribed.URL, Secret: webhookSecretMock},
+		storage.WebhookSubscription{
+			Id: "2", Url: other.URL, Secret: webhookSecretMock, Events: []string{string(storage.EventImageDeleted)},
+		},
+		storage.WebhookSubscription{Id: "3", Url: failing.URL, Secret: webhookSecretMock},
+	)
+
+	event := events.Event{Id: "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10", Type: string(storage.EventImageCreated)}
+	if err := service.Publish(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+
+	if *subscribedReceived != 1 || *otherReceived != 0 {
+		t.Fatalf("Expected only the subscribed webhook to receive, got %d and %d", *subscribedReceived, *otherReceived)
+	}
+	if *failingReceived != 3 {
+		t.Fatalf("Expected the failing webhook to be retried twice, got %d deliveries", *failingReceived)
+	}
+	if len(repo.deliveries) != 2 {
+		t.Fatalf("Expected 2 recorded deliveries, got %d", len(repo.deliveries))
+	}
+	for _, delivery := range repo.deliveries {
+		expectedSuccess := delivery.SubscriptionId == "1"
+		if delivery.Success != expectedSuccess || delivery.EventId != event.Id {
+			t.Fatalf("Unexpected delivery %+v", delivery)
+		}
+		if !expectedSuccess && (delivery.Attempts != 3 || delivery.StatusCode != http.StatusInternalServerError) {
+			t.Fatalf("Expected 3 attempts with status 500, got %+v", delivery)
+		}
+	}
+}
+
+func TestWebhooksService_ReplayWebhookDelivery(t *testing.T) {
+	status := int32(http.StatusServiceUnavailable)
+	receiver, received := newWebhookReceiver(t, &status)
+	service, repo := newWebhooksTestService(
+		storage.WebhookSubscription{Id: webhookSubscriptionIdMock, Url: receiver.URL, Secret: webhookSecretMock},
+	)
+
+	event := events.Event{Id: "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10", Type: string(storage.EventImageUpdated)}
+	if err := service.Publish(context.Background(), event); err != nil {
+		t.Fatal(err)
+	}
+	if len(repo.deliveries) != 1 || repo.deliveries[0].Success {
+		t.Fatalf("Expected a failed delivery, got %+v", repo.deliveries)
+	}
+
+	atomic.StoreInt32(&status, http.StatusOK)
+	replayed, err := service.ReplayWebhookDelivery(context.Background(), repo.deliveries[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !replayed.Success || replayed.EventId != event.Id || replayed.Id == repo.deliveries[0].Id {
+		t.Fatalf("Expected a new successful delivery of %s, got %+v", event.Id, replayed)
+	}
+	if *received != 4 {
+		t.Fatalf("Expected 4 received deliveries, got %d", *received)
+	}
+}
+
+func TestValidateWebhookInput(t *testing.T) {
+	values := []struct {
+		Name  string
+		Input storage.WebhookInput
+	}{
+		{Name: "Relative url", Input: storage.WebhookInput{Url: "/hooks", Secret: webhookSecretMock}},
+		{Name: "Other scheme", Input: storage.WebhookInput{Url: "ftp://partner.com", Secret: webhookSecretMock}},
+		{Name: "Short secret", Input: storage.WebhookInput{Url: "https://partner.com", Secret: "secret"}},
+		{
+			Name: "Unknown event",
+			Input: storage.WebhookInput{
+				Url: "https://partner.com", Secret: webhookSecretMock, Events: []string{"image.viewed"},
+			},
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			service, _ := newWebhooksTestService()
+			_, err := service.CreateWebhook(context.Background(), auth.AuthorizationDto{}, data.Input)
+
+			var invalidArgument exception.InvalidArgument
+			if !errors.As(err, &invalidArgument) {
+				t.Fatalf("Expected invalid argument, got %v", err)
+			}
+		})
+	}
+}
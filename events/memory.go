@@ -0,0 +1,36 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryPublisher keeps the published events in memory for tests, nothing ever removes them
+type MemoryPublisher struct {
+	mutex  sync.RWMutex
+	events []Event
+}
+
+func NewMemoryPublisher() *MemoryPublisher {
+	return &MemoryPublisher{}
+}
+
+func (publisher *MemoryPublisher) Publish(_ context.Context, event Event) error {
+	publisher.mutex.Lock()
+	defer publisher.mutex.Unlock()
+
+	publisher.events = append(publisher.events, event)
+
+	return nil
+}
+
+// Events returns a copy of the published events in the order they were published
+func (publisher *MemoryPublisher) Events() []Event {
+	publisher.mutex.RLock()
+	defer publisher.mutex.RUnlock()
+
+	events := make([]Event, len(publisher.events))
+	copy(events, publisher.events)
+
+	return events
+}
@@ -0,0 +1,21 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+type Event struct {
+	Id        string          `json:"id"`
+	Type      string          `json:"type"`
+	ImageId   string          `json:"imageId"`
+	Payload   json.RawMessage `json:"payload"`
+	CreatedAt time.Time       `json:"createdAt"`
+}
+
+// EventPublisher delivers the image lifecycle events to other services. Events are delivered at least once, so
+// receivers should deduplicate them by id.
+type EventPublisher interface {
+	Publish(ctx context.Context, event Event) error
+}
@@ -0,0 +1,89 @@
+package webhook
+
+import (
+	"api/events"
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	// SignatureHeader carries sha256=<hex HMAC-SHA256 of "<timestamp>.<body>"> keyed with the subscription secret
+	SignatureHeader = "X-Webhook-Signature"
+	// TimestampHeader carries the unix seconds of the delivery, receivers can reject old ones to prevent replays
+	TimestampHeader = "X-Webhook-Timestamp"
+	EventIdHeader   = "X-Event-Id"
+	EventTypeHeader = "X-Event-Type"
+)
+
+// Sign returns the signature of the body sent at the timestamp
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify tells whether the signature was made for the body and timestamp with the secret
+func Verify(secret, timestamp string, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// Sender posts signed events to the webhook subscriptions
+type Sender struct {
+	client *http.Client
+	now    func() time.Time
+}
+
+func NewSender() *Sender {
+	return &Sender{
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		now: time.Now,
+	}
+}
+
+// Send posts the event once and returns the status code of the response, any status outside 2xx is an error
+func (sender *Sender) Send(ctx context.Context, url, secret string, event events.Event) (int, error) {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return 0, err
+	}
+	timestamp := strconv.FormatInt(sender.now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIdHeader, event.Id)
+	req.Header.Set(EventTypeHeader, event.Type)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(secret, timestamp, body))
+
+	res, err := sender.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed posting event %s: %w", event.Id, err)
+	}
+	defer res.Body.Close()
+	// Drained so that the connection can be reused
+	_, _ = io.Copy(ioutil.Discard, res.Body)
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return res.StatusCode, fmt.Errorf("webhook responded to event %s with status %d", event.Id, res.StatusCode)
+	}
+
+	return res.StatusCode, nil
+}
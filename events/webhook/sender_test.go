@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"api/events"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const secretMock = "a-very-secret-secret"
+
+func newTestSender(t *testing.T, handler http.HandlerFunc) (*Sender, string) {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	sender := &Sender{client: server.Client(), now: func() time.Time { return time.Unix(1650000000, 0) }}
+	return sender, server.URL
+}
+
+func TestSign(t *testing.T) {
+	signature := Sign(secretMock, "1650000000", []byte(`{"id":"1"}`))
+	if !Verify(secretMock, "1650000000", []byte(`{"id":"1"}`), signature) {
+		t.Fatalf("Expected signature %s to be verified", signature)
+	}
+	if Verify("another-secret", "1650000000", []byte(`{"id":"1"}`), signature) {
+		t.Fatal("Expected signature of another secret to fail")
+	}
+	if Verify(secretMock, "1650000001", []byte(`{"id":"1"}`), signature) {
+		t.Fatal("Expected signature of another timestamp to fail")
+	}
+}
+
+func TestSender_Send(t *testing.T) {
+	event := events.Event{
+		Id:      "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10",
+		Type:    "image.created",
+		ImageId: "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
+		Payload: json.RawMessage(`{"name":"my-plane"}`),
+	}
+
+	var received events.Event
+	sender, url := newTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		timestamp := r.Header.Get(TimestampHeader)
+		if timestamp != "1650000000" || !Verify(secretMock, timestamp, body, r.Header.Get(SignatureHeader)) {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Header.Get(EventTypeHeader) != event.Type || r.Header.Get(EventIdHeader) != event.Id {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if err = json.Unmarshal(body, &received); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	statusCode, err := sender.Send(context.Background(), url, secretMock, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if statusCode != http.StatusNoContent {
+		t.Fatalf("Expected status %d, got %d", http.StatusNoContent, statusCode)
+	}
+	if received.Id != event.Id || string(received.Payload) != string(event.Payload) {
+		t.Fatalf("Expected %+v, got %+v", event, received)
+	}
+}
+
+func TestSender_SendFailure(t *testing.T) {
+	sender, url := newTestSender(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	statusCode, err := sender.Send(context.Background(), url, secretMock, events.Event{Id: "1"})
+	if err == nil {
+		t.Fatal("Expected error, got nil")
+	}
+	if statusCode != http.StatusServiceUnavailable {
+		t.Fatalf("Expected status %d, got %d", http.StatusServiceUnavailable, statusCode)
+	}
+}
@@ -0,0 +1,12 @@
+package http_server
+
+import (
+	"api/storage"
+	"context"
+)
+
+type AuditHandler interface {
+	GetAuditEvents(
+		ctx context.Context, filter storage.AuditEventFilter, limit, offset int,
+	) (storage.AuditEventPage, error)
+}
@@ -0,0 +1,30 @@
+package http_server
+
+import (
+	"api/storage"
+	"context"
+	"encoding/json"
+)
+
+const auditEventIdMock = "2e4f6a8b-0c1d-4e3f-8a5b-7c9d1e3f5a70"
+
+type AuditHandlerMock struct {
+}
+
+func (h AuditHandlerMock) GetAuditEvents(
+	_ context.Context, filter storage.AuditEventFilter, _, _ int,
+) (storage.AuditEventPage, error) {
+	event := storage.AuditEvent{
+		Id:        auditEventIdMock,
+		ActorId:   "0c5a1e3d-7b9f-4d2a-8e6c-1f3b5d7a9c20",
+		Action:    storage.AuditImageDeleted,
+		TargetId:  "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
+		Before:    json.RawMessage(`{"name":"my-image-1"}`),
+		RequestId: "c9k2ncu8s3b1g5p0l2a0",
+		ClientIp:  "127.0.0.1",
+	}
+	if filter.Action != "" && filter.Action != event.Action {
+		return storage.AuditEventPage{Events: []storage.AuditEvent{}}, nil
+	}
+	return storage.AuditEventPage{Events: []storage.AuditEvent{event}, Total: 1}, nil
+}
@@ -0,0 +1,46 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/http_server/authenticator"
+	"api/http_server/http_util"
+	"api/http_server/middleware"
+	"api/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+// AuditRouter exposes the audit log of the admin actions to admins only
+func AuditRouter(handler AuditHandler, logger *zerolog.Logger, authenticator authenticator.Authenticator) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/",
+			middleware.Authorize(FetchAuditEvents(handler, logger), authenticator, auth.RoleAdmin),
+		)
+	}
+}
+
+func FetchAuditEvents(handler AuditHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		filter := storage.AuditEventFilter{
+			ActorId:  query.Get("actorId"),
+			Action:   storage.AuditAction(query.Get("action")),
+			TargetId: query.Get("targetId"),
+		}
+
+		page := http_util.ToUint(query.Get("page"))
+		size := http_util.ToUint(query.Get("size"))
+		limit, offset := storage.PagingToLimitOffset(page, size)
+
+		result, err := handler.GetAuditEvents(r.Context(), filter, limit, offset)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(
+			w, http.StatusOK, http_util.NewPageResponse(result.Events, result.Total, limit, offset, result.HasNext),
+		)
+	}
+}
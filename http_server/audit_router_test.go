@@ -0,0 +1,54 @@
+package http_server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestFetchAuditEvents(t *testing.T) {
+	testServer := newTestServer(t)
+	auditUrl := testServer.URL + "/api/v1/audit"
+
+	data := []struct {
+		name          string
+		url           string
+		expectedTotal float64
+	}{
+		{name: "All", url: auditUrl + "?page=1&size=10", expectedTotal: 1},
+		{name: "By action", url: auditUrl + "?action=image.deleted", expectedTotal: 1},
+		{name: "By other action", url: auditUrl + "?action=tag.created", expectedTotal: 0},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, http.MethodGet, d.url, "")
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+			}
+
+			var page map[string]interface{}
+			if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			if page["total"] != d.expectedTotal {
+				t.Fatalf("Expected total %v, got %+v", d.expectedTotal, page)
+			}
+		})
+	}
+}
+
+func TestFetchAuditEvents_Unauthorized(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res, err := http.Get(testServer.URL + "/api/v1/audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code 401, got %d", res.StatusCode)
+	}
+}
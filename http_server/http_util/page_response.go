@@ -0,0 +1,30 @@
+package http_util
+
+const ApiVersion = "v1"
+
+// PageResponse is the versioned envelope of paginated lists, Page is 0 when paging by cursor
+type PageResponse struct {
+	ApiVersion string      `json:"apiVersion"`
+	Items      interface{} `json:"items"`
+	Total      int         `json:"total"`
+	Page       int         `json:"page"`
+	Size       int         `json:"size"`
+	HasNext    bool        `json:"hasNext"`
+	NextCursor string      `json:"nextCursor,omitempty"`
+}
+
+func NewPageResponse(items interface{}, total, limit, offset int, hasNext bool) PageResponse {
+	page := 0
+	if limit > 0 {
+		page = offset/limit + 1
+	}
+
+	return PageResponse{
+		ApiVersion: ApiVersion,
+		Items:      items,
+		Total:      total,
+		Page:       page,
+		Size:       limit,
+		HasNext:    hasNext,
+	}
+}
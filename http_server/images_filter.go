@@ -0,0 +1,58 @@
+package http_server
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseTimeParam accepts both RFC 3339 timestamps and plain dates, where dates are treated as midnight UTC
+func parseTimeParam(name, value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, dateLayout} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			utc := parsed.UTC()
+			return &utc, nil
+		}
+	}
+
+	return nil, exception.InvalidArgument{
+		Reason: fmt.Sprintf("Invalid %s, expected RFC 3339 date-time or a %s date", name, dateLayout),
+	}
+}
+
+func parseImageFilter(query url.Values) (storage.ImageFilter, error) {
+	createdAfter, err := parseTimeParam("createdAfter", query.Get("createdAfter"))
+	if err != nil {
+		return storage.ImageFilter{}, err
+	}
+	createdBefore, err := parseTimeParam("createdBefore", query.Get("createdBefore"))
+	if err != nil {
+		return storage.ImageFilter{}, err
+	}
+	capturedAfter, err := parseTimeParam("capturedAfter", query.Get("capturedAfter"))
+	if err != nil {
+		return storage.ImageFilter{}, err
+	}
+	capturedBefore, err := parseTimeParam("capturedBefore", query.Get("capturedBefore"))
+	if err != nil {
+		return storage.ImageFilter{}, err
+	}
+
+	return storage.ImageFilter{
+		Tag:            query.Get("tag"),
+		Format:         storage.ImageFormat(query.Get("format")),
+		AuthorId:       query.Get("authorId"),
+		CreatedAfter:   createdAfter,
+		CreatedBefore:  createdBefore,
+		CapturedAfter:  capturedAfter,
+		CapturedBefore: capturedBefore,
+	}, nil
+}
@@ -0,0 +1,64 @@
+package http_server
+
+import (
+	"api/storage"
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseImageFilter(t *testing.T) {
+	after := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2021, 6, 1, 10, 30, 0, 0, time.UTC)
+
+	data := []struct {
+		testName  string
+		query     string
+		expected  storage.ImageFilter
+		isInvalid bool
+	}{
+		{testName: "Empty", query: "", expected: storage.ImageFilter{}},
+		{
+			testName: "All filters",
+			query:    "tag=planes&format=png&authorId=user-1&createdAfter=2021-05-01&createdBefore=2021-06-01T12:30:00%2B02:00",
+			expected: storage.ImageFilter{
+				Tag:           "planes",
+				Format:        storage.PngFormat,
+				AuthorId:      "user-1",
+				CreatedAfter:  &after,
+				CreatedBefore: &before,
+			},
+		},
+		{
+			testName: "Capture date range",
+			query:    "capturedAfter=2021-05-01&capturedBefore=2021-06-01T10:30:00Z",
+			expected: storage.ImageFilter{CapturedAfter: &after, CapturedBefore: &before},
+		},
+		{testName: "Invalid date", query: "createdAfter=yesterday", isInvalid: true},
+		{testName: "Invalid capture date", query: "capturedBefore=2021-13-01", isInvalid: true},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			query, err := url.ParseQuery(d.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			filter, err := parseImageFilter(query)
+			if d.isInvalid {
+				if err == nil {
+					t.Fatal("Expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(filter, d.expected) {
+				t.Fatalf("Expected %+v, got %+v", d.expected, filter)
+			}
+		})
+	}
+}
@@ -5,16 +5,32 @@ import (
 	"api/image"
 	"api/storage"
 	"context"
+	"golang.org/x/text/language"
 	"mime/multipart"
 )
 
 type ImagesHandler interface {
-	Get(ctx context.Context, limit, offset int, order storage.Order) (storage.ImageList, error)
-	GetOne(ctx context.Context, imageId string) (storage.Image, error)
+	Get(
+		ctx context.Context, paging storage.Paging, filter storage.ImageFilter, preferences []language.Tag,
+	) (storage.ImagePage, error)
+	Search(ctx context.Context, text string, limit, offset int) (storage.ImagePage, error)
+	GetOne(ctx context.Context, imageId string, preferences []language.Tag) (storage.Image, error)
+	GetOneBySlug(ctx context.Context, slug string) (image storage.Image, isRedirect bool, err error)
 	UploadAndResize(
 		ctx context.Context,
 		authorization auth.AuthorizationDto,
 		imageName string,
+		description storage.ImageDescription,
+		format image.Format,
+		originalFile *multipart.FileHeader,
+		croppedFile *multipart.FileHeader,
+	) (storage.Image, error)
+	Update(
+		ctx context.Context,
+		imageId string,
+		authorization auth.AuthorizationDto,
+		imageName string,
+		description storage.ImageDescriptionUpdate,
 		format image.Format,
 		originalFile *multipart.FileHeader,
 		croppedFile *multipart.FileHeader,
@@ -24,4 +40,22 @@ type ImagesHandler interface {
 		auth auth.AuthorizationDto,
 		imageId string,
 	) error
+	RestoreImage(ctx context.Context, authorization auth.AuthorizationDto, imageId string) (storage.Image, error)
+	GetImageVersions(ctx context.Context, imageId string) ([]storage.ImageVersion, error)
+	GetSimilarImages(ctx context.Context, imageId string, maxDistance int) ([]storage.SimilarImage, error)
+	GetMetadata(ctx context.Context, imageId string) (storage.ImageMetadata, error)
+	GetTranslations(ctx context.Context, imageId string) ([]storage.ImageTranslation, error)
+	SetTranslation(
+		ctx context.Context, authorization auth.AuthorizationDto, imageId, locale, altText, caption string,
+	) (storage.ImageTranslation, error)
+	DeleteTranslation(ctx context.Context, authorization auth.AuthorizationDto, imageId, locale string) error
+	RestoreImageVersion(
+		ctx context.Context, authorization auth.AuthorizationDto, imageId string, version int,
+	) (storage.Image, error)
+	AttachTag(
+		ctx context.Context, authorization auth.AuthorizationDto, imageId, tagId string,
+	) (storage.Image, error)
+	DetachTag(
+		ctx context.Context, authorization auth.AuthorizationDto, imageId, tagId string,
+	) (storage.Image, error)
 }
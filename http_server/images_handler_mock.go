@@ -2,23 +2,42 @@ package http_server
 
 import (
 	"api/auth"
+	"api/core/exception"
 	"api/image"
 	"api/storage"
 	"context"
 	"fmt"
+	"golang.org/x/text/language"
 	"mime/multipart"
+	"time"
 )
 
 type ImagesHandlerMock struct {
 }
 
+// ImagesHandlerNextCursorMock is returned by ImagesHandlerMock.Get when paging by page number
+var ImagesHandlerNextCursorMock = &storage.Cursor{
+	CreatedAt: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+	Id:        "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
+}
+
 func (h ImagesHandlerMock) Get(
-	_ context.Context, limit, offset int, order storage.Order,
-) (storage.ImageList, error) {
-	if limit != 10 || offset != 10 || order != storage.OrderAscending {
-		return storage.ImageList{}, fmt.Errorf(
+	_ context.Context, paging storage.Paging, _ storage.ImageFilter, preferences []language.Tag,
+) (storage.ImagePage, error) {
+	if paging.Cursor != nil {
+		if paging.Limit != 10 || paging.Offset != 0 || paging.Cursor.Id != ImagesHandlerNextCursorMock.Id {
+			return storage.ImagePage{}, fmt.Errorf(
+				"expecting limit %d got %d | expecting offset %d got %d | expecting cursor %s got %s",
+				10, paging.Limit, 0, paging.Offset, ImagesHandlerNextCursorMock.Id, paging.Cursor.Id,
+			)
+		}
+		return storage.ImagePage{Images: storage.ImageList{}, Total: 21}, nil
+	}
+
+	if paging.Limit != 10 || paging.Offset != 10 || paging.Order != storage.OrderAscending {
+		return storage.ImagePage{}, fmt.Errorf(
 			"expecting limit %d got %d | expecting offset %d got %d and expecting order %s got %s",
-			10, limit, 10, offset, storage.OrderDescending, order,
+			10, paging.Limit, 10, paging.Offset, storage.OrderAscending, paging.Order,
 		)
 	}
 	images := storage.ImageList{
@@ -49,25 +68,94 @@ func (h ImagesHandlerMock) Get(
 			},
 			CreatedAt: nil,
 			UpdatedAt: nil,
+			Locale:    mockLocale(preferences),
 		},
 	}
 
-	return images, nil
+	return storage.ImagePage{Images: images, Total: 21, HasNext: true, NextCursor: ImagesHandlerNextCursorMock}, nil
+}
+
+func (h ImagesHandlerMock) Search(
+	_ context.Context, text string, limit, offset int,
+) (storage.ImagePage, error) {
+	if text == "" {
+		return storage.ImagePage{}, exception.InvalidArgument{Reason: "Search query is required"}
+	}
+	if limit != 10 || offset != 0 {
+		return storage.ImagePage{}, fmt.Errorf(
+			"expecting limit %d got %d | expecting offset %d got %d", 10, limit, 0, offset,
+		)
+	}
+
+	return storage.ImagePage{
+		Images: storage.ImageList{{Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263", Name: text}},
+		Total:  1,
+	}, nil
+}
+
+func (h ImagesHandlerMock) GetOne(
+	_ context.Context, imageId string, preferences []language.Tag,
+) (storage.Image, error) {
+	return storage.Image{Id: imageId, Locale: mockLocale(preferences)}, nil
+}
+
+// mockLocale is de when German is preferred most, images are only translated to German
+func mockLocale(preferences []language.Tag) string {
+	if len(preferences) > 0 {
+		if base, _ := preferences[0].Base(); base.String() == "de" {
+			return "de"
+		}
+	}
+	return "en"
 }
 
-func (h ImagesHandlerMock) GetOne(ctx context.Context, imageId string) (storage.Image, error) {
-	return storage.Image{}, nil
+// GetOneBySlug knows my-new-plane which was previously named my-plane
+func (h ImagesHandlerMock) GetOneBySlug(_ context.Context, slug string) (storage.Image, bool, error) {
+	img := storage.Image{Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263", Name: "my-new-plane"}
+
+	switch slug {
+	case "my-new-plane":
+		return img, false, nil
+	case "my-plane":
+		return img, true, nil
+	}
+
+	return storage.Image{}, false, exception.NotFound{Msg: "Image not found " + slug}
 }
 
 func (h ImagesHandlerMock) UploadAndResize(
 	ctx context.Context,
 	authorization auth.AuthorizationDto,
 	imageName string,
+	description storage.ImageDescription,
 	format image.Format,
 	originalFile *multipart.FileHeader,
 	croppedFile *multipart.FileHeader,
 ) (storage.Image, error) {
-	return storage.Image{}, nil
+	return storage.Image{Name: imageName, ImageDescription: description}, nil
+}
+
+func (h ImagesHandlerMock) Update(
+	_ context.Context,
+	imageId string,
+	_ auth.AuthorizationDto,
+	imageName string,
+	description storage.ImageDescriptionUpdate,
+	format image.Format,
+	originalFile *multipart.FileHeader,
+	croppedFile *multipart.FileHeader,
+) (storage.Image, error) {
+	img := storage.Image{Id: imageId, Name: "my-image-1", Format: storage.PngFormat}
+	if imageName != "" {
+		img.Name = imageName
+	}
+	img.ImageDescription = description.ApplyTo(img.ImageDescription)
+	if originalFile != nil && croppedFile != nil {
+		img.Format = storage.ImageFormat(format)
+		img.Original = "images/" + originalFile.Filename
+	}
+
+	return img, nil
 }
 
 func (h ImagesHandlerMock) DeleteOne(
@@ -77,3 +165,77 @@ func (h ImagesHandlerMock) DeleteOne(
 ) error {
 	return nil
 }
+
+func (h ImagesHandlerMock) RestoreImage(
+	_ context.Context, _ auth.AuthorizationDto, imageId string,
+) (storage.Image, error) {
+	return storage.Image{Id: imageId, Name: "my-image-1"}, nil
+}
+
+func (h ImagesHandlerMock) GetImageVersions(_ context.Context, imageId string) ([]storage.ImageVersion, error) {
+	return []storage.ImageVersion{
+		{ImageId: imageId, Version: 2, Name: "my-image-1", Format: storage.PngFormat},
+		{ImageId: imageId, Version: 1, Name: "my-image-1", Format: storage.JpgFormat},
+	}, nil
+}
+
+func (h ImagesHandlerMock) GetSimilarImages(
+	_ context.Context, _ string, maxDistance int,
+) ([]storage.SimilarImage, error) {
+	similar := []storage.SimilarImage{
+		{Image: storage.Image{Id: "5e9b0f33-1d55-4d6e-9a0c-6f1e43b3c1a2", Name: "my-image-2"}, Distance: 2},
+	}
+	if maxDistance >= 0 && maxDistance < 2 {
+		return []storage.SimilarImage{}, nil
+	}
+	return similar, nil
+}
+
+func (h ImagesHandlerMock) GetMetadata(_ context.Context, _ string) (storage.ImageMetadata, error) {
+	return storage.ImageMetadata{CameraModel: "Canon EOS R5", ExposureTime: "1/250", Iso: 400}, nil
+}
+
+func (h ImagesHandlerMock) GetTranslations(_ context.Context, imageId string) ([]storage.ImageTranslation, error) {
+	return []storage.ImageTranslation{{ImageId: imageId, Locale: "de", AltText: "Ein Flugzeug"}}, nil
+}
+
+func (h ImagesHandlerMock) SetTranslation(
+	_ context.Context, _ auth.AuthorizationDto, imageId, locale, altText, caption string,
+) (storage.ImageTranslation, error) {
+	if locale == "en" {
+		return storage.ImageTranslation{}, exception.InvalidArgument{
+			Reason: "Texts in the default locale en are set on the image itself",
+		}
+	}
+	return storage.ImageTranslation{ImageId: imageId, Locale: locale, AltText: altText, Caption: caption}, nil
+}
+
+// DeleteTranslation knows only the de translation of every image
+func (h ImagesHandlerMock) DeleteTranslation(_ context.Context, _ auth.AuthorizationDto, imageId, locale string) error {
+	if locale != "de" {
+		return exception.NotFound{Msg: fmt.Sprintf("Translation %s of image %s not found", locale, imageId)}
+	}
+	return nil
+}
+
+// RestoreImageVersion knows versions 1 and 2 of every image
+func (h ImagesHandlerMock) RestoreImageVersion(
+	_ context.Context, _ auth.AuthorizationDto, imageId string, version int,
+) (storage.Image, error) {
+	if version > 2 {
+		return storage.Image{}, exception.NotFound{Msg: fmt.Sprintf("Version %d of image %s not found", version, imageId)}
+	}
+	return storage.Image{Id: imageId, Name: "my-image-1", Format: storage.JpgFormat}, nil
+}
+
+func (h ImagesHandlerMock) AttachTag(
+	_ context.Context, _ auth.AuthorizationDto, imageId, tagId string,
+) (storage.Image, error) {
+	return storage.Image{Id: imageId, Tags: storage.TagList{{Id: tagId}}}, nil
+}
+
+func (h ImagesHandlerMock) DetachTag(
+	_ context.Context, _ auth.AuthorizationDto, imageId, _ string,
+) (storage.Image, error) {
+	return storage.Image{Id: imageId, Tags: storage.TagList{}}, nil
+}
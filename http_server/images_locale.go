@@ -0,0 +1,46 @@
+package http_server
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"fmt"
+	"golang.org/x/text/language"
+	"net/http"
+	"strings"
+)
+
+// parseLocalePreferences reads the locales the client prefers, the lang query parameter overrides Accept-Language.
+// An Accept-Language that can not be parsed is ignored, browsers send it on their own.
+func parseLocalePreferences(r *http.Request) ([]language.Tag, error) {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		tag, err := language.Parse(lang)
+		if err != nil {
+			return nil, exception.InvalidArgument{Reason: fmt.Sprintf("Invalid lang %s", lang)}
+		}
+		return []language.Tag{tag}, nil
+	}
+
+	preferences, _, err := language.ParseAcceptLanguage(r.Header.Get("Accept-Language"))
+	if err != nil {
+		return nil, nil
+	}
+	return preferences, nil
+}
+
+// writeContentLanguage lists the locales of the texts of the images, a page may mix locales when some of its images
+// are not translated
+func writeContentLanguage(w http.ResponseWriter, images ...storage.Image) {
+	w.Header().Add("Vary", "Accept-Language")
+
+	var locales []string
+	seen := make(map[string]bool)
+	for _, img := range images {
+		if img.Locale != "" && !seen[img.Locale] {
+			seen[img.Locale] = true
+			locales = append(locales, img.Locale)
+		}
+	}
+	if len(locales) > 0 {
+		w.Header().Set("Content-Language", strings.Join(locales, ", "))
+	}
+}
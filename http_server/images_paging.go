@@ -0,0 +1,64 @@
+package http_server
+
+import (
+	"api/core/exception"
+	"api/http_server/http_util"
+	"api/storage"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const nextCursorHeader = "X-Next-Cursor"
+
+// parseImagePaging supports both page and size for existing clients and the opaque cursor for keyset pagination
+func parseImagePaging(query url.Values) (storage.Paging, error) {
+	page := http_util.ToUint(query.Get("page"))
+	size := http_util.ToUint(query.Get("size"))
+	order := storage.ToOrderOr(query.Get("order"), storage.OrderDescending)
+
+	var cursor *storage.Cursor
+	if value := query.Get("cursor"); value != "" {
+		decoded, err := storage.DecodeCursor(value)
+		if err != nil {
+			return storage.Paging{}, exception.InvalidArgument{Reason: "Invalid cursor"}
+		}
+		cursor = &decoded
+	}
+
+	return storage.NewPaging(page, size, order, cursor), nil
+}
+
+// nextPageUrl keeps all the query parameters of the current request, page is replaced by the cursor
+func nextPageUrl(current *url.URL, cursor string) string {
+	query := current.Query()
+	query.Del("page")
+	query.Set("cursor", cursor)
+
+	next := url.URL{Path: current.Path, RawQuery: query.Encode()}
+
+	return next.String()
+}
+
+// writeNextCursor sets the cursor headers, the Link header follows RFC 8288 like GitHub's API does
+func writeNextCursor(w http.ResponseWriter, r *http.Request, cursor *storage.Cursor) {
+	if cursor == nil {
+		return
+	}
+
+	encoded := cursor.Encode()
+	w.Header().Set(nextCursorHeader, encoded)
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, nextPageUrl(r.URL, encoded)))
+}
+
+func newImagesPageResponse(paging storage.Paging, page storage.ImagePage) http_util.PageResponse {
+	response := http_util.NewPageResponse(page.Images, page.Total, paging.Limit, paging.Offset, page.HasNext)
+	if paging.Cursor != nil {
+		response.Page = 0
+	}
+	if page.NextCursor != nil {
+		response.NextCursor = page.NextCursor.Encode()
+	}
+
+	return response
+}
@@ -0,0 +1,109 @@
+package http_server
+
+import (
+	"api/core/exception"
+	"api/storage"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestParseImagePaging(t *testing.T) {
+	cursor := storage.Cursor{
+		CreatedAt: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+		Id:        "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
+	}
+
+	values := []struct {
+		Name           string
+		Query          url.Values
+		ExpectedOffset int
+		ExpectedCursor *storage.Cursor
+		IsInvalid      bool
+	}{
+		{
+			Name:           "Page and size",
+			Query:          url.Values{"page": {"2"}, "size": {"10"}},
+			ExpectedOffset: 10,
+		},
+		{
+			Name:           "Cursor ignores page",
+			Query:          url.Values{"page": {"2"}, "size": {"10"}, "cursor": {cursor.Encode()}},
+			ExpectedOffset: 0,
+			ExpectedCursor: &cursor,
+		},
+		{
+			Name:      "Invalid cursor",
+			Query:     url.Values{"cursor": {"not-a-cursor"}},
+			IsInvalid: true,
+		},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			paging, err := parseImagePaging(data.Query)
+			if data.IsInvalid {
+				var invalidArgument exception.InvalidArgument
+				if !errors.As(err, &invalidArgument) {
+					t.Fatalf("Expected invalid argument, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if paging.Offset != data.ExpectedOffset {
+				t.Fatalf("Expected offset %d, got %d", data.ExpectedOffset, paging.Offset)
+			}
+			if data.ExpectedCursor == nil && paging.Cursor != nil {
+				t.Fatalf("Expected no cursor, got %+v", paging.Cursor)
+			}
+			if data.ExpectedCursor != nil && (paging.Cursor == nil || *paging.Cursor != *data.ExpectedCursor) {
+				t.Fatalf("Expected cursor %+v, got %+v", data.ExpectedCursor, paging.Cursor)
+			}
+		})
+	}
+}
+
+func TestNextPageUrl(t *testing.T) {
+	current, err := url.Parse("http://localhost/api/v1/images?page=2&size=10&tag=planes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := nextPageUrl(current, "abc")
+	expected := "/api/v1/images?cursor=abc&size=10&tag=planes"
+	if result != expected {
+		t.Fatalf("Expected %s, got %s", expected, result)
+	}
+}
+
+func TestNewImagesPageResponse(t *testing.T) {
+	cursor := &storage.Cursor{
+		CreatedAt: time.Date(2021, 5, 1, 10, 0, 0, 0, time.UTC),
+		Id:        "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
+	}
+	page := storage.ImagePage{Images: storage.ImageList{}, Total: 25, HasNext: true, NextCursor: cursor}
+
+	values := []struct {
+		Name         string
+		Paging       storage.Paging
+		ExpectedPage int
+	}{
+		{Name: "By page", Paging: storage.Paging{Limit: 10, Offset: 10}, ExpectedPage: 2},
+		{Name: "By cursor", Paging: storage.Paging{Limit: 10, Cursor: cursor}, ExpectedPage: 0},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			response := newImagesPageResponse(data.Paging, page)
+			if response.Page != data.ExpectedPage || response.Size != 10 || response.Total != 25 {
+				t.Fatalf("Expected page %d of size 10 and total 25, got %+v", data.ExpectedPage, response)
+			}
+			if !response.HasNext || response.NextCursor != cursor.Encode() || response.ApiVersion != "v1" {
+				t.Fatalf("Expected next page with cursor %s, got %+v", cursor.Encode(), response)
+			}
+		})
+	}
+}
@@ -8,10 +8,15 @@ import (
 	"api/http_server/middleware"
 	"api/image"
 	"api/storage"
+	"encoding/json"
 	"fmt"
 	"github.com/go-chi/chi/v5"
 	"github.com/rs/zerolog"
 	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"unicode/utf8"
 )
 
 const maxBodyLimitBytes = 30 * 1024 * 1024 // 20MB
@@ -19,7 +24,10 @@ const maxBodyLimitBytes = 30 * 1024 * 1024 // 20MB
 func ImagesRouter(handler ImagesHandler, logger *zerolog.Logger, authenticator authenticator.Authenticator) func(chi.Router) {
 	return func(r chi.Router) {
 		r.Get("/", FetchImages(handler, logger))
+		r.Get("/search", SearchImages(handler, logger))
+		r.Get("/by-name/{slug}", FetchImageBySlug(handler, logger))
 		r.Get("/{imageId}", FetchImage(handler, logger))
+		r.Get("/{imageId}/metadata", FetchImageMetadata(handler, logger))
 		r.Post("/",
 			middleware.Authorize(AddImage(handler, logger), authenticator, auth.RoleAdmin),
 		)
@@ -29,53 +37,174 @@ func ImagesRouter(handler ImagesHandler, logger *zerolog.Logger, authenticator a
 		r.Delete("/{imageId}",
 			middleware.Authorize(DeleteOne(handler, logger), authenticator, auth.RoleAdmin),
 		)
+		r.Post("/{imageId}/restore",
+			middleware.Authorize(RestoreImage(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Get("/{imageId}/versions",
+			middleware.Authorize(FetchImageVersions(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Get("/{imageId}/similar",
+			middleware.Authorize(FetchSimilarImages(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Post("/{imageId}/versions/{version}/restore",
+			middleware.Authorize(RestoreImageVersion(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Get("/{imageId}/translations",
+			middleware.Authorize(FetchImageTranslations(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Put("/{imageId}/translations/{locale}",
+			middleware.Authorize(SetImageTranslation(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Delete("/{imageId}/translations/{locale}",
+			middleware.Authorize(DeleteImageTranslation(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Put("/{imageId}/tags/{tagId}",
+			middleware.Authorize(AttachTag(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Delete("/{imageId}/tags/{tagId}",
+			middleware.Authorize(DetachTag(handler, logger), authenticator, auth.RoleAdmin),
+		)
 	}
 }
 
+// FetchImage returns the image with its alt text and caption in the locale of the lang query parameter or the best
+// one of Accept-Language, falling back to the default locale
 func FetchImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		imageId := chi.URLParam(r, "imageId")
-		img, err := handler.GetOne(ctx, imageId)
+		preferences, err := parseLocalePreferences(r)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		img, err := handler.GetOne(ctx, imageId, preferences)
 		if err != nil {
 			http_util.HandleError(logger, w, err)
 			return
 		}
 
+		writeContentLanguage(w, img)
 		http_util.WriteJson(w, http.StatusOK, img)
 	}
 }
 
+// FetchImageMetadata returns the camera, lens and exposure the original file was taken with
+func FetchImageMetadata(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		metadata, err := handler.GetMetadata(r.Context(), chi.URLParam(r, "imageId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, metadata)
+	}
+}
+
+// FetchImageBySlug answers old slugs of renamed images with a permanent redirect to the current one, so that
+// search engines keep the ranking of the image
+func FetchImageBySlug(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		img, isRedirect, err := handler.GetOneBySlug(ctx, chi.URLParam(r, "slug"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		if !isRedirect {
+			http_util.WriteJson(w, http.StatusOK, img)
+			return
+		}
+
+		location := url.URL{Path: path.Join(path.Dir(r.URL.Path), img.Name), RawQuery: r.URL.RawQuery}
+		w.Header().Set("Location", location.String())
+		http_util.WriteJson(w, http.StatusMovedPermanently, img)
+	}
+}
+
 func FetchImages(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		paging, err := parseImagePaging(r.URL.Query())
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		filter, err := parseImageFilter(r.URL.Query())
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		preferences, err := parseLocalePreferences(r)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		page, err := handler.Get(ctx, paging, filter, preferences)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		writeNextCursor(w, r, page.NextCursor)
+		writeContentLanguage(w, page.Images...)
+		http_util.WriteJson(w, http.StatusOK, newImagesPageResponse(paging, page))
+	}
+}
+
+func SearchImages(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		page := http_util.ToUint(r.URL.Query().Get("page"))
 		size := http_util.ToUint(r.URL.Query().Get("size"))
-
-		order := storage.ToOrderOr(r.URL.Query().Get("order"), storage.OrderDescending)
 		limit, offset := storage.PagingToLimitOffset(page, size)
 
-		imageList, err := handler.Get(ctx, limit, offset, order)
+		result, err := handler.Search(ctx, r.URL.Query().Get("q"), limit, offset)
 		if err != nil {
 			http_util.HandleError(logger, w, err)
 			return
 		}
 
-		http_util.WriteJson(w, http.StatusOK, imageList)
+		http_util.WriteJson(
+			w, http.StatusOK, http_util.NewPageResponse(result.Images, result.Total, limit, offset, result.HasNext),
+		)
 	}
 }
 
 type UploadImageDto struct {
-	Name   string
-	Format image.Format
+	Name        string
+	Format      image.Format
+	Description storage.ImageDescription
 }
 
-func (dto UploadImageDto) validate() error {
-	if len(dto.Name) < 5 || len(dto.Name) > 200 {
+// Image names are counted in characters, multibyte letters are transliterated into the slug later on
+const (
+	minImageNameLength = 5
+	maxImageNameLength = 200
+)
+
+func validateImageName(name string) error {
+	if length := utf8.RuneCountInString(name); length < minImageNameLength || length > maxImageNameLength {
 		return exception.InvalidArgument{
-			Reason: "Name should be between 5 and 250 characters",
+			Reason: fmt.Sprintf(
+				"Name should be between %d and %d characters", minImageNameLength, maxImageNameLength,
+			),
 		}
 	}
+	return nil
+}
+
+func (dto UploadImageDto) validate() error {
+	if err := validateImageName(dto.Name); err != nil {
+		return err
+	}
 
 	if !dto.Format.IsSupported() {
 		return exception.InvalidArgument{
@@ -120,13 +249,20 @@ func AddImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 		data := &UploadImageDto{}
 		data.Name = r.PostFormValue("name")
 		data.Format = image.Format(r.PostFormValue("format"))
+		data.Description = storage.ImageDescription{
+			AltText: r.PostFormValue("altText"),
+			Title:   r.PostFormValue("title"),
+			Caption: r.PostFormValue("caption"),
+			Credit:  r.PostFormValue("credit"),
+			License: r.PostFormValue("license"),
+		}
 		if err = data.validate(); err != nil {
 			http_util.WriteBadRequestJson(w, err)
 			return
 		}
 
 		ctx := r.Context()
-		authorization, err := auth.ExtractAuthorizationDto(ctx, string(middleware.UserAuthDtoKey))
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
 		if err != nil {
 			http_util.HandleError(logger, w, err)
 			return
@@ -136,6 +272,7 @@ func AddImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 			ctx,
 			authorization,
 			data.Name,
+			data.Description,
 			data.Format,
 			originalFileHeader,
 			croppedFileHeader,
@@ -149,6 +286,36 @@ func AddImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 	}
 }
 
+// UpdateImageDto is valid with any of a name, both files with a format and description fields
+type UpdateImageDto struct {
+	Name        string
+	Format      image.Format
+	HasFiles    bool
+	Description storage.ImageDescriptionUpdate
+}
+
+func (dto UpdateImageDto) validate() error {
+	if dto.Name == "" && !dto.HasFiles && dto.Description.IsEmpty() {
+		return exception.InvalidArgument{
+			Reason: "Expected a name, files, description fields or any of them together",
+		}
+	}
+
+	if dto.Name != "" {
+		if err := validateImageName(dto.Name); err != nil {
+			return err
+		}
+	}
+
+	if dto.HasFiles && !dto.Format.IsSupported() {
+		return exception.InvalidArgument{
+			Reason: fmt.Sprintf("Unsupported format %s", dto.Format),
+		}
+	}
+
+	return nil
+}
+
 func UpdateImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		err := r.ParseMultipartForm(maxBodyLimitBytes)
@@ -161,44 +328,43 @@ func UpdateImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc
 			return
 		}
 
-		_, originalFileHeader, err := r.FormFile("originalFile")
-		if err != nil {
-			http_util.WriteJson(
-				w,
-				http.StatusBadRequest,
-				http_util.NewFailureResponse("missing originalFile"),
-			)
-			return
-		}
-		_, croppedFileHeader, err := r.FormFile("croppedFile")
-		if err != nil {
+		_, originalFileHeader, originalErr := r.FormFile("originalFile")
+		_, croppedFileHeader, croppedErr := r.FormFile("croppedFile")
+		if (originalErr == nil) != (croppedErr == nil) {
 			http_util.WriteJson(
 				w,
 				http.StatusBadRequest,
-				http_util.NewFailureResponse("missing croppedFile"),
+				http_util.NewFailureResponse("originalFile and croppedFile must be uploaded together"),
 			)
 			return
 		}
 
-		data := &UploadImageDto{}
+		data := &UpdateImageDto{}
 		data.Name = r.PostFormValue("name")
 		data.Format = image.Format(r.PostFormValue("format"))
+		data.HasFiles = originalErr == nil && croppedErr == nil
+		data.Description = parseDescriptionUpdate(r.PostForm)
 		if err = data.validate(); err != nil {
 			http_util.WriteBadRequestJson(w, err)
 			return
 		}
+		if !data.HasFiles {
+			data.Format = ""
+		}
 
 		ctx := r.Context()
-		authorization, err := auth.ExtractAuthorizationDto(ctx, string(middleware.UserAuthDtoKey))
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
 		if err != nil {
 			http_util.HandleError(logger, w, err)
 			return
 		}
 
-		img, err := handler.UploadAndResize(
+		img, err := handler.Update(
 			ctx,
+			chi.URLParam(r, "imageId"),
 			authorization,
 			data.Name,
+			data.Description,
 			data.Format,
 			originalFileHeader,
 			croppedFileHeader,
@@ -208,7 +374,26 @@ func UpdateImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc
 			return
 		}
 
-		http_util.WriteJson(w, http.StatusCreated, img)
+		http_util.WriteJson(w, http.StatusOK, img)
+	}
+}
+
+// parseDescriptionUpdate takes the description fields that were sent, a field sent empty clears it
+func parseDescriptionUpdate(form url.Values) storage.ImageDescriptionUpdate {
+	field := func(name string) *string {
+		if _, ok := form[name]; !ok {
+			return nil
+		}
+		value := form.Get(name)
+		return &value
+	}
+
+	return storage.ImageDescriptionUpdate{
+		AltText: field("altText"),
+		Title:   field("title"),
+		Caption: field("caption"),
+		Credit:  field("credit"),
+		License: field("license"),
 	}
 }
 
@@ -216,7 +401,7 @@ func DeleteOne(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 		imageId := chi.URLParam(r, "imageId")
-		authDto, err := auth.ExtractAuthorizationDto(ctx, string(middleware.UserAuthDtoKey))
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
 		if err != nil {
 			http_util.HandleError(logger, w, err)
 			return
@@ -230,3 +415,191 @@ func DeleteOne(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
 		http_util.WriteJson(w, http.StatusNoContent, nil)
 	}
 }
+
+// RestoreImage brings a deleted image back from the trash
+func RestoreImage(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		img, err := handler.RestoreImage(ctx, authDto, chi.URLParam(r, "imageId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, img)
+	}
+}
+
+func FetchImageVersions(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		versions, err := handler.GetImageVersions(r.Context(), chi.URLParam(r, "imageId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, versions)
+	}
+}
+
+// FetchSimilarImages lists the images that look like the image, closest first. The distance query parameter is the
+// maximum number of differing bits of their perceptual hashes, the configured one when missing.
+func FetchSimilarImages(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		maxDistance := -1
+		if distance := r.URL.Query().Get("distance"); distance != "" {
+			var err error
+			maxDistance, err = strconv.Atoi(distance)
+			if err != nil || maxDistance < 0 || maxDistance > 64 {
+				http_util.WriteBadRequestJson(w, exception.InvalidArgument{Reason: "Distance must be between 0 and 64"})
+				return
+			}
+		}
+
+		similar, err := handler.GetSimilarImages(r.Context(), chi.URLParam(r, "imageId"), maxDistance)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, similar)
+	}
+}
+
+// RestoreImageVersion brings back the files of a previous version of the image
+func RestoreImageVersion(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, err := strconv.Atoi(chi.URLParam(r, "version"))
+		if err != nil || version < 1 {
+			http_util.WriteBadRequestJson(w, exception.InvalidArgument{Reason: "Version must be a positive number"})
+			return
+		}
+
+		ctx := r.Context()
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		img, err := handler.RestoreImageVersion(ctx, authDto, chi.URLParam(r, "imageId"), version)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, img)
+	}
+}
+
+// FetchImageTranslations returns the alt texts and captions of the image in the locales other than the default one
+func FetchImageTranslations(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		translations, err := handler.GetTranslations(r.Context(), chi.URLParam(r, "imageId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, translations)
+	}
+}
+
+const maxTranslationBodyLimitBytes = 8 * 1024
+
+type ImageTranslationDto struct {
+	AltText string `json:"altText"`
+	Caption string `json:"caption"`
+}
+
+// SetImageTranslation creates or overwrites the translation of the image in the locale of the path
+func SetImageTranslation(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var data ImageTranslationDto
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTranslationBodyLimitBytes)).Decode(&data)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, exception.InvalidArgument{Reason: "failed parsing json body"})
+			return
+		}
+
+		ctx := r.Context()
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		translation, err := handler.SetTranslation(
+			ctx, authDto, chi.URLParam(r, "imageId"), chi.URLParam(r, "locale"), data.AltText, data.Caption,
+		)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, translation)
+	}
+}
+
+func DeleteImageTranslation(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		err = handler.DeleteTranslation(ctx, authDto, chi.URLParam(r, "imageId"), chi.URLParam(r, "locale"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusNoContent, nil)
+	}
+}
+
+func AttachTag(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		img, err := handler.AttachTag(ctx, authDto, chi.URLParam(r, "imageId"), chi.URLParam(r, "tagId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, img)
+	}
+}
+
+func DetachTag(handler ImagesHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		authDto, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		img, err := handler.DetachTag(ctx, authDto, chi.URLParam(r, "imageId"), chi.URLParam(r, "tagId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, img)
+	}
+}
@@ -4,19 +4,34 @@ import (
 	"api/http_server/authenticator"
 	"api/logger"
 	"api/storage"
+	"bytes"
 	"context"
 	"encoding/json"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"reflect"
+	"strings"
 	"testing"
 )
 
+// imagesPageResponse is http_util.PageResponse with typed items
+type imagesPageResponse struct {
+	ApiVersion string            `json:"apiVersion"`
+	Items      storage.ImageList `json:"items"`
+	Total      int               `json:"total"`
+	Page       int               `json:"page"`
+	Size       int               `json:"size"`
+	HasNext    bool              `json:"hasNext"`
+	NextCursor string            `json:"nextCursor"`
+}
+
 func TestFetchImage(t *testing.T) {
 	config := Config{}
 	handlers := Handlers{
 		ImagesHandler: ImagesHandlerMock{},
+		TagsHandler:   TagsHandlerMock{},
 		Authenticator: authenticator.Mock{},
 	}
 	ctx := context.Background()
@@ -51,10 +66,24 @@ func TestFetchImage(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	var images storage.ImageList
-	if err = json.Unmarshal(data, &images); err != nil {
+	var response imagesPageResponse
+	if err = json.Unmarshal(data, &response); err != nil {
 		t.Fatal(err)
 	}
+	images := response.Items
+
+	expectedResponse := imagesPageResponse{
+		ApiVersion: "v1",
+		Total:      21,
+		Page:       2,
+		Size:       10,
+		HasNext:    true,
+		NextCursor: ImagesHandlerNextCursorMock.Encode(),
+	}
+	response.Items = nil
+	if !reflect.DeepEqual(response, expectedResponse) {
+		t.Fatalf("Expected %+v, got %+v", expectedResponse, response)
+	}
 
 	expectedImages := storage.ImageList{
 		{
@@ -84,6 +113,7 @@ func TestFetchImage(t *testing.T) {
 			},
 			CreatedAt: nil,
 			UpdatedAt: nil,
+			Locale:    "en",
 		},
 	}
 
@@ -92,6 +122,485 @@ func TestFetchImage(t *testing.T) {
 	}
 }
 
+func TestFetchImages_NextCursor(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/images?size=10&page=2&order=ASC", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	nextCursor := ImagesHandlerNextCursorMock.Encode()
+	if res.Header.Get(nextCursorHeader) != nextCursor {
+		t.Fatalf("Expected next cursor %s, got %s", nextCursor, res.Header.Get(nextCursorHeader))
+	}
+	expectedLink := `</api/v1/images?cursor=` + nextCursor + `&order=ASC&size=10>; rel="next"`
+	if res.Header.Get("Link") != expectedLink {
+		t.Fatalf("Expected link %s, got %s", expectedLink, res.Header.Get("Link"))
+	}
+
+	res = doRequest(t, http.MethodGet, testServer.URL+expectedLink[1:strings.Index(expectedLink, ">")], "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+	if res.Header.Get("Link") != "" {
+		t.Fatalf("Expected no link on the last page, got %s", res.Header.Get("Link"))
+	}
+}
+
+func TestFetchImages_InvalidCursor(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/images?cursor=invalid", "")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code 400, got %d", res.StatusCode)
+	}
+}
+
+func newMultipartBody(t *testing.T, fields map[string]string, files ...string) (*bytes.Buffer, string) {
+	body := new(bytes.Buffer)
+	writer := multipart.NewWriter(body)
+
+	for _, field := range files {
+		part, err := writer.CreateFormFile(field, field+".png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err = part.Write([]byte("file")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return body, writer.FormDataContentType()
+}
+
+func TestUpdateImage(t *testing.T) {
+	testServer := newTestServer(t)
+	imageId := "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	data := []struct {
+		testName           string
+		fields             map[string]string
+		files              []string
+		expectedStatusCode int
+		expectedName       string
+		expectedFormat     storage.ImageFormat
+	}{
+		{
+			testName:           "Name only",
+			fields:             map[string]string{"name": "my new plane"},
+			expectedStatusCode: http.StatusOK,
+			expectedName:       "my new plane",
+			expectedFormat:     storage.PngFormat,
+		},
+		{
+			testName:           "Files only",
+			fields:             map[string]string{"format": "jpg"},
+			files:              []string{"originalFile", "croppedFile"},
+			expectedStatusCode: http.StatusOK,
+			expectedName:       "my-image-1",
+			expectedFormat:     storage.JpgFormat,
+		},
+		{
+			testName:           "Files and name",
+			fields:             map[string]string{"name": "my new plane", "format": "webp"},
+			files:              []string{"originalFile", "croppedFile"},
+			expectedStatusCode: http.StatusOK,
+			expectedName:       "my new plane",
+			expectedFormat:     storage.WebpFormat,
+		},
+		{
+			testName:           "Description only",
+			fields:             map[string]string{"altText": "A fighter plane over white cliffs", "license": ""},
+			expectedStatusCode: http.StatusOK,
+			expectedName:       "my-image-1",
+			expectedFormat:     storage.PngFormat,
+		},
+		{
+			testName:           "Nothing to update",
+			fields:             map[string]string{"format": "jpg"},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			testName:           "Single file",
+			fields:             map[string]string{"format": "jpg"},
+			files:              []string{"originalFile"},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			testName:           "Files without format",
+			files:              []string{"originalFile", "croppedFile"},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			testName:           "Short name",
+			fields:             map[string]string{"name": "abc"},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+		{
+			testName:           "Longest name in multibyte letters",
+			fields:             map[string]string{"name": strings.Repeat("ž", 200)},
+			expectedStatusCode: http.StatusOK,
+			expectedName:       strings.Repeat("ž", 200),
+			expectedFormat:     storage.PngFormat,
+		},
+		{
+			testName:           "Long name",
+			fields:             map[string]string{"name": strings.Repeat("a", 201)},
+			expectedStatusCode: http.StatusBadRequest,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			body, contentType := newMultipartBody(t, d.fields, d.files...)
+			req, err := http.NewRequest(http.MethodPatch, testServer.URL+"/api/v1/images/"+imageId, body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Authorization", authHeaderMock)
+			req.Header.Set("Content-Type", contentType)
+
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			if res.StatusCode != d.expectedStatusCode {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatusCode, res.StatusCode)
+			}
+			if d.expectedStatusCode != http.StatusOK {
+				return
+			}
+
+			var img storage.Image
+			if err = json.NewDecoder(res.Body).Decode(&img); err != nil {
+				t.Fatal(err)
+			}
+			if img.Id != imageId || img.Name != d.expectedName || img.Format != d.expectedFormat {
+				t.Fatalf(
+					"Expected image %s named %s of format %s, got %+v", imageId, d.expectedName, d.expectedFormat, img,
+				)
+			}
+			if img.AltText != d.fields["altText"] {
+				t.Fatalf("Expected alt text %q, got %q", d.fields["altText"], img.AltText)
+			}
+		})
+	}
+}
+
+func TestFetchImageBySlug(t *testing.T) {
+	testServer := newTestServer(t)
+	client := &http.Client{
+		CheckRedirect: func(_ *http.Request, _ []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+
+	data := []struct {
+		testName           string
+		slug               string
+		expectedStatusCode int
+		expectedLocation   string
+	}{
+		{testName: "Current slug", slug: "my-new-plane", expectedStatusCode: http.StatusOK},
+		{
+			testName:           "Old slug",
+			slug:               "my-plane",
+			expectedStatusCode: http.StatusMovedPermanently,
+			expectedLocation:   "/api/v1/images/by-name/my-new-plane",
+		},
+		{testName: "Unknown slug", slug: "my-ship", expectedStatusCode: http.StatusNotFound},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			res, err := client.Get(testServer.URL + "/api/v1/images/by-name/" + d.slug)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = res.Body.Close()
+			}()
+			if res.StatusCode != d.expectedStatusCode {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatusCode, res.StatusCode)
+			}
+			if res.Header.Get("Location") != d.expectedLocation {
+				t.Fatalf("Expected location %s, got %s", d.expectedLocation, res.Header.Get("Location"))
+			}
+		})
+	}
+}
+
+func TestSearchImages(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/images/search?q=world-war&size=10", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var response imagesPageResponse
+	if err := json.NewDecoder(res.Body).Decode(&response); err != nil {
+		t.Fatal(err)
+	}
+	if len(response.Items) != 1 || response.Items[0].Name != "world-war" {
+		t.Fatalf("Expected single image named world-war, got %+v", response.Items)
+	}
+	if response.Total != 1 || response.Page != 1 || response.Size != 10 || response.HasNext {
+		t.Fatalf("Expected single page of 1 image, got %+v", response)
+	}
+}
+
+func TestSearchImages_MissingQuery(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/images/search", "")
+	if res.StatusCode != http.StatusBadRequest {
+		t.Fatalf("Expected status code 400, got %d", res.StatusCode)
+	}
+}
+
+func TestFetchImageVersions(t *testing.T) {
+	testServer := newTestServer(t)
+	imageId := "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/images/"+imageId+"/versions", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var versions []storage.ImageVersion
+	if err := json.NewDecoder(res.Body).Decode(&versions); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 || versions[0].ImageId != imageId {
+		t.Fatalf("Expected versions 2 and 1 of %s, got %+v", imageId, versions)
+	}
+}
+
+func TestRestoreImageVersion(t *testing.T) {
+	testServer := newTestServer(t)
+	imagesUrl := testServer.URL + "/api/v1/images/3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	data := []struct {
+		name           string
+		version        string
+		expectedStatus int
+	}{
+		{name: "Restored", version: "1", expectedStatus: http.StatusOK},
+		{name: "Missing version", version: "3", expectedStatus: http.StatusNotFound},
+		{name: "Invalid version", version: "first", expectedStatus: http.StatusBadRequest},
+		{name: "Zero version", version: "0", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, http.MethodPost, imagesUrl+"/versions/"+d.version+"/restore", "")
+			if res.StatusCode != d.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestFetchSimilarImages(t *testing.T) {
+	testServer := newTestServer(t)
+	imagesUrl := testServer.URL + "/api/v1/images/3c47d736-6c4e-4a1c-a04b-3744cc30b263/similar"
+
+	data := []struct {
+		name            string
+		query           string
+		expectedStatus  int
+		expectedSimilar int
+	}{
+		{name: "Configured distance", query: "", expectedStatus: http.StatusOK, expectedSimilar: 1},
+		{name: "Closer distance", query: "?distance=1", expectedStatus: http.StatusOK, expectedSimilar: 0},
+		{name: "Invalid distance", query: "?distance=close", expectedStatus: http.StatusBadRequest},
+		{name: "Distance above 64", query: "?distance=65", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, http.MethodGet, imagesUrl+d.query, "")
+			if res.StatusCode != d.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatus, res.StatusCode)
+			}
+			if res.StatusCode != http.StatusOK {
+				return
+			}
+
+			var similar []storage.SimilarImage
+			if err := json.NewDecoder(res.Body).Decode(&similar); err != nil {
+				t.Fatal(err)
+			}
+			if len(similar) != d.expectedSimilar {
+				t.Fatalf("Expected %d similar images, got %+v", d.expectedSimilar, similar)
+			}
+		})
+	}
+}
+
+func TestFetchImageMetadata(t *testing.T) {
+	testServer := newTestServer(t)
+
+	metadataUrl := testServer.URL + "/api/v1/images/3c47d736-6c4e-4a1c-a04b-3744cc30b263/metadata"
+
+	res := doRequest(t, http.MethodGet, metadataUrl, "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code %d, got %d", http.StatusOK, res.StatusCode)
+	}
+
+	var metadata storage.ImageMetadata
+	if err := json.NewDecoder(res.Body).Decode(&metadata); err != nil {
+		t.Fatal(err)
+	}
+	if metadata.CameraModel != "Canon EOS R5" || metadata.ExposureTime != "1/250" || metadata.Iso != 400 {
+		t.Fatalf("Expected the metadata of the image, got %+v", metadata)
+	}
+}
+
+func TestFetchImage_Locale(t *testing.T) {
+	testServer := newTestServer(t)
+	imageUrl := testServer.URL + "/api/v1/images/3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	data := []struct {
+		name             string
+		query            string
+		acceptLanguage   string
+		expectedStatus   int
+		expectedLanguage string
+	}{
+		{name: "Default locale", expectedStatus: http.StatusOK, expectedLanguage: "en"},
+		{
+			name:             "Accept-Language",
+			acceptLanguage:   "de-AT, de;q=0.9, en;q=0.5",
+			expectedStatus:   http.StatusOK,
+			expectedLanguage: "de",
+		},
+		{
+			name:             "Lang overrides Accept-Language",
+			query:            "?lang=en",
+			acceptLanguage:   "de",
+			expectedStatus:   http.StatusOK,
+			expectedLanguage: "en",
+		},
+		{
+			name:             "Invalid Accept-Language is ignored",
+			acceptLanguage:   "de;q=high",
+			expectedStatus:   http.StatusOK,
+			expectedLanguage: "en",
+		},
+		{name: "Invalid lang", query: "?lang=12345", expectedStatus: http.StatusBadRequest},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			req, err := http.NewRequest(http.MethodGet, imageUrl+d.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if d.acceptLanguage != "" {
+				req.Header.Set("Accept-Language", d.acceptLanguage)
+			}
+			res, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				_ = res.Body.Close()
+			}()
+
+			if res.StatusCode != d.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatus, res.StatusCode)
+			}
+			if res.Header.Get("Content-Language") != d.expectedLanguage {
+				t.Fatalf("Expected Content-Language %s, got %s", d.expectedLanguage, res.Header.Get("Content-Language"))
+			}
+		})
+	}
+}
+
+func TestFetchImages_Locale(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/images?size=10&page=2&order=ASC&lang=de-CH", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+	if res.Header.Get("Content-Language") != "de" {
+		t.Fatalf("Expected Content-Language de, got %s", res.Header.Get("Content-Language"))
+	}
+}
+
+func TestImageTranslations(t *testing.T) {
+	testServer := newTestServer(t)
+	translationsUrl := testServer.URL + "/api/v1/images/3c47d736-6c4e-4a1c-a04b-3744cc30b263/translations"
+
+	res := doRequest(t, http.MethodGet, translationsUrl, "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+	var translations []storage.ImageTranslation
+	if err := json.NewDecoder(res.Body).Decode(&translations); err != nil {
+		t.Fatal(err)
+	}
+	if len(translations) != 1 || translations[0].Locale != "de" {
+		t.Fatalf("Expected the de translation, got %+v", translations)
+	}
+
+	data := []struct {
+		name           string
+		method         string
+		locale         string
+		body           string
+		expectedStatus int
+	}{
+		{
+			name:           "Set",
+			method:         http.MethodPut,
+			locale:         "fr",
+			body:           `{"altText": "Un avion", "caption": "Au-dessus des falaises"}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Set default locale",
+			method:         http.MethodPut,
+			locale:         "en",
+			body:           `{}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid body",
+			method:         http.MethodPut,
+			locale:         "fr",
+			body:           `{"altText":`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{name: "Delete", method: http.MethodDelete, locale: "de", expectedStatus: http.StatusNoContent},
+		{name: "Delete missing", method: http.MethodDelete, locale: "fr", expectedStatus: http.StatusNotFound},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, d.method, translationsUrl+"/"+d.locale, d.body)
+			if res.StatusCode != d.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
+
 // TODO: Create router endpoint test and move the rest to the core application test
 //func (s *MySuite) TestUploadFile() {
 //	repoMock := new(storage.ImageRepoMock)
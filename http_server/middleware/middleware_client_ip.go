@@ -0,0 +1,18 @@
+package middleware
+
+import (
+	"api/pkg/clientip"
+	"net"
+	"net/http"
+)
+
+// ClientIp stores the IP of the client in the request context for the audit log, it is the same address
+// hlog.RemoteAddrHandler logs
+func ClientIp(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+			r = r.WithContext(clientip.NewContext(r.Context(), host))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
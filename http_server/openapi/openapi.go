@@ -2,6 +2,7 @@ package openapi
 
 import (
 	"api/http_server/http_util"
+	"api/storage"
 	"embed"
 	"fmt"
 	"github.com/getkin/kin-openapi/openapi3"
@@ -50,6 +51,17 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 		return nil, err
 	}
 
+	licenseSchemaRef := &openapi3.SchemaRef{
+		Value: &openapi3.Schema{
+			Type: "string",
+			Enum: []interface{}{
+				"", "all-rights-reserved", "public-domain", "cc0", "cc-by", "cc-by-sa", "cc-by-nd", "cc-by-nc",
+				"cc-by-nc-sa", "cc-by-nc-nd",
+			},
+			Description: "License code of the image, empty when unknown",
+		},
+	}
+
 	swagger.Components.Schemas = openapi3.Schemas{
 		"Image": &openapi3.SchemaRef{
 			Value: &openapi3.Schema{
@@ -66,11 +78,419 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 					"croppedFile": {
 						Value: &openapi3.Schema{Type: "string", Format: "binary"},
 					},
+					"altText": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							MaxLength:   openapi3.Uint64Ptr(250),
+							Description: "Read out by screen readers in place of the image",
+						},
+					},
+					"title": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(200), Example: "Supermarine Spitfire"},
+					},
+					"caption": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(1000)},
+					},
+					"credit": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(200), Example: "Jane Doe"},
+					},
+					"license": licenseSchemaRef,
+					"tags": {
+						Value: &openapi3.Schema{
+							Type:  "array",
+							Items: &openapi3.SchemaRef{Ref: "#/components/schemas/Tag"},
+						},
+					},
+					"deletedAt": {
+						Value: &openapi3.Schema{
+							Type: "string", Format: "date-time", Description: "Only set on images in the trash",
+						},
+					},
+					"perceptualHash": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Example:     "f0e4c2d7c8b0a1e3",
+							Description: "dHash of the cropped file in hex, images that look alike differ in few bits",
+						},
+					},
+					"blurHash": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Example:     "LFTI:j;$fQ;$|co1fQo1fQfQfQfQ",
+							Description: "BlurHash of the cropped file to show while the sizes load, missing until backfilled",
+						},
+					},
+					"dominantColor": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Example:     "#3a6ea5",
+							Pattern:     "^#[0-9a-f]{6}$",
+							Description: "Dominant color of the cropped file, missing until backfilled",
+						},
+					},
+					"metadata": {
+						Ref: "#/components/schemas/ImageMetadata",
+					},
+					"locale": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Example:     "de",
+							Description: "Locale of the alt text and the caption, only set on fetched images",
+						},
+					},
+					"duplicates": {
+						Value: &openapi3.Schema{
+							Type:        "array",
+							Description: "Only set on an uploaded image that looks like images already stored",
+							Items:       &openapi3.SchemaRef{Ref: "#/components/schemas/SimilarImage"},
+						},
+					},
 				},
 				Required: []string{"name", "format", "originalFile", "croppedFile"},
 			},
 		},
+		"Tag": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"id": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"value": {
+						Value: &openapi3.Schema{Type: "string", Example: "planes"},
+					},
+					"createdAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time"},
+					},
+					"updatedAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time", Nullable: true},
+					},
+					"authorId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid", Nullable: true},
+					},
+				},
+			},
+		},
+		"TagUsage": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				AllOf: openapi3.SchemaRefs{
+					{Ref: "#/components/schemas/Tag"},
+					{
+						Value: &openapi3.Schema{
+							Type: "object",
+							Properties: map[string]*openapi3.SchemaRef{
+								"count": {
+									Value: &openapi3.Schema{Type: "integer", Description: "Number of images using the tag"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"TagValue": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"value": {
+						Value: &openapi3.Schema{Type: "string", Example: "planes"},
+					},
+				},
+				Required: []string{"value"},
+			},
+		},
+		"AuditEvent": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"id": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"actorId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid", Description: "User making the change"},
+					},
+					"action": {
+						Value: &openapi3.Schema{
+							Type: "string",
+							Enum: []interface{}{
+								"image.created", "image.updated", "image.deleted", "image.restored", "image.translated",
+								"tag.created", "tag.updated", "tag.deleted",
+								"webhook.created", "webhook.updated", "webhook.deleted",
+								"user.role_changed",
+							},
+						},
+					},
+					"targetId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid", Description: "Changed resource"},
+					},
+					"before": {
+						Value: &openapi3.Schema{Type: "object", Nullable: true, Description: "State before the change"},
+					},
+					"after": {
+						Value: &openapi3.Schema{Type: "object", Nullable: true, Description: "State after the change"},
+					},
+					"requestId": {
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"clientIp": {
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"createdAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time"},
+					},
+				},
+			},
+		},
+		"SimilarImage": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				AllOf: openapi3.SchemaRefs{
+					{Ref: "#/components/schemas/Image"},
+					{
+						Value: &openapi3.Schema{
+							Type: "object",
+							Properties: map[string]*openapi3.SchemaRef{
+								"distance": {
+									Value: &openapi3.Schema{
+										Type:        "integer",
+										Example:     3,
+										Description: "Number of bits in which the perceptual hashes differ, 0 for the same picture",
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		"ErrResponse": errResponseSchemaRef,
+		"ImageVersion": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type:        "object",
+				Description: "Previous files of an image, replaced by an update",
+				Properties: map[string]*openapi3.SchemaRef{
+					"imageId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"version": {
+						Value: &openapi3.Schema{Type: "integer", Example: 1},
+					},
+					"name": {
+						Value: &openapi3.Schema{Type: "string", Example: "my-plane"},
+					},
+					"format": {
+						Value: &openapi3.Schema{Type: "string", Enum: []interface{}{"jpg", "png", "webp"}},
+					},
+					"original": {
+						Value: &openapi3.Schema{Type: "string", Example: "images/my-plane.png"},
+					},
+					"domain": {
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"path": {
+						Value: &openapi3.Schema{Type: "string", Example: "images"},
+					},
+					"sizes": {
+						Value: &openapi3.Schema{Type: "object"},
+					},
+					"createdAt": {
+						Value: &openapi3.Schema{
+							Type: "string", Format: "date-time", Description: "When the files were replaced",
+						},
+					},
+					"metadata": {
+						Ref: "#/components/schemas/ImageMetadata",
+					},
+				},
+			},
+		},
+		"ImageMetadata": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Description: "Sanitized EXIF of the original file, only set on the image when it is uploaded or its " +
+					"files are replaced, tags missing from the file are left out",
+				Properties: map[string]*openapi3.SchemaRef{
+					"cameraMake": {
+						Value: &openapi3.Schema{Type: "string", Example: "Canon"},
+					},
+					"cameraModel": {
+						Value: &openapi3.Schema{Type: "string", Example: "Canon EOS R5"},
+					},
+					"lensModel": {
+						Value: &openapi3.Schema{Type: "string", Example: "RF24-105mm F4 L IS USM"},
+					},
+					"exposureTime": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Example:     "1/250",
+							Description: "Exposure in seconds, as a fraction below one second",
+						},
+					},
+					"fNumber": {
+						Value: &openapi3.Schema{Type: "number", Example: 2.8},
+					},
+					"focalLength": {
+						Value: &openapi3.Schema{Type: "number", Example: 50, Description: "Focal length in millimeters"},
+					},
+					"iso": {
+						Value: &openapi3.Schema{Type: "integer", Example: 400},
+					},
+					"capturedAt": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Format:      "date-time",
+							Description: "Capture date in UTC, taken as the local time of the camera without an offset",
+						},
+					},
+					"orientation": {
+						Value: openapi3.NewIntegerSchema().WithMin(1).WithMax(8),
+					},
+					"gps": {
+						Value: &openapi3.Schema{
+							Type:        "object",
+							Description: "Where the photo was taken, only kept when IMAGES_METADATA_GPS is enabled",
+							Properties: map[string]*openapi3.SchemaRef{
+								"latitude": {
+									Value: &openapi3.Schema{Type: "number", Example: 51.5072},
+								},
+								"longitude": {
+									Value: &openapi3.Schema{Type: "number", Example: -0.1276},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		"Webhook": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"id": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"url": {
+						Value: &openapi3.Schema{Type: "string", Example: "https://example.com/hooks/images"},
+					},
+					"events": {
+						Value: &openapi3.Schema{
+							Type:        "array",
+							Description: "Subscribed event types, all of them when empty",
+							Items:       &openapi3.SchemaRef{Ref: "#/components/schemas/WebhookEventType"},
+						},
+					},
+					"createdAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time"},
+					},
+					"updatedAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time", Nullable: true},
+					},
+				},
+			},
+		},
+		"WebhookEventType": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "string",
+				Enum: []interface{}{"image.created", "image.updated", "image.deleted", "image.restored"},
+			},
+		},
+		"WebhookInput": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"url": {
+						Value: &openapi3.Schema{Type: "string", Example: "https://example.com/hooks/images"},
+					},
+					"secret": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							Description: "Key of the X-Webhook-Signature HMAC, required on create and kept when empty on update",
+						},
+					},
+					"events": {
+						Value: &openapi3.Schema{
+							Type:  "array",
+							Items: &openapi3.SchemaRef{Ref: "#/components/schemas/WebhookEventType"},
+						},
+					},
+				},
+				Required: []string{"url"},
+			},
+		},
+		"WebhookDelivery": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"id": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"subscriptionId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"eventId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"eventType": {
+						Ref: "#/components/schemas/WebhookEventType",
+					},
+					"payload": {
+						Value: &openapi3.Schema{Type: "object", Description: "Delivered body"},
+					},
+					"statusCode": {
+						Value: &openapi3.Schema{Type: "integer", Description: "Status of the last attempt, 0 without a response"},
+					},
+					"success": {
+						Value: &openapi3.Schema{Type: "boolean"},
+					},
+					"attempts": {
+						Value: &openapi3.Schema{Type: "integer"},
+					},
+					"error": {
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"createdAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time"},
+					},
+				},
+			},
+		},
+		"ImageTranslation": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"imageId": {
+						Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+					},
+					"locale": {
+						Value: &openapi3.Schema{Type: "string", Example: "pt-BR", Description: "BCP 47 language tag"},
+					},
+					"altText": {
+						Value: &openapi3.Schema{Type: "string", Example: "Um avião"},
+					},
+					"caption": {
+						Value: &openapi3.Schema{Type: "string"},
+					},
+					"createdAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time"},
+					},
+					"updatedAt": {
+						Value: &openapi3.Schema{Type: "string", Format: "date-time"},
+					},
+				},
+			},
+		},
+		"ImageTranslationInput": &openapi3.SchemaRef{
+			Value: &openapi3.Schema{
+				Type: "object",
+				Properties: map[string]*openapi3.SchemaRef{
+					"altText": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(250), Example: "Um avião"},
+					},
+					"caption": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(1000)},
+					},
+				},
+			},
+		},
 		"CreateImage": &openapi3.SchemaRef{
 			Value: &openapi3.Schema{
 				Type: "object",
@@ -87,6 +507,23 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 					"croppedFile": {
 						Value: &openapi3.Schema{Type: "string", Format: "binary"},
 					},
+					"altText": {
+						Value: &openapi3.Schema{
+							Type:        "string",
+							MaxLength:   openapi3.Uint64Ptr(250),
+							Description: "Read out by screen readers in place of the image",
+						},
+					},
+					"title": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(200), Example: "Supermarine Spitfire"},
+					},
+					"caption": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(1000)},
+					},
+					"credit": {
+						Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(200), Example: "Jane Doe"},
+					},
+					"license": licenseSchemaRef,
 				},
 				Required: []string{"name", "format", "originalFile", "croppedFile"},
 			},
@@ -96,7 +533,7 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 	swagger.Components.RequestBodies = openapi3.RequestBodies{
 		"UploadNewImage": &openapi3.RequestBodyRef{
 			Value: openapi3.NewRequestBody().
-				WithDescription("Create a new image. Ensure that the cropped image is in one of the allowed aspect ratios: `1:1` `3:2` `4:3` `5:8` `16:9`, otherwise it will fail.").
+				WithDescription("Create a new image. Both files must be images in the given format within the allowed dimensions, by default 100 to 10000 pixels wide and high, and the cropped image must be in one of the allowed aspect ratios, by default `1:1` `3:2` `4:3` `5:8` `16:9`. Otherwise the request is rejected before uploading with 400. Uploads that look like a stored image are rejected with 400 as well or listed in `duplicates` of the response, depending on the configuration.").
 				WithRequired(true).
 				WithContent(openapi3.NewContentWithFormDataSchemaRef(
 					&openapi3.SchemaRef{
@@ -104,10 +541,27 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 					},
 				)),
 		},
+		"TagValue": &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().
+				WithDescription("Tag value between 2 and 50 characters. It is stored lowercased with collapsed whitespace.").
+				WithRequired(true).
+				WithJSONSchemaRef(&openapi3.SchemaRef{
+					Ref: "#/components/schemas/TagValue",
+				}),
+		},
+		"WebhookInput": &openapi3.RequestBodyRef{
+			Value: openapi3.NewRequestBody().
+				WithDescription("Absolute http or https url, secret between 16 and 255 characters and the subscribed events").
+				WithRequired(true).
+				WithJSONSchemaRef(&openapi3.SchemaRef{
+					Ref: "#/components/schemas/WebhookInput",
+				}),
+		},
 		"UpdateImage": &openapi3.RequestBodyRef{
 			Value: openapi3.NewRequestBody().
 				WithDescription(
-					"If you upload images, you will need to provide cropped, original and format. Name can be standalone",
+					"If you upload images, you will need to provide cropped, original and format. Name can be standalone, " +
+						"as can the texts describing the image. A text sent empty is cleared, one left out is kept",
 				).
 				WithRequired(true).
 				WithContent(openapi3.NewContentWithFormDataSchemaRef(&openapi3.SchemaRef{
@@ -126,6 +580,23 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 							"croppedFile": {
 								Value: &openapi3.Schema{Type: "string", Format: "binary"},
 							},
+							"altText": {
+								Value: &openapi3.Schema{
+									Type:        "string",
+									MaxLength:   openapi3.Uint64Ptr(250),
+									Description: "Read out by screen readers in place of the image",
+								},
+							},
+							"title": {
+								Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(200), Example: "Supermarine Spitfire"},
+							},
+							"caption": {
+								Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(1000)},
+							},
+							"credit": {
+								Value: &openapi3.Schema{Type: "string", MaxLength: openapi3.Uint64Ptr(200), Example: "Jane Doe"},
+							},
+							"license": licenseSchemaRef,
 						},
 					},
 				})),
@@ -146,88 +617,367 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 		},
 		"ImagesResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("Images").
+				WithDescription("Page of images").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Value: &openapi3.Schema{
+								Type: "object",
+								Properties: map[string]*openapi3.SchemaRef{
+									"apiVersion": {
+										Value: &openapi3.Schema{Type: "string", Example: http_util.ApiVersion},
+									},
+									"items": {
+										Value: &openapi3.Schema{
+											Type:  "array",
+											Items: &openapi3.SchemaRef{Ref: "#/components/schemas/Image"},
+										},
+									},
+									"total": {
+										Value: &openapi3.Schema{
+											Type:        "integer",
+											Description: "Number of all images matching the query",
+										},
+									},
+									"page": {
+										Value: &openapi3.Schema{
+											Type:        "integer",
+											Description: "Current page number, 0 when paging by cursor",
+										},
+									},
+									"size": {
+										Value: &openapi3.Schema{Type: "integer", Description: "Maximum number of items"},
+									},
+									"hasNext": {
+										Value: &openapi3.Schema{Type: "boolean"},
+									},
+									"nextCursor": {
+										Value: &openapi3.Schema{
+											Type:        "string",
+											Description: "Cursor of the next page, only present when there is one",
+										},
+									},
+								},
+								Required: []string{"apiVersion", "items", "total", "page", "size", "hasNext"},
+							},
+						},
+					),
+				),
+		},
+		"TagResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Tag").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/Tag",
+						},
+					),
+				),
+		},
+		"TagsResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Tags with the number of images using them, most used first").
 				WithContent(
 					openapi3.NewContentWithJSONSchemaRef(
 						&openapi3.SchemaRef{
 							Value: &openapi3.Schema{
 								Type: "array",
 								Items: &openapi3.SchemaRef{
-									Ref: "#/components/schemas/Image",
+									Ref: "#/components/schemas/TagUsage",
 								},
 							},
 						},
 					),
 				),
 		},
-		"EmptyResponse": &openapi3.ResponseRef{
+		"WebhookResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("Ok empty response"),
+				WithDescription("Webhook subscription").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/Webhook",
+						},
+					),
+				),
 		},
-		"BadRequestResponse": &openapi3.ResponseRef{
+		"ImageVersionsResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("Bad request").
+				WithDescription("Previous versions of the image, newest first").
 				WithContent(
 					openapi3.NewContentWithJSONSchemaRef(
 						&openapi3.SchemaRef{
-							Ref: "#/components/schemas/ErrResponse",
+							Value: &openapi3.Schema{
+								Type: "array",
+								Items: &openapi3.SchemaRef{
+									Ref: "#/components/schemas/ImageVersion",
+								},
+							},
 						},
 					),
 				),
 		},
-		"ForbiddenResponse": &openapi3.ResponseRef{
+		"ImageMetadataResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("Forbidden").
+				WithDescription("Metadata read from the original file of the image").
 				WithContent(
 					openapi3.NewContentWithJSONSchemaRef(
 						&openapi3.SchemaRef{
-							Ref: "#/components/schemas/ErrResponse",
+							Ref: "#/components/schemas/ImageMetadata",
 						},
 					),
 				),
 		},
-		"UnauthorizedResponse": &openapi3.ResponseRef{
+		"SimilarImagesResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("Unauthorized").
+				WithDescription("Images that look like the image, closest first").
 				WithContent(
 					openapi3.NewContentWithJSONSchemaRef(
 						&openapi3.SchemaRef{
-							Ref: "#/components/schemas/ErrResponse",
+							Value: &openapi3.Schema{
+								Type: "array",
+								Items: &openapi3.SchemaRef{
+									Ref: "#/components/schemas/SimilarImage",
+								},
+							},
 						},
 					),
 				),
 		},
-		"NotFoundResponse": &openapi3.ResponseRef{
+		"WebhooksResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("Resource not found").
+				WithDescription("Webhook subscriptions").
 				WithContent(
 					openapi3.NewContentWithJSONSchemaRef(
 						&openapi3.SchemaRef{
-							Ref: "#/components/schemas/ErrResponse",
+							Value: &openapi3.Schema{
+								Type: "array",
+								Items: &openapi3.SchemaRef{
+									Ref: "#/components/schemas/Webhook",
+								},
+							},
 						},
 					),
 				),
 		},
-		"ServerErrorResponse": &openapi3.ResponseRef{
+		"WebhookDeliveryResponse": &openapi3.ResponseRef{
 			Value: openapi3.NewResponse().
-				WithDescription("ServerErrorResponse").
+				WithDescription("Webhook delivery").
 				WithContent(
 					openapi3.NewContentWithJSONSchemaRef(
 						&openapi3.SchemaRef{
-							Ref: "#/components/schemas/ErrResponse",
+							Ref: "#/components/schemas/WebhookDelivery",
 						},
 					),
 				),
 		},
-	}
-
+		"WebhookDeliveriesResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Page of webhook deliveries, newest first").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Value: &openapi3.Schema{
+								Type: "object",
+								Properties: map[string]*openapi3.SchemaRef{
+									"apiVersion": {
+										Value: &openapi3.Schema{Type: "string", Example: http_util.ApiVersion},
+									},
+									"items": {
+										Value: &openapi3.Schema{
+											Type:  "array",
+											Items: &openapi3.SchemaRef{Ref: "#/components/schemas/WebhookDelivery"},
+										},
+									},
+									"total": {
+										Value: &openapi3.Schema{Type: "integer"},
+									},
+									"page": {
+										Value: &openapi3.Schema{Type: "integer"},
+									},
+									"size": {
+										Value: &openapi3.Schema{Type: "integer"},
+									},
+									"hasNext": {
+										Value: &openapi3.Schema{Type: "boolean"},
+									},
+								},
+								Required: []string{"apiVersion", "items", "total", "page", "size", "hasNext"},
+							},
+						},
+					),
+				),
+		},
+		"AuditEventsResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Page of audit events, newest first").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Value: &openapi3.Schema{
+								Type: "object",
+								Properties: map[string]*openapi3.SchemaRef{
+									"apiVersion": {
+										Value: &openapi3.Schema{Type: "string", Example: http_util.ApiVersion},
+									},
+									"items": {
+										Value: &openapi3.Schema{
+											Type:  "array",
+											Items: &openapi3.SchemaRef{Ref: "#/components/schemas/AuditEvent"},
+										},
+									},
+									"total": {
+										Value: &openapi3.Schema{Type: "integer"},
+									},
+									"page": {
+										Value: &openapi3.Schema{Type: "integer"},
+									},
+									"size": {
+										Value: &openapi3.Schema{Type: "integer"},
+									},
+									"hasNext": {
+										Value: &openapi3.Schema{Type: "boolean"},
+									},
+								},
+								Required: []string{"apiVersion", "items", "total", "page", "size", "hasNext"},
+							},
+						},
+					),
+				),
+		},
+		"EmptyResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Ok empty response"),
+		},
+		"BadRequestResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Bad request").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/ErrResponse",
+						},
+					),
+				),
+		},
+		"ForbiddenResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Forbidden").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/ErrResponse",
+						},
+					),
+				),
+		},
+		"UnauthorizedResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Unauthorized").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/ErrResponse",
+						},
+					),
+				),
+		},
+		"NotFoundResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("Resource not found").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/ErrResponse",
+						},
+					),
+				),
+		},
+		"ServerErrorResponse": &openapi3.ResponseRef{
+			Value: openapi3.NewResponse().
+				WithDescription("ServerErrorResponse").
+				WithContent(
+					openapi3.NewContentWithJSONSchemaRef(
+						&openapi3.SchemaRef{
+							Ref: "#/components/schemas/ErrResponse",
+						},
+					),
+				),
+		},
+	}
+
+	swagger.Components.Responses["ImagesResponse"].Value.Headers = openapi3.Headers{
+		"Link": &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: "Relative url of the next page with `rel=\"next\"`, only present when there is one",
+					Schema:      &openapi3.SchemaRef{Value: openapi3.NewStringSchema()},
+				},
+			},
+		},
+		"X-Next-Cursor": &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: "Opaque cursor of the next page, only present when there is one",
+					Schema:      &openapi3.SchemaRef{Value: openapi3.NewStringSchema()},
+				},
+			},
+		},
+		"Content-Language": &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: "Locales of the alt texts and captions of the page, images that are not translated " +
+						"to the preferred locale are in the default one. Only present on the list of images",
+					Schema: &openapi3.SchemaRef{Value: openapi3.NewStringSchema()},
+				},
+			},
+		},
+	}
+
+	swagger.Components.Responses["LocalizedImageResponse"] = &openapi3.ResponseRef{
+		Value: openapi3.NewResponse().
+			WithDescription("Image with its alt text and caption in the locale that matches the preferences best").
+			WithContent(openapi3.NewContentWithJSONSchemaRef(&openapi3.SchemaRef{Ref: "#/components/schemas/Image"})),
+	}
+	swagger.Components.Responses["LocalizedImageResponse"].Value.Headers = openapi3.Headers{
+		"Content-Language": &openapi3.HeaderRef{
+			Value: &openapi3.Header{
+				Parameter: openapi3.Parameter{
+					Description: "Locale of the alt text and the caption",
+					Schema:      &openapi3.SchemaRef{Value: openapi3.NewStringSchema()},
+				},
+			},
+		},
+	}
+
+	// localeParameters pick the locale of the alt texts and captions, the default locale is the fallback
+	localeParameters := openapi3.Parameters{
+		{
+			Value: &openapi3.Parameter{
+				Name:        "lang",
+				In:          "query",
+				Description: "Preferred locale as a BCP 47 language tag, overrides Accept-Language",
+				Schema:      &openapi3.SchemaRef{Value: openapi3.NewStringSchema()},
+			},
+		},
+		{
+			Value: &openapi3.Parameter{
+				Name:        "Accept-Language",
+				In:          "header",
+				Description: "Preferred locales, ignored when it can not be parsed",
+				Schema:      &openapi3.SchemaRef{Value: openapi3.NewStringSchema()},
+			},
+		},
+	}
+
 	swagger.Paths = openapi3.Paths{
 		"/api/v1/images": &openapi3.PathItem{
 			Summary: "Images aka pictures",
 			Get: &openapi3.Operation{
 				OperationID: "GetImages",
 				Tags:        []string{"Images"},
-				Description: "Fetch list of images",
+				Description: "Fetch list of images, all filters are combined",
 				Parameters: openapi3.Parameters{
 					{
 						Value: &openapi3.Parameter{
@@ -235,32 +985,124 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 							In:   "query",
 							Description: fmt.Sprintf(
 								"Number of results, default is %d and maximum is %d",
-								20, 100,
+								storage.PaginationLimitDefault, storage.PaginationLimitMax,
 							),
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewIntegerSchema().WithMin(1),
+							},
 						},
 					},
 					{
 						Value: &openapi3.Parameter{
 							Name:        "page",
 							In:          "query",
-							Description: "Page number for pagination, minimum 1",
+							Description: "Page number for pagination, minimum 1, ignored when cursor is present",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewIntegerSchema().WithMin(1),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name: "cursor",
+							In:   "query",
+							Description: "Opaque cursor from the `X-Next-Cursor` or `Link` header of the previous page, " +
+								"unlike page it does not skip or repeat images inserted while paging",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema(),
+							},
 						},
 					},
 					{
 						Value: &openapi3.Parameter{
 							Name:        "order",
 							In:          "query",
-							Description: "Specify descending or ascending order",
+							Description: "Specify descending or ascending order by creation date, default is descending",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema().WithEnum("DESC", "ASC"),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "tag",
+							In:          "query",
+							Description: "Only images with the tag value, case insensitive",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema(),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "format",
+							In:          "query",
+							Description: "Only images of the format",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema().WithEnum("jpg", "png", "webp"),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "authorId",
+							In:          "query",
+							Description: "Only images uploaded by the author",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewUUIDSchema(),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "createdAfter",
+							In:          "query",
+							Description: "Only images created at or after the RFC 3339 date-time or date, like `2021-05-01`",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema(),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "createdBefore",
+							In:          "query",
+							Description: "Only images created before the RFC 3339 date-time or date, like `2021-06-01`",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema(),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "capturedAfter",
+							In:          "query",
+							Description: "Only images captured at or after the RFC 3339 date-time or date, like `2021-05-01`",
+							Schema: &openapi3.SchemaRef{
+								Value: openapi3.NewStringSchema(),
+							},
+						},
+					},
+					{
+						Value: &openapi3.Parameter{
+							Name:        "capturedBefore",
+							In:          "query",
+							Description: "Only images captured before the RFC 3339 date-time or date, like `2021-06-01`",
 							Schema: &openapi3.SchemaRef{
-								Value: openapi3.NewSchema().WithEnum("First", "Second"),
+								Value: openapi3.NewStringSchema(),
 							},
 						},
 					},
+					localeParameters[0],
+					localeParameters[1],
 				},
 				Responses: openapi3.Responses{
 					"200": &openapi3.ResponseRef{
 						Ref: "#/components/responses/ImagesResponse",
 					},
+					"400": &openapi3.ResponseRef{
+						Ref: "#/components/responses/BadRequestResponse",
+					},
 					"500": &openapi3.ResponseRef{
 						Ref: "#/components/responses/ServerErrorResponse",
 					},
@@ -302,7 +1144,7 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 			Get: &openapi3.Operation{
 				OperationID: "GetImage",
 				Tags:        []string{"Images"},
-				Description: "Fetch image info",
+				Description: "Fetch image info with its alt text and caption in the preferred locale",
 				Parameters: openapi3.Parameters{
 					{
 						Value: &openapi3.Parameter{
@@ -317,10 +1159,15 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 							},
 						},
 					},
+					localeParameters[0],
+					localeParameters[1],
 				},
 				Responses: openapi3.Responses{
 					"200": &openapi3.ResponseRef{
-						Ref: "#/components/responses/ImageResponse",
+						Ref: "#/components/responses/LocalizedImageResponse",
+					},
+					"400": &openapi3.ResponseRef{
+						Ref: "#/components/responses/BadRequestResponse",
 					},
 					"404": &openapi3.ResponseRef{
 						Ref: "#/components/responses/NotFoundResponse",
@@ -333,7 +1180,8 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 			Delete: &openapi3.Operation{
 				OperationID: "DeleteImage",
 				Tags:        []string{"Images"},
-				Description: "Delete image and invalidate CDN images",
+				Description: "Move image to the trash, its CDN images are deleted and invalidated once the trash " +
+					"retention period passes",
 				Security: &openapi3.SecurityRequirements{
 					openapi3.SecurityRequirement{
 						"oauth2": []string{},
@@ -369,7 +1217,7 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 			Patch: &openapi3.Operation{
 				OperationID: "Update image",
 				Tags:        []string{"Images"},
-				Description: "Update existing image or change the name. Note that this will invalidate the cashed image on edge locations.",
+				Description: "Update existing image or change the name. Note that this will invalidate the cashed image on edge locations. Replaced files are kept as a version of the image.",
 				Security: &openapi3.SecurityRequirements{
 					openapi3.SecurityRequirement{
 						"oauth2": []string{},
@@ -417,6 +1265,1123 @@ func newOpenApi3(config OpenApi3Config) (*openapi3.T, error) {
 		},
 	}
 
+	tagIdParameter := &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        "tagId",
+			In:          "path",
+			Description: "Id of tag",
+			Required:    true,
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type:   "string",
+					Format: "uuid",
+				},
+			},
+		},
+	}
+	adminSecurity := &openapi3.SecurityRequirements{
+		openapi3.SecurityRequirement{
+			"oauth2": []string{},
+		},
+	}
+
+	swagger.Paths["/api/v1/tags"] = &openapi3.PathItem{
+		Summary: "Tags",
+		Get: &openapi3.Operation{
+			OperationID: "GetTags",
+			Tags:        []string{"Tags"},
+			Description: "Fetch all tags with their usage counts, useful for tag clouds",
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/TagsResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Post: &openapi3.Operation{
+			OperationID: "CreateTag",
+			Tags:        []string{"Tags"},
+			Description: "Create a new tag, requires admin authorization",
+			Security:    adminSecurity,
+			RequestBody: &openapi3.RequestBodyRef{
+				Ref: "#/components/requestBodies/TagValue",
+			},
+			Responses: openapi3.Responses{
+				"201": &openapi3.ResponseRef{
+					Ref: "#/components/responses/TagResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+	swagger.Paths["/api/v1/tags/{tagId}"] = &openapi3.PathItem{
+		Summary: "Tag",
+		Patch: &openapi3.Operation{
+			OperationID: "RenameTag",
+			Tags:        []string{"Tags"},
+			Description: "Rename the tag, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters:  openapi3.Parameters{tagIdParameter},
+			RequestBody: &openapi3.RequestBodyRef{
+				Ref: "#/components/requestBodies/TagValue",
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/TagResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Delete: &openapi3.Operation{
+			OperationID: "DeleteTag",
+			Tags:        []string{"Tags"},
+			Description: "Delete the tag and detach it from all images, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters:  openapi3.Parameters{tagIdParameter},
+			Responses: openapi3.Responses{
+				"204": &openapi3.ResponseRef{
+					Ref: "#/components/responses/EmptyResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+	swagger.Paths["/api/v1/images/{id}/tags/{tagId}"] = &openapi3.PathItem{
+		Summary: "Image tag",
+		Put: &openapi3.Operation{
+			OperationID: "AttachTag",
+			Tags:        []string{"Images", "Tags"},
+			Description: "Attach the tag to the image, attaching it twice has no effect",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+						},
+					},
+				},
+				tagIdParameter,
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Delete: &openapi3.Operation{
+			OperationID: "DetachTag",
+			Tags:        []string{"Images", "Tags"},
+			Description: "Detach the tag from the image",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: &openapi3.Schema{Type: "string", Format: "uuid"},
+						},
+					},
+				},
+				tagIdParameter,
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/search"] = &openapi3.PathItem{
+		Summary: "Image search",
+		Get: &openapi3.Operation{
+			OperationID: "SearchImages",
+			Tags:        []string{"Images"},
+			Description: "Full-text search of images by name, title, alt text, caption and credit, every term is " +
+				"matched as a prefix and results are ordered by relevance, matches in the name and title first",
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "q",
+						In:          "query",
+						Description: "Search text, free text or an SEO name like `world-war-plane`",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewStringSchema().WithMinLength(1).WithMaxLength(200),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name: "size",
+						In:   "query",
+						Description: fmt.Sprintf(
+							"Number of results, default is %d and maximum is %d",
+							storage.PaginationLimitDefault, storage.PaginationLimitMax,
+						),
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "page",
+						In:          "query",
+						Description: "Page number for pagination, minimum 1",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImagesResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/by-name/{slug}"] = &openapi3.PathItem{
+		Summary: "Image by name",
+		Get: &openapi3.Operation{
+			OperationID: "GetImageBySlug",
+			Tags:        []string{"Images"},
+			Description: "Fetch image info by its SEO name, previous names of renamed images " +
+				"are permanently redirected to the current one",
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "slug",
+						In:          "path",
+						Description: "Current or previous SEO name of the image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewStringSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageResponse",
+				},
+				"301": &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().
+						WithDescription("Previous name, the Location header points to the current one").
+						WithContent(
+							openapi3.NewContentWithJSONSchemaRef(
+								&openapi3.SchemaRef{Ref: "#/components/schemas/Image"},
+							),
+						),
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	webhookIdParameter := &openapi3.ParameterRef{
+		Value: &openapi3.Parameter{
+			Name:        "webhookId",
+			In:          "path",
+			Description: "Id of webhook subscription",
+			Required:    true,
+			Schema: &openapi3.SchemaRef{
+				Value: &openapi3.Schema{
+					Type:   "string",
+					Format: "uuid",
+				},
+			},
+		},
+	}
+	swagger.Paths["/api/v1/webhooks"] = &openapi3.PathItem{
+		Summary: "Webhooks",
+		Get: &openapi3.Operation{
+			OperationID: "GetWebhooks",
+			Tags:        []string{"Webhooks"},
+			Description: "Fetch the webhook subscriptions, requires admin authorization",
+			Security:    adminSecurity,
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/WebhooksResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Post: &openapi3.Operation{
+			OperationID: "CreateWebhook",
+			Tags:        []string{"Webhooks"},
+			Description: "Subscribe a url to image events. Deliveries are posted as JSON and signed in the X-Webhook-Signature header with sha256=HMAC-SHA256 of `<X-Webhook-Timestamp>.<body>`, requires admin authorization",
+			Security:    adminSecurity,
+			RequestBody: &openapi3.RequestBodyRef{
+				Ref: "#/components/requestBodies/WebhookInput",
+			},
+			Responses: openapi3.Responses{
+				"201": &openapi3.ResponseRef{
+					Ref: "#/components/responses/WebhookResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+	swagger.Paths["/api/v1/webhooks/{webhookId}"] = &openapi3.PathItem{
+		Summary: "Webhook",
+		Get: &openapi3.Operation{
+			OperationID: "GetWebhook",
+			Tags:        []string{"Webhooks"},
+			Description: "Fetch the webhook subscription, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters:  openapi3.Parameters{webhookIdParameter},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/WebhookResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Put: &openapi3.Operation{
+			OperationID: "UpdateWebhook",
+			Tags:        []string{"Webhooks"},
+			Description: "Replace the url and events of the webhook subscription, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters:  openapi3.Parameters{webhookIdParameter},
+			RequestBody: &openapi3.RequestBodyRef{
+				Ref: "#/components/requestBodies/WebhookInput",
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/WebhookResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Delete: &openapi3.Operation{
+			OperationID: "DeleteWebhook",
+			Tags:        []string{"Webhooks"},
+			Description: "Delete the webhook subscription with its deliveries, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters:  openapi3.Parameters{webhookIdParameter},
+			Responses: openapi3.Responses{
+				"204": &openapi3.ResponseRef{
+					Ref: "#/components/responses/EmptyResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+	swagger.Paths["/api/v1/webhooks/deliveries"] = &openapi3.PathItem{
+		Summary: "Webhook deliveries",
+		Get: &openapi3.Operation{
+			OperationID: "GetWebhookDeliveries",
+			Tags:        []string{"Webhooks"},
+			Description: "Fetch the log of webhook deliveries, newest first, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "webhookId",
+						In:          "query",
+						Description: "Only deliveries to the webhook subscription",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "eventId",
+						In:          "query",
+						Description: "Only deliveries of the event",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "success",
+						In:          "query",
+						Description: "Only successful or failed deliveries",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewBoolSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "page",
+						In:          "query",
+						Description: "Page number for pagination, minimum 1",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name: "size",
+						In:   "query",
+						Description: fmt.Sprintf(
+							"Number of results, default is %d and maximum is %d",
+							storage.PaginationLimitDefault, storage.PaginationLimitMax,
+						),
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/WebhookDeliveriesResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+	swagger.Paths["/api/v1/webhooks/deliveries/{deliveryId}/replay"] = &openapi3.PathItem{
+		Summary: "Webhook delivery replay",
+		Post: &openapi3.Operation{
+			OperationID: "ReplayWebhookDelivery",
+			Tags:        []string{"Webhooks"},
+			Description: "Deliver the event of the delivery again to its webhook subscription and record it as a new delivery, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "deliveryId",
+						In:          "path",
+						Description: "Id of webhook delivery",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/WebhookDeliveryResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/audit"] = &openapi3.PathItem{
+		Summary: "Audit log",
+		Get: &openapi3.Operation{
+			OperationID: "GetAuditEvents",
+			Tags:        []string{"Audit"},
+			Description: "Fetch the log of the changes made by admins, newest first, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "actorId",
+						In:          "query",
+						Description: "Only changes made by the user",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "action",
+						In:          "query",
+						Description: "Only changes of the action, like `image.deleted`",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewStringSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "targetId",
+						In:          "query",
+						Description: "Only changes of the resource",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "page",
+						In:          "query",
+						Description: "Page number for pagination, minimum 1",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name: "size",
+						In:   "query",
+						Description: fmt.Sprintf(
+							"Number of results, default is %d and maximum is %d",
+							storage.PaginationLimitDefault, storage.PaginationLimitMax,
+						),
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/AuditEventsResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/users/{userId}/role"] = &openapi3.PathItem{
+		Summary: "User role",
+		Put: &openapi3.Operation{
+			OperationID: "SetUserRole",
+			Tags:        []string{"Users"},
+			Description: "Give the user the role, the change is recorded in the audit log. Admins can not change " +
+				"their own role. Requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "userId",
+						In:          "path",
+						Description: "Id of user",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+			},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: openapi3.NewRequestBody().
+					WithRequired(true).
+					WithJSONSchema(&openapi3.Schema{
+						Type: "object",
+						Properties: map[string]*openapi3.SchemaRef{
+							"role": {
+								Value: &openapi3.Schema{
+									Type:        "string",
+									Enum:        []interface{}{"Administrators", ""},
+									Description: "Empty for no role",
+								},
+							},
+						},
+						Required: []string{"role"},
+					}),
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().
+						WithDescription("User with the role").
+						WithJSONSchema(&openapi3.Schema{
+							Type: "object",
+							Properties: map[string]*openapi3.SchemaRef{
+								"id":    {Value: &openapi3.Schema{Type: "string", Format: "uuid"}},
+								"email": {Value: &openapi3.Schema{Type: "string"}},
+								"role":  {Value: &openapi3.Schema{Type: "string"}},
+							},
+						}),
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/restore"] = &openapi3.PathItem{
+		Summary: "Image restore",
+		Post: &openapi3.Operation{
+			OperationID: "RestoreImage",
+			Tags:        []string{"Images"},
+			Description: "Restore a deleted image from the trash, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"403": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ForbiddenResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/versions"] = &openapi3.PathItem{
+		Summary: "Image versions",
+		Get: &openapi3.Operation{
+			OperationID: "GetImageVersions",
+			Tags:        []string{"Images"},
+			Description: "Fetch the previous files of the image, kept each time an update replaced them, " +
+				"requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageVersionsResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/translations"] = &openapi3.PathItem{
+		Summary: "Image translations",
+		Get: &openapi3.Operation{
+			OperationID: "GetImageTranslations",
+			Tags:        []string{"Images"},
+			Description: "Fetch the alt texts and captions of the image in the locales other than the default one, " +
+				"requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().
+						WithDescription("Translations ordered by locale").
+						WithContent(openapi3.NewContentWithJSONSchemaRef(&openapi3.SchemaRef{
+							Value: &openapi3.Schema{
+								Type:  "array",
+								Items: &openapi3.SchemaRef{Ref: "#/components/schemas/ImageTranslation"},
+							},
+						})),
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/translations/{locale}"] = &openapi3.PathItem{
+		Summary: "Image translation",
+		Put: &openapi3.Operation{
+			OperationID: "SetImageTranslation",
+			Tags:        []string{"Images"},
+			Description: "Create or overwrite the alt text and the caption of the image in the locale, texts in the " +
+				"default locale are set on the image itself. Requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "locale",
+						In:          "path",
+						Description: "BCP 47 language tag other than the default locale, like `de` or `pt-BR`",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewStringSchema(),
+						},
+					},
+				},
+			},
+			RequestBody: &openapi3.RequestBodyRef{
+				Value: openapi3.NewRequestBody().
+					WithRequired(true).
+					WithJSONSchemaRef(&openapi3.SchemaRef{Ref: "#/components/schemas/ImageTranslationInput"}),
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().
+						WithDescription("Stored translation").
+						WithContent(openapi3.NewContentWithJSONSchemaRef(
+							&openapi3.SchemaRef{Ref: "#/components/schemas/ImageTranslation"},
+						)),
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+		Delete: &openapi3.Operation{
+			OperationID: "DeleteImageTranslation",
+			Tags:        []string{"Images"},
+			Description: "Delete the translation of the image in the locale, requires admin authorization",
+			Security:    adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "locale",
+						In:          "path",
+						Description: "BCP 47 language tag other than the default locale, like `de` or `pt-BR`",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewStringSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"204": &openapi3.ResponseRef{
+					Value: openapi3.NewResponse().WithDescription("Translation deleted"),
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/metadata"] = &openapi3.PathItem{
+		Summary: "Image metadata",
+		Get: &openapi3.Operation{
+			OperationID: "GetImageMetadata",
+			Tags:        []string{"Images"},
+			Description: "Fetch the camera, lens, exposure and capture date read from the EXIF of the original file, " +
+				"empty for files without one",
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageMetadataResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/similar"] = &openapi3.PathItem{
+		Summary: "Similar images",
+		Get: &openapi3.Operation{
+			OperationID: "GetSimilarImages",
+			Tags:        []string{"Images"},
+			Description: "Fetch up to 20 images whose perceptual hash is within the distance of the one of the image, " +
+				"requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "distance",
+						In:          "query",
+						Description: "Maximum number of differing bits, defaults to the configured duplicate distance",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(0).WithMax(64),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/SimilarImagesResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/images/{id}/versions/{version}/restore"] = &openapi3.PathItem{
+		Summary: "Image version restore",
+		Post: &openapi3.Operation{
+			OperationID: "RestoreImageVersion",
+			Tags:        []string{"Images"},
+			Description: "Restore the files and name of a previous version of the image, the replaced files are " +
+				"kept as the next version, requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "id",
+						In:          "path",
+						Description: "Id of image",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewUUIDSchema(),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name:        "version",
+						In:          "path",
+						Description: "Number of the version",
+						Required:    true,
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImageResponse",
+				},
+				"400": &openapi3.ResponseRef{
+					Ref: "#/components/responses/BadRequestResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"404": &openapi3.ResponseRef{
+					Ref: "#/components/responses/NotFoundResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
+	swagger.Paths["/api/v1/trash"] = &openapi3.PathItem{
+		Summary: "Trash",
+		Get: &openapi3.Operation{
+			OperationID: "GetTrash",
+			Tags:        []string{"Images"},
+			Description: "Fetch the deleted images that can still be restored, most recently deleted first, " +
+				"requires admin authorization",
+			Security: adminSecurity,
+			Parameters: openapi3.Parameters{
+				{
+					Value: &openapi3.Parameter{
+						Name:        "page",
+						In:          "query",
+						Description: "Page number for pagination, minimum 1",
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+				{
+					Value: &openapi3.Parameter{
+						Name: "size",
+						In:   "query",
+						Description: fmt.Sprintf(
+							"Number of results, default is %d and maximum is %d",
+							storage.PaginationLimitDefault, storage.PaginationLimitMax,
+						),
+						Schema: &openapi3.SchemaRef{
+							Value: openapi3.NewIntegerSchema().WithMin(1),
+						},
+					},
+				},
+			},
+			Responses: openapi3.Responses{
+				"200": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ImagesResponse",
+				},
+				"401": &openapi3.ResponseRef{
+					Ref: "#/components/responses/UnauthorizedResponse",
+				},
+				"500": &openapi3.ResponseRef{
+					Ref: "#/components/responses/ServerErrorResponse",
+				},
+			},
+		},
+	}
+
 	swagger.Components.SecuritySchemes = openapi3.SecuritySchemes{
 		"oauth2": &openapi3.SecuritySchemeRef{
 			Value: &openapi3.SecurityScheme{
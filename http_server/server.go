@@ -24,8 +24,13 @@ type Server struct {
 }
 
 type Handlers struct {
-	ImagesHandler ImagesHandler
-	Authenticator authenticator.Authenticator
+	ImagesHandler   ImagesHandler
+	TagsHandler     TagsHandler
+	WebhooksHandler WebhooksHandler
+	AuditHandler    AuditHandler
+	TrashHandler    TrashHandler
+	UsersHandler    UsersHandler
+	Authenticator   authenticator.Authenticator
 }
 
 func NewServer(logger *zerolog.Logger, config Config, handlers Handlers) (*Server, error) {
@@ -60,6 +65,7 @@ func NewServer(logger *zerolog.Logger, config Config, handlers Handlers) (*Serve
 			Msg("")
 	}))
 	c = c.Append(hlog.RemoteAddrHandler("ip"))
+	c = c.Append(coremiddleware.ClientIp)
 	c = c.Append(hlog.UserAgentHandler("user_agent"))
 	c = c.Append(hlog.RefererHandler("referer"))
 	c = c.Append(hlog.RequestIDHandler("req_id", "Request-Id"))
@@ -87,7 +93,7 @@ func NewServer(logger *zerolog.Logger, config Config, handlers Handlers) (*Serve
 		AllowedOrigins:   config.CorsAllowOrigins,
 		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
-		ExposedHeaders:   []string{"Link"},
+		ExposedHeaders:   []string{"Link", nextCursorHeader},
 		AllowCredentials: false,
 		MaxAge:           300, // Maximum value not ignored by any of major browsers
 	}))
@@ -114,6 +120,31 @@ func NewServer(logger *zerolog.Logger, config Config, handlers Handlers) (*Serve
 		logger,
 		handlers.Authenticator,
 	))
+	r.Route("/api/v1/tags", TagsRouter(
+		handlers.TagsHandler,
+		logger,
+		handlers.Authenticator,
+	))
+	r.Route("/api/v1/webhooks", WebhooksRouter(
+		handlers.WebhooksHandler,
+		logger,
+		handlers.Authenticator,
+	))
+	r.Route("/api/v1/audit", AuditRouter(
+		handlers.AuditHandler,
+		logger,
+		handlers.Authenticator,
+	))
+	r.Route("/api/v1/trash", TrashRouter(
+		handlers.TrashHandler,
+		logger,
+		handlers.Authenticator,
+	))
+	r.Route("/api/v1/users", UsersRouter(
+		handlers.UsersHandler,
+		logger,
+		handlers.Authenticator,
+	))
 
 	httpServer := &http.Server{
 		Addr:              port,
@@ -0,0 +1,18 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/storage"
+	"context"
+)
+
+type TagsHandler interface {
+	GetTags(ctx context.Context) (storage.TagUsageList, error)
+	CreateTag(
+		ctx context.Context, authorization auth.AuthorizationDto, value string,
+	) (storage.Tag, error)
+	RenameTag(
+		ctx context.Context, authorization auth.AuthorizationDto, tagId, value string,
+	) (storage.Tag, error)
+	DeleteTag(ctx context.Context, authorization auth.AuthorizationDto, tagId string) error
+}
@@ -0,0 +1,33 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/storage"
+	"context"
+)
+
+type TagsHandlerMock struct {
+}
+
+func (h TagsHandlerMock) GetTags(_ context.Context) (storage.TagUsageList, error) {
+	return storage.TagUsageList{
+		{Tag: storage.Tag{Id: "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10", Value: "planes"}, Count: 3},
+		{Tag: storage.Tag{Id: "8e3e1c5e-0f6e-4a0e-8b0c-5b3d4e6f7a21", Value: "ships"}, Count: 0},
+	}, nil
+}
+
+func (h TagsHandlerMock) CreateTag(
+	_ context.Context, _ auth.AuthorizationDto, value string,
+) (storage.Tag, error) {
+	return storage.Tag{Id: "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10", Value: value}, nil
+}
+
+func (h TagsHandlerMock) RenameTag(
+	_ context.Context, _ auth.AuthorizationDto, tagId, value string,
+) (storage.Tag, error) {
+	return storage.Tag{Id: tagId, Value: value}, nil
+}
+
+func (h TagsHandlerMock) DeleteTag(_ context.Context, _ auth.AuthorizationDto, _ string) error {
+	return nil
+}
@@ -0,0 +1,138 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/http_server/authenticator"
+	"api/http_server/http_util"
+	"api/http_server/middleware"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"net/http"
+	"strings"
+)
+
+const maxTagBodyLimitBytes = 1024
+
+func TagsRouter(handler TagsHandler, logger *zerolog.Logger, authenticator authenticator.Authenticator) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/", FetchTags(handler, logger))
+		r.Post("/",
+			middleware.Authorize(AddTag(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Patch("/{tagId}",
+			middleware.Authorize(RenameTag(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Delete("/{tagId}",
+			middleware.Authorize(DeleteTag(handler, logger), authenticator, auth.RoleAdmin),
+		)
+	}
+}
+
+type TagDto struct {
+	Value string `json:"value"`
+}
+
+func (dto TagDto) validate() error {
+	value := strings.TrimSpace(dto.Value)
+	if len(value) < 2 || len(value) > 50 {
+		return exception.InvalidArgument{
+			Reason: "Tag value should be between 2 and 50 characters",
+		}
+	}
+
+	return nil
+}
+
+func readTagDto(w http.ResponseWriter, r *http.Request) (TagDto, error) {
+	var dto TagDto
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxTagBodyLimitBytes)).Decode(&dto); err != nil {
+		return TagDto{}, exception.InvalidArgument{Reason: "failed parsing json body"}
+	}
+	if err := dto.validate(); err != nil {
+		return TagDto{}, err
+	}
+
+	return dto, nil
+}
+
+func FetchTags(handler TagsHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		tags, err := handler.GetTags(r.Context())
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, tags)
+	}
+}
+
+func AddTag(handler TagsHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := readTagDto(w, r)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		tag, err := handler.CreateTag(ctx, authorization, data.Value)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusCreated, tag)
+	}
+}
+
+func RenameTag(handler TagsHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := readTagDto(w, r)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		tag, err := handler.RenameTag(ctx, authorization, chi.URLParam(r, "tagId"), data.Value)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, tag)
+	}
+}
+
+func DeleteTag(handler TagsHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		if err = handler.DeleteTag(ctx, authorization, chi.URLParam(r, "tagId")); err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusNoContent, nil)
+	}
+}
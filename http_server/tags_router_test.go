@@ -0,0 +1,158 @@
+package http_server
+
+import (
+	"api/http_server/authenticator"
+	"api/logger"
+	"api/storage"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const authHeaderMock = "Bearer tokenMock"
+
+func newTestServer(t *testing.T) *httptest.Server {
+	server, err := NewServer(logger.NewLogger(), NewDefaultConfig(), Handlers{
+		ImagesHandler:   ImagesHandlerMock{},
+		TagsHandler:     TagsHandlerMock{},
+		WebhooksHandler: WebhooksHandlerMock{},
+		AuditHandler:    AuditHandlerMock{},
+		TrashHandler:    TrashHandlerMock{},
+		UsersHandler:    UsersHandlerMock{},
+		Authenticator:   authenticator.Mock{},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if err = server.Shutdown(context.Background()); err != nil {
+			t.Error(err)
+		}
+	})
+
+	testServer := httptest.NewServer(server.router)
+	t.Cleanup(testServer.Close)
+
+	return testServer
+}
+
+func doRequest(t *testing.T, method, url, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", authHeaderMock)
+	req.Header.Set("Content-Type", "application/json")
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = res.Body.Close()
+	})
+
+	return res
+}
+
+func TestFetchTags(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res, err := http.Get(testServer.URL + "/api/v1/tags")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var tags storage.TagUsageList
+	if err = json.NewDecoder(res.Body).Decode(&tags); err != nil {
+		t.Fatal(err)
+	}
+
+	expected, _ := TagsHandlerMock{}.GetTags(context.Background())
+	if !reflect.DeepEqual(tags, expected) {
+		t.Fatalf("Expected %+v, got %+v", expected, tags)
+	}
+}
+
+func TestAddTag(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodPost, testServer.URL+"/api/v1/tags", `{"value": "planes"}`)
+	if res.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status code 201, got %d", res.StatusCode)
+	}
+
+	var tag storage.Tag
+	if err := json.NewDecoder(res.Body).Decode(&tag); err != nil {
+		t.Fatal(err)
+	}
+	if tag.Value != "planes" {
+		t.Fatalf("Expected tag value planes, got %s", tag.Value)
+	}
+}
+
+func TestAddTag_Invalid(t *testing.T) {
+	testServer := newTestServer(t)
+
+	data := []struct {
+		name string
+		body string
+	}{
+		{name: "Malformed json", body: `{"value":`},
+		{name: "Too short", body: `{"value": "a"}`},
+		{name: "Too long", body: `{"value": "` + strings.Repeat("a", 51) + `"}`},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, http.MethodPost, testServer.URL+"/api/v1/tags", d.body)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status code 400, got %d", res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestAddTag_Unauthorized(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res, err := http.Post(testServer.URL+"/api/v1/tags", "application/json", strings.NewReader(`{"value": "planes"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code 401, got %d", res.StatusCode)
+	}
+}
+
+func TestAttachTag(t *testing.T) {
+	testServer := newTestServer(t)
+
+	imageId := "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+	tagId := "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10"
+	res := doRequest(t, http.MethodPut, testServer.URL+"/api/v1/images/"+imageId+"/tags/"+tagId, "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var img storage.Image
+	if err := json.NewDecoder(res.Body).Decode(&img); err != nil {
+		t.Fatal(err)
+	}
+	if img.Id != imageId || len(img.Tags) != 1 || img.Tags[0].Id != tagId {
+		t.Fatalf("Expected image %s tagged with %s, got %+v", imageId, tagId, img)
+	}
+}
@@ -0,0 +1,10 @@
+package http_server
+
+import (
+	"api/storage"
+	"context"
+)
+
+type TrashHandler interface {
+	GetTrash(ctx context.Context, limit, offset int) (storage.ImagePage, error)
+}
@@ -0,0 +1,18 @@
+package http_server
+
+import (
+	"api/storage"
+	"context"
+	"time"
+)
+
+type TrashHandlerMock struct {
+}
+
+func (h TrashHandlerMock) GetTrash(_ context.Context, _, _ int) (storage.ImagePage, error) {
+	deletedAt := time.Date(2022, 5, 1, 10, 0, 0, 0, time.UTC)
+	images := storage.ImageList{
+		{Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263", Name: "my-image-1", DeletedAt: &deletedAt},
+	}
+	return storage.ImagePage{Images: images, Total: 1}, nil
+}
@@ -0,0 +1,39 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/http_server/authenticator"
+	"api/http_server/http_util"
+	"api/http_server/middleware"
+	"api/storage"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+// TrashRouter lists the deleted images to admins, they are restored through the images router
+func TrashRouter(handler TrashHandler, logger *zerolog.Logger, authenticator authenticator.Authenticator) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/",
+			middleware.Authorize(FetchTrash(handler, logger), authenticator, auth.RoleAdmin),
+		)
+	}
+}
+
+func FetchTrash(handler TrashHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		page := http_util.ToUint(r.URL.Query().Get("page"))
+		size := http_util.ToUint(r.URL.Query().Get("size"))
+		limit, offset := storage.PagingToLimitOffset(page, size)
+
+		result, err := handler.GetTrash(r.Context(), limit, offset)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(
+			w, http.StatusOK, http_util.NewPageResponse(result.Images, result.Total, limit, offset, result.HasNext),
+		)
+	}
+}
@@ -0,0 +1,45 @@
+package http_server
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+func TestFetchTrash(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/trash?page=1&size=10", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var page struct {
+		Items []map[string]interface{} `json:"items"`
+		Total float64                  `json:"total"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Items) != 1 || page.Items[0]["deletedAt"] == nil {
+		t.Fatalf("Expected a single deleted image, got %+v", page)
+	}
+}
+
+func TestRestoreImage(t *testing.T) {
+	testServer := newTestServer(t)
+	imageId := "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+
+	res := doRequest(t, http.MethodPost, testServer.URL+"/api/v1/images/"+imageId+"/restore", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var img map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&img); err != nil {
+		t.Fatal(err)
+	}
+	if img["id"] != imageId {
+		t.Fatalf("Expected restored image %s, got %+v", imageId, img)
+	}
+}
@@ -0,0 +1,13 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/storage"
+	"context"
+)
+
+type UsersHandler interface {
+	SetUserRole(
+		ctx context.Context, authorization auth.AuthorizationDto, userId, role string,
+	) (storage.User, error)
+}
@@ -0,0 +1,16 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/storage"
+	"context"
+)
+
+type UsersHandlerMock struct {
+}
+
+func (h UsersHandlerMock) SetUserRole(
+	_ context.Context, _ auth.AuthorizationDto, userId, role string,
+) (storage.User, error) {
+	return storage.User{Id: userId, Email: "user@example.com", Role: storage.AuthRole(role)}, nil
+}
@@ -0,0 +1,55 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/http_server/authenticator"
+	"api/http_server/http_util"
+	"api/http_server/middleware"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"net/http"
+)
+
+const maxUserRoleBodyLimitBytes = 1024
+
+// UsersRouter lets admins change the roles of the users
+func UsersRouter(handler UsersHandler, logger *zerolog.Logger, authenticator authenticator.Authenticator) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Put("/{userId}/role",
+			middleware.Authorize(SetUserRole(handler, logger), authenticator, auth.RoleAdmin),
+		)
+	}
+}
+
+// UserRoleDto holds the role to give, empty for none
+type UserRoleDto struct {
+	Role *string `json:"role"`
+}
+
+func SetUserRole(handler UsersHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var data UserRoleDto
+		err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxUserRoleBodyLimitBytes)).Decode(&data)
+		if err != nil || data.Role == nil {
+			http_util.WriteBadRequestJson(w, exception.InvalidArgument{Reason: "Expected a json body with the role"})
+			return
+		}
+
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		user, err := handler.SetUserRole(ctx, authorization, chi.URLParam(r, "userId"), *data.Role)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, user)
+	}
+}
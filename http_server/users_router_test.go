@@ -0,0 +1,73 @@
+package http_server
+
+import (
+	"api/storage"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+const userIdMock = "6f1d3b5a-9c7e-4a2b-8d0f-2e4c6a8b0d13"
+
+func TestSetUserRole(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(
+		t, http.MethodPut, testServer.URL+"/api/v1/users/"+userIdMock+"/role", `{"role": "Administrators"}`,
+	)
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var user storage.User
+	if err := json.NewDecoder(res.Body).Decode(&user); err != nil {
+		t.Fatal(err)
+	}
+	if user.Id != userIdMock || user.Role != storage.AuthRoleAdmin {
+		t.Fatalf("Expected user %s with the admin role, got %+v", userIdMock, user)
+	}
+}
+
+func TestSetUserRole_Invalid(t *testing.T) {
+	testServer := newTestServer(t)
+
+	data := []struct {
+		name string
+		body string
+	}{
+		{name: "Malformed json", body: `{"role":`},
+		{name: "Missing role", body: `{}`},
+		{name: "Too large", body: `{"role": "` + strings.Repeat("a", maxUserRoleBodyLimitBytes) + `"}`},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, http.MethodPut, testServer.URL+"/api/v1/users/"+userIdMock+"/role", d.body)
+			if res.StatusCode != http.StatusBadRequest {
+				t.Fatalf("Expected status code 400, got %d", res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestSetUserRole_Unauthorized(t *testing.T) {
+	testServer := newTestServer(t)
+
+	req, err := http.NewRequest(
+		http.MethodPut, testServer.URL+"/api/v1/users/"+userIdMock+"/role", strings.NewReader(`{"role": ""}`),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code 401, got %d", res.StatusCode)
+	}
+}
@@ -0,0 +1,23 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/storage"
+	"context"
+)
+
+type WebhooksHandler interface {
+	GetWebhooks(ctx context.Context) ([]storage.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, subscriptionId string) (storage.WebhookSubscription, error)
+	CreateWebhook(
+		ctx context.Context, authorization auth.AuthorizationDto, input storage.WebhookInput,
+	) (storage.WebhookSubscription, error)
+	UpdateWebhook(
+		ctx context.Context, authorization auth.AuthorizationDto, subscriptionId string, input storage.WebhookInput,
+	) (storage.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, authorization auth.AuthorizationDto, subscriptionId string) error
+	GetWebhookDeliveries(
+		ctx context.Context, filter storage.WebhookDeliveryFilter, limit, offset int,
+	) (storage.WebhookDeliveryPage, error)
+	ReplayWebhookDelivery(ctx context.Context, deliveryId string) (storage.WebhookDelivery, error)
+}
@@ -0,0 +1,76 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/storage"
+	"context"
+)
+
+const (
+	webhookIdMock         = "5f0c2f7e-3c1b-4b8e-9a44-0d8c2f9e1a10"
+	webhookDeliveryIdMock = "7b2d4e6f-8a9b-4c1d-9e2f-3a4b5c6d7e80"
+)
+
+type WebhooksHandlerMock struct {
+}
+
+func (h WebhooksHandlerMock) GetWebhooks(_ context.Context) ([]storage.WebhookSubscription, error) {
+	return []storage.WebhookSubscription{
+		{Id: webhookIdMock, Url: "https://partner.com/hooks", Secret: "a-very-secret-secret"},
+	}, nil
+}
+
+func (h WebhooksHandlerMock) GetWebhook(
+	_ context.Context, subscriptionId string,
+) (storage.WebhookSubscription, error) {
+	if subscriptionId != webhookIdMock {
+		return storage.WebhookSubscription{}, exception.NotFound{Msg: "Webhook not found " + subscriptionId}
+	}
+	return storage.WebhookSubscription{Id: webhookIdMock, Url: "https://partner.com/hooks"}, nil
+}
+
+func (h WebhooksHandlerMock) CreateWebhook(
+	_ context.Context, _ auth.AuthorizationDto, input storage.WebhookInput,
+) (storage.WebhookSubscription, error) {
+	return storage.WebhookSubscription{Id: webhookIdMock, Url: input.Url, Secret: input.Secret, Events: input.Events}, nil
+}
+
+func (h WebhooksHandlerMock) UpdateWebhook(
+	_ context.Context, _ auth.AuthorizationDto, subscriptionId string, input storage.WebhookInput,
+) (storage.WebhookSubscription, error) {
+	return storage.WebhookSubscription{Id: subscriptionId, Url: input.Url, Events: input.Events}, nil
+}
+
+func (h WebhooksHandlerMock) DeleteWebhook(_ context.Context, _ auth.AuthorizationDto, _ string) error {
+	return nil
+}
+
+func (h WebhooksHandlerMock) GetWebhookDeliveries(
+	_ context.Context, filter storage.WebhookDeliveryFilter, _, _ int,
+) (storage.WebhookDeliveryPage, error) {
+	delivery := storage.WebhookDelivery{
+		Id:             webhookDeliveryIdMock,
+		SubscriptionId: webhookIdMock,
+		EventType:      storage.EventImageCreated,
+		StatusCode:     500,
+		Attempts:       4,
+	}
+	if filter.Success != nil && *filter.Success {
+		return storage.WebhookDeliveryPage{Deliveries: []storage.WebhookDelivery{}}, nil
+	}
+	return storage.WebhookDeliveryPage{Deliveries: []storage.WebhookDelivery{delivery}, Total: 1}, nil
+}
+
+func (h WebhooksHandlerMock) ReplayWebhookDelivery(
+	_ context.Context, deliveryId string,
+) (storage.WebhookDelivery, error) {
+	return storage.WebhookDelivery{
+		Id:             "9c8b7a6d-5e4f-4a3b-8c2d-1e0f9a8b7c60",
+		SubscriptionId: webhookIdMock,
+		EventType:      storage.EventImageCreated,
+		StatusCode:     200,
+		Success:        true,
+		Attempts:       1,
+	}, nil
+}
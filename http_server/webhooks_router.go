@@ -0,0 +1,205 @@
+package http_server
+
+import (
+	"api/auth"
+	"api/core/exception"
+	"api/http_server/authenticator"
+	"api/http_server/http_util"
+	"api/http_server/middleware"
+	"api/storage"
+	"encoding/json"
+	"github.com/go-chi/chi/v5"
+	"github.com/rs/zerolog"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+const maxWebhookBodyLimitBytes = 4 * 1024
+
+// WebhooksRouter exposes the webhook subscriptions and their delivery log to admins only
+func WebhooksRouter(
+	handler WebhooksHandler, logger *zerolog.Logger, authenticator authenticator.Authenticator,
+) func(chi.Router) {
+	return func(r chi.Router) {
+		r.Get("/",
+			middleware.Authorize(FetchWebhooks(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Post("/",
+			middleware.Authorize(AddWebhook(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Get("/deliveries",
+			middleware.Authorize(FetchWebhookDeliveries(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Post("/deliveries/{deliveryId}/replay",
+			middleware.Authorize(ReplayWebhookDelivery(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Get("/{webhookId}",
+			middleware.Authorize(FetchWebhook(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Put("/{webhookId}",
+			middleware.Authorize(UpdateWebhook(handler, logger), authenticator, auth.RoleAdmin),
+		)
+		r.Delete("/{webhookId}",
+			middleware.Authorize(DeleteWebhook(handler, logger), authenticator, auth.RoleAdmin),
+		)
+	}
+}
+
+func readWebhookInput(w http.ResponseWriter, r *http.Request) (storage.WebhookInput, error) {
+	var input storage.WebhookInput
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxWebhookBodyLimitBytes)).Decode(&input); err != nil {
+		return storage.WebhookInput{}, exception.InvalidArgument{Reason: "failed parsing json body"}
+	}
+
+	return input, nil
+}
+
+func parseWebhookDeliveryFilter(query url.Values) (storage.WebhookDeliveryFilter, error) {
+	filter := storage.WebhookDeliveryFilter{
+		SubscriptionId: query.Get("webhookId"),
+		EventId:        query.Get("eventId"),
+	}
+
+	if value := query.Get("success"); value != "" {
+		success, err := strconv.ParseBool(value)
+		if err != nil {
+			return storage.WebhookDeliveryFilter{}, exception.InvalidArgument{
+				Reason: "Query parameter success must be true or false",
+			}
+		}
+		filter.Success = &success
+	}
+
+	return filter, nil
+}
+
+func FetchWebhooks(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhooks, err := handler.GetWebhooks(r.Context())
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, webhooks)
+	}
+}
+
+func FetchWebhook(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		webhook, err := handler.GetWebhook(r.Context(), chi.URLParam(r, "webhookId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, webhook)
+	}
+}
+
+func AddWebhook(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := readWebhookInput(w, r)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		webhook, err := handler.CreateWebhook(ctx, authorization, data)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusCreated, webhook)
+	}
+}
+
+func UpdateWebhook(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := readWebhookInput(w, r)
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		webhook, err := handler.UpdateWebhook(ctx, authorization, chi.URLParam(r, "webhookId"), data)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, webhook)
+	}
+}
+
+func DeleteWebhook(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		authorization, err := auth.ExtractAuthorizationDto(ctx, middleware.UserAuthDtoKey)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		if err = handler.DeleteWebhook(ctx, authorization, chi.URLParam(r, "webhookId")); err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusNoContent, nil)
+	}
+}
+
+func FetchWebhookDeliveries(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		filter, err := parseWebhookDeliveryFilter(r.URL.Query())
+		if err != nil {
+			http_util.WriteBadRequestJson(w, err)
+			return
+		}
+
+		page := http_util.ToUint(r.URL.Query().Get("page"))
+		size := http_util.ToUint(r.URL.Query().Get("size"))
+		limit, offset := storage.PagingToLimitOffset(page, size)
+
+		result, err := handler.GetWebhookDeliveries(r.Context(), filter, limit, offset)
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(
+			w,
+			http.StatusOK,
+			http_util.NewPageResponse(result.Deliveries, result.Total, limit, offset, result.HasNext),
+		)
+	}
+}
+
+func ReplayWebhookDelivery(handler WebhooksHandler, logger *zerolog.Logger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		delivery, err := handler.ReplayWebhookDelivery(r.Context(), chi.URLParam(r, "deliveryId"))
+		if err != nil {
+			http_util.HandleError(logger, w, err)
+			return
+		}
+
+		http_util.WriteJson(w, http.StatusOK, delivery)
+	}
+}
@@ -0,0 +1,121 @@
+package http_server
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestFetchWebhooks(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/webhooks", "")
+	if res.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+	}
+
+	var webhooks []map[string]interface{}
+	if err := json.NewDecoder(res.Body).Decode(&webhooks); err != nil {
+		t.Fatal(err)
+	}
+	if len(webhooks) != 1 || webhooks[0]["id"] != webhookIdMock {
+		t.Fatalf("Expected webhook %s, got %+v", webhookIdMock, webhooks)
+	}
+	if _, ok := webhooks[0]["secret"]; ok {
+		t.Fatal("Expected the secret to be hidden")
+	}
+}
+
+func TestFetchWebhooks_Unauthorized(t *testing.T) {
+	testServer := newTestServer(t)
+
+	res, err := http.Get(testServer.URL + "/api/v1/webhooks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("Expected status code 401, got %d", res.StatusCode)
+	}
+}
+
+func TestWebhookRoutes(t *testing.T) {
+	testServer := newTestServer(t)
+	webhookUrl := testServer.URL + "/api/v1/webhooks"
+	itemUrl := webhookUrl + "/" + webhookIdMock
+	missingUrl := webhookUrl + "/0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10"
+	replayUrl := webhookUrl + "/deliveries/" + webhookDeliveryIdMock + "/replay"
+	body := `{"url": "https://partner.com/hooks", "secret": "a-very-secret-secret", "events": ["image.created"]}`
+
+	data := []struct {
+		name           string
+		method         string
+		url            string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Create", method: http.MethodPost, url: webhookUrl, body: body, expectedStatus: http.StatusCreated},
+		{name: "Malformed", method: http.MethodPost, url: webhookUrl, body: `{"url":`, expectedStatus: http.StatusBadRequest},
+		{name: "Fetch one", method: http.MethodGet, url: itemUrl, expectedStatus: http.StatusOK},
+		{name: "Fetch missing", method: http.MethodGet, url: missingUrl, expectedStatus: http.StatusNotFound},
+		{name: "Update", method: http.MethodPut, url: itemUrl, body: body, expectedStatus: http.StatusOK},
+		{name: "Delete", method: http.MethodDelete, url: itemUrl, expectedStatus: http.StatusNoContent},
+		{name: "Replay", method: http.MethodPost, url: replayUrl, expectedStatus: http.StatusOK},
+		{
+			name:           "Invalid deliveries filter",
+			method:         http.MethodGet,
+			url:            webhookUrl + "/deliveries?success=maybe",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, d.method, d.url, d.body)
+			if res.StatusCode != d.expectedStatus {
+				t.Fatalf("Expected status code %d, got %d", d.expectedStatus, res.StatusCode)
+			}
+		})
+	}
+}
+
+func TestFetchWebhookDeliveries(t *testing.T) {
+	testServer := newTestServer(t)
+
+	data := []struct {
+		name          string
+		query         string
+		expectedTotal int
+	}{
+		{name: "All", query: "", expectedTotal: 1},
+		{name: "Failed", query: "?success=false&webhookId=" + webhookIdMock, expectedTotal: 1},
+		{name: "Successful", query: "?success=true", expectedTotal: 0},
+	}
+
+	for _, d := range data {
+		t.Run(d.name, func(t *testing.T) {
+			res := doRequest(t, http.MethodGet, testServer.URL+"/api/v1/webhooks/deliveries"+d.query, "")
+			if res.StatusCode != http.StatusOK {
+				t.Fatalf("Expected status code 200, got %d", res.StatusCode)
+			}
+
+			var page struct {
+				ApiVersion string            `json:"apiVersion"`
+				Items      []json.RawMessage `json:"items"`
+				Total      int               `json:"total"`
+			}
+			if err := json.NewDecoder(res.Body).Decode(&page); err != nil {
+				t.Fatal(err)
+			}
+			if page.Total != d.expectedTotal || len(page.Items) != d.expectedTotal || page.ApiVersion != "v1" {
+				t.Fatalf("Expected %d deliveries, got %+v", d.expectedTotal, page)
+			}
+			if d.expectedTotal > 0 && !strings.Contains(string(page.Items[0]), webhookDeliveryIdMock) {
+				t.Fatalf("Expected delivery %s, got %s", webhookDeliveryIdMock, page.Items[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+// Package content checks that the uploaded files really are images of the declared format and of a size that is safe
+// to decode, and strips the location the images were taken at before they leave the API
+package content
+
+import (
+	"api/image"
+	"bytes"
+	"errors"
+	"fmt"
+	_ "golang.org/x/image/webp"
+	stdimage "image"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+)
+
+var (
+	ErrUnknownFormat = errors.New("not a jpg, png or webp image")
+	ErrMismatch      = errors.New("content does not match the format")
+	ErrTooManyPixels = errors.New("too many pixels")
+)
+
+var (
+	jpegMagic = []byte{0xff, 0xd8, 0xff}
+	pngMagic  = []byte{0x89, 'P', 'N', 'G', '\r', '\n', 0x1a, '\n'}
+	riffMagic = []byte("RIFF")
+	webpMagic = []byte("WEBP")
+)
+
+// Sniff returns the format of the content from its magic bytes
+func Sniff(header []byte) (image.Format, error) {
+	switch {
+	case bytes.HasPrefix(header, jpegMagic):
+		return image.JpgFormat, nil
+	case bytes.HasPrefix(header, pngMagic):
+		return image.PngFormat, nil
+	case len(header) >= 12 && bytes.HasPrefix(header, riffMagic) && bytes.Equal(header[8:12], webpMagic):
+		return image.WebpFormat, nil
+	}
+	return "", ErrUnknownFormat
+}
+
+// Inspect returns the format of the content together with its dimensions, only the header of the image is decoded
+func Inspect(r io.Reader) (image.Format, image.Dimensions, error) {
+	header := make([]byte, 12)
+	n, err := io.ReadFull(r, header)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return "", image.Dimensions{}, ErrUnknownFormat
+	}
+	format, err := Sniff(header[:n])
+	if err != nil {
+		return "", image.Dimensions{}, err
+	}
+
+	config, _, err := stdimage.DecodeConfig(io.MultiReader(bytes.NewReader(header[:n]), r))
+	if err != nil {
+		return "", image.Dimensions{}, fmt.Errorf("%w: %v", ErrUnknownFormat, err)
+	}
+
+	return format, image.Dimensions{Width: config.Width, Height: config.Height}, nil
+}
+
+// CheckPixels refuses images holding more than maxPixels once decoded, a small file can declare dimensions that
+// take gigabytes to decode. A maxPixels of 0 allows any.
+func CheckPixels(dimensions image.Dimensions, maxPixels int) error {
+	if maxPixels > 0 && int64(dimensions.Width)*int64(dimensions.Height) > int64(maxPixels) {
+		return fmt.Errorf(
+			"%w: %dx%d is more than %d pixels", ErrTooManyPixels, dimensions.Width, dimensions.Height, maxPixels,
+		)
+	}
+	return nil
+}
+
+// Decode decodes the image after checking from its header that it has at most maxPixels
+func Decode(data []byte, maxPixels int) (stdimage.Image, error) {
+	_, dimensions, err := Inspect(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if err = CheckPixels(dimensions, maxPixels); err != nil {
+		return nil, err
+	}
+
+	img, _, err := stdimage.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed decoding image: %w", err)
+	}
+	return img, nil
+}
+
+// Sanitize checks that the data is an image of the format with at most maxPixels, and returns it without GPS data
+func Sanitize(data []byte, format image.Format, maxPixels int) ([]byte, error) {
+	actual, dimensions, err := Inspect(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if actual != format {
+		return nil, fmt.Errorf("%w: %s is a %s image", ErrMismatch, format, actual)
+	}
+	if err = CheckPixels(dimensions, maxPixels); err != nil {
+		return nil, err
+	}
+
+	return StripGps(data, format)
+}
+
+// ReadFile reads the uploaded file and sanitizes it
+func ReadFile(fileHeader *multipart.FileHeader, format image.Format, maxPixels int) ([]byte, error) {
+	data, err := readAll(fileHeader)
+	if err != nil {
+		return nil, err
+	}
+
+	return Sanitize(data, format, maxPixels)
+}
+
+// ReadFileExif reads the EXIF of the uploaded file as it was sent, before its GPS data is stripped
+func ReadFileExif(fileHeader *multipart.FileHeader, format image.Format) (Exif, error) {
+	data, err := readAll(fileHeader)
+	if err != nil {
+		return Exif{}, err
+	}
+
+	return ReadExif(data, format)
+}
+
+func readAll(fileHeader *multipart.FileHeader) ([]byte, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	return ioutil.ReadAll(file)
+}
@@ -0,0 +1,213 @@
+package content_test
+
+import (
+	"api/image"
+	"api/image/content"
+	"api/image/pipeline"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	stdimage "image"
+	"testing"
+)
+
+// exifHeader starts the EXIF of jpg and may start the one of webp
+var exifHeader = []byte("Exif\x00\x00")
+
+// latitudeMock are the GPSLatitude rationals of 51° 30' 26.12"
+var latitudeMock = []byte{
+	51, 0, 0, 0, 1, 0, 0, 0,
+	30, 0, 0, 0, 1, 0, 0, 0,
+	0x34, 0x0a, 0, 0, 100, 0, 0, 0,
+}
+
+// newTiffWithGps builds a little endian TIFF with IFD0 holding the camera make and a pointer to the GPS IFD
+func newTiffWithGps() []byte {
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	entry := func(tag, fieldType uint16, count uint32, value []byte) []byte {
+		e := make([]byte, 12)
+		binary.LittleEndian.PutUint16(e, tag)
+		binary.LittleEndian.PutUint16(e[2:], fieldType)
+		binary.LittleEndian.PutUint32(e[4:], count)
+		copy(e[8:], value)
+		return e
+	}
+
+	tiff = append(tiff, 2, 0)
+	tiff = append(tiff, entry(0x010f, 2, 4, []byte("Cam\x00"))...)
+	tiff = append(tiff, entry(0x8825, 4, 1, []byte{38, 0, 0, 0})...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	tiff = append(tiff, 2, 0)
+	tiff = append(tiff, entry(0x0001, 2, 2, []byte("N\x00"))...)
+	tiff = append(tiff, entry(0x0002, 5, 3, []byte{68, 0, 0, 0})...)
+	tiff = append(tiff, 0, 0, 0, 0)
+
+	return append(tiff, latitudeMock...)
+}
+
+func encodeTestImage(t *testing.T, format image.Format) []byte {
+	data, err := pipeline.Encode(stdimage.NewRGBA(stdimage.Rect(0, 0, 40, 30)), format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func withJpegExif(data, tiff []byte) []byte {
+	segment := append([]byte{0xff, 0xe1, 0, 0}, exifHeader...)
+	segment = append(segment, tiff...)
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(segment)-2))
+	return append(append(append([]byte{}, data[:2]...), segment...), data[2:]...)
+}
+
+func withPngExif(data, tiff []byte) []byte {
+	// The eXIf chunk goes after the 8 bytes of the signature and the 25 of the IHDR chunk
+	chunk := make([]byte, 4, 12+len(tiff))
+	binary.BigEndian.PutUint32(chunk, uint32(len(tiff)))
+	chunk = append(append(chunk, "eXIf"...), tiff...)
+	checksum := make([]byte, 4)
+	binary.BigEndian.PutUint32(checksum, crc32.ChecksumIEEE(chunk[4:]))
+	chunk = append(chunk, checksum...)
+	return append(append(append([]byte{}, data[:33]...), chunk...), data[33:]...)
+}
+
+func withWebpExif(data, tiff []byte) []byte {
+	chunk := make([]byte, 8, 8+len(tiff)+1)
+	copy(chunk, "EXIF")
+	binary.LittleEndian.PutUint32(chunk[4:], uint32(len(tiff)))
+	chunk = append(chunk, tiff...)
+	if len(tiff)%2 != 0 {
+		chunk = append(chunk, 0)
+	}
+	out := append(append([]byte{}, data...), chunk...)
+	binary.LittleEndian.PutUint32(out[4:], uint32(len(out)-8))
+	return out
+}
+
+func TestSniff(t *testing.T) {
+	for _, format := range image.SupportedFormats {
+		sniffed, err := content.Sniff(encodeTestImage(t, format))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sniffed != format {
+			t.Fatalf("Expected %s, got %s", format, sniffed)
+		}
+	}
+
+	if _, err := content.Sniff([]byte("GIF89a")); !errors.Is(err, content.ErrUnknownFormat) {
+		t.Fatalf("Expected unknown format, got %v", err)
+	}
+}
+
+func TestSanitize(t *testing.T) {
+	png := encodeTestImage(t, image.PngFormat)
+
+	if _, err := content.Sanitize(png, image.JpgFormat, 0); !errors.Is(err, content.ErrMismatch) {
+		t.Fatalf("Expected mismatch of png declared as jpg, got %v", err)
+	}
+	if _, err := content.Sanitize(png, image.PngFormat, 40*30-1); !errors.Is(err, content.ErrTooManyPixels) {
+		t.Fatalf("Expected too many pixels, got %v", err)
+	}
+	if _, err := content.Sanitize(png, image.PngFormat, 40*30); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestStripGps(t *testing.T) {
+	values := []struct {
+		Format   image.Format
+		WithExif func(data, tiff []byte) []byte
+	}{
+		{Format: image.JpgFormat, WithExif: withJpegExif},
+		{Format: image.PngFormat, WithExif: withPngExif},
+		{Format: image.WebpFormat, WithExif: withWebpExif},
+	}
+
+	for _, data := range values {
+		t.Run(string(data.Format), func(t *testing.T) {
+			withExif := data.WithExif(encodeTestImage(t, data.Format), newTiffWithGps())
+
+			stripped, err := content.Sanitize(withExif, data.Format, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(stripped) != len(withExif) {
+				t.Fatalf("Expected the EXIF to be stripped in place, got %d bytes from %d", len(stripped), len(withExif))
+			}
+			if bytes.Contains(stripped, latitudeMock) {
+				t.Fatal("Expected the latitude to be removed")
+			}
+
+			tiff := stripped[bytes.Index(stripped, []byte("II*\x00")):]
+			if count := binary.LittleEndian.Uint16(tiff[8:]); count != 1 {
+				t.Fatalf("Expected only the camera make left in IFD0, got %d entries", count)
+			}
+			if !bytes.Contains(tiff, []byte("Cam\x00")) {
+				t.Fatal("Expected the camera make to be kept")
+			}
+			if _, _, err = stdimage.Decode(bytes.NewReader(stripped)); err != nil {
+				t.Fatalf("Expected the stripped image to decode, got %v", err)
+			}
+		})
+	}
+}
+
+func TestStripGps_RemovesInvalidExif(t *testing.T) {
+	withExif := withJpegExif(encodeTestImage(t, image.JpgFormat), []byte("II*\x00\xff\xff\xff\xff"))
+
+	stripped, err := content.StripGps(withExif, image.JpgFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(stripped, exifHeader) {
+		t.Fatal("Expected the unreadable EXIF to be removed")
+	}
+}
+
+// jfifSegment is the APP0 segment of a JFIF file, with it decoding the config of a jpg stops at the start of frame
+var jfifSegment = []byte{0xff, 0xe0, 0, 16, 'J', 'F', 'I', 'F', 0, 1, 1, 0, 0, 1, 0, 1, 0, 0}
+
+// withJpegSegmentAfterFrame makes a JFIF file of the data with the segment after the start of frame, where decoding
+// the config stops reading
+func withJpegSegmentAfterFrame(t *testing.T, data, segment []byte) []byte {
+	frame := bytes.Index(data, []byte{0xff, 0xc0})
+	if frame < 0 {
+		t.Fatal("Expected a baseline start of frame")
+	}
+	end := frame + 2 + int(binary.BigEndian.Uint16(data[frame+2:]))
+
+	jfif := append(append([]byte{}, data[:2]...), jfifSegment...)
+	jfif = append(append(jfif, data[2:end]...), segment...)
+	return append(jfif, data[end:]...)
+}
+
+func TestSanitize_InvalidJpegSegment(t *testing.T) {
+	values := []struct {
+		Name    string
+		Segment []byte
+	}{
+		{Name: "Length 0", Segment: []byte{0xff, 0xe1, 0, 0}},
+		{Name: "Length 1", Segment: []byte{0xff, 0xe2, 0, 1}},
+		{Name: "Past the end", Segment: []byte{0xff, 0xe1, 0xff, 0xff, 'E', 'x', 'i', 'f'}},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			jpg := withJpegSegmentAfterFrame(t, encodeTestImage(t, image.JpgFormat), data.Segment)
+			if data.Name == "Past the end" {
+				// Truncated right after the segment header
+				jpg = jpg[:bytes.Index(jpg, data.Segment)+len(data.Segment)]
+			}
+			if _, _, err := stdimage.DecodeConfig(bytes.NewReader(jpg)); err != nil {
+				t.Fatalf("Expected the config to decode, got %v", err)
+			}
+
+			if _, err := content.Sanitize(jpg, image.JpgFormat, 0); err == nil {
+				t.Fatal("Expected the invalid segment to be refused")
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package content
+
+import (
+	"golang.org/x/image/draw"
+	stdimage "image"
+)
+
+// DHash is the difference hash of the image, it is shrunk to 9x8 shades of gray and every bit tells whether a shade
+// is brighter than the one to its left. Resizing, recompressing or slightly retouching an image flips only a few
+// bits, so the number of differing bits measures how alike two images look.
+func DHash(img stdimage.Image) uint64 {
+	gray := stdimage.NewGray(stdimage.Rect(0, 0, 9, 8))
+	draw.BiLinear.Scale(gray, gray.Bounds(), img, img.Bounds(), draw.Src, nil)
+
+	var hash uint64
+	for y := 0; y < 8; y++ {
+		for x := 0; x < 8; x++ {
+			hash <<= 1
+			if gray.GrayAt(x+1, y).Y > gray.GrayAt(x, y).Y {
+				hash |= 1
+			}
+		}
+	}
+	return hash
+}
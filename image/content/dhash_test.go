@@ -0,0 +1,57 @@
+package content_test
+
+import (
+	"api/image"
+	"api/image/content"
+	"api/image/pipeline"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"math/bits"
+	"testing"
+)
+
+// newPatternImage draws bands of varying brightness side by side, or one above the other
+func newPatternImage(width, height int, stacked bool) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			band := x * 7 / width
+			if stacked {
+				band = y * 7 / height
+			}
+			shade := uint8(band * 97 % 256)
+			img.Set(x, y, color.NRGBA{R: shade, G: shade / 2, B: 255 - shade, A: 255})
+		}
+	}
+	return img
+}
+
+func hashOf(t *testing.T, img stdimage.Image, format image.Format) uint64 {
+	data, err := pipeline.Encode(img, format)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded, err := content.Decode(data, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content.DHash(decoded)
+}
+
+func TestDHash(t *testing.T) {
+	hash := hashOf(t, newPatternImage(400, 300, false), image.PngFormat)
+
+	if distance := bits.OnesCount64(hash ^ hashOf(t, newPatternImage(200, 150, false), image.JpgFormat)); distance > 4 {
+		t.Fatalf("Expected the resized jpg to look alike, got a distance of %d", distance)
+	}
+	if distance := bits.OnesCount64(hash ^ hashOf(t, newPatternImage(400, 300, true), image.PngFormat)); distance < 16 {
+		t.Fatalf("Expected the stacked bands to look different, got a distance of %d", distance)
+	}
+}
+
+func TestDecode_TooManyPixels(t *testing.T) {
+	if _, err := content.Decode(encodeTestImage(t, image.PngFormat), 40*30-1); !errors.Is(err, content.ErrTooManyPixels) {
+		t.Fatalf("Expected too many pixels, got %v", err)
+	}
+}
@@ -0,0 +1,339 @@
+package content
+
+import (
+	"api/image"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// The tags read from IFD0, from the EXIF IFD it points to and from the GPS IFD
+const (
+	makeTag               = 0x010f
+	modelTag              = 0x0110
+	orientationTag        = 0x0112
+	exifIfdTag            = 0x8769
+	exposureTimeTag       = 0x829a
+	fNumberTag            = 0x829d
+	isoTag                = 0x8827
+	dateTimeOriginalTag   = 0x9003
+	offsetTimeOriginalTag = 0x9011
+	focalLengthTag        = 0x920a
+	lensModelTag          = 0xa434
+	gpsLatitudeRefTag     = 0x0001
+	gpsLatitudeTag        = 0x0002
+	gpsLongitudeRefTag    = 0x0003
+	gpsLongitudeTag       = 0x0004
+)
+
+const (
+	// exifTimeLayout is how cameras write dates, in their local time unless an offset is written next to them
+	exifTimeLayout = "2006:01:02 15:04:05"
+	// maxExifTextLength bounds the texts kept from the EXIF, they are written by the camera or by any editor
+	maxExifTextLength = 128
+)
+
+// Exif is the part of the EXIF of a photo that photographers care about, tags missing from the file stay empty
+type Exif struct {
+	Make      string
+	Model     string
+	LensModel string
+	// ExposureTime is in seconds, written as a fraction such as 1/250 below one second
+	ExposureTime string
+	FNumber      float64
+	// FocalLength is in millimeters
+	FocalLength float64
+	Iso         int
+	// CapturedAt is in UTC, the local time of the camera is taken as UTC when it did not write its offset
+	CapturedAt *time.Time
+	// Orientation is the EXIF orientation from 1 to 8, how the camera was held
+	Orientation int
+	// Gps is always read, it is up to the caller to keep it
+	Gps *GpsPosition
+}
+
+// GpsPosition is where the photo was taken, in decimal degrees
+type GpsPosition struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// ReadExif reads the EXIF of the image, images without one get an empty Exif
+func ReadExif(data []byte, format image.Format) (Exif, error) {
+	var tiff []byte
+	switch format {
+	case image.JpgFormat:
+		var err error
+		if tiff, err = findJpegExif(data); err != nil {
+			return Exif{}, err
+		}
+	case image.PngFormat:
+		tiff = findPngExif(data)
+	case image.WebpFormat:
+		tiff = findWebpExif(data)
+	default:
+		return Exif{}, ErrUnknownFormat
+	}
+	if tiff == nil {
+		return Exif{}, nil
+	}
+
+	return parseExif(tiff)
+}
+
+// findJpegExif walks the segments up to the image data looking for the APP1 EXIF segment
+func findJpegExif(data []byte) ([]byte, error) {
+	pos := 2
+	for {
+		segment, ok, err := nextJpegSegment(data, pos)
+		if err != nil || !ok {
+			return nil, err
+		}
+
+		payload := data[segment.start:segment.end]
+		if segment.marker == 0xe1 && bytes.HasPrefix(payload, exifHeader) {
+			return payload[len(exifHeader):], nil
+		}
+		pos = segment.end
+	}
+}
+
+// findPngExif walks the chunks looking for eXIf
+func findPngExif(data []byte) []byte {
+	pos := len(pngMagic)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return nil
+		}
+		switch string(data[pos+4 : pos+8]) {
+		case "IEND":
+			return nil
+		case "eXIf":
+			return data[pos+8 : pos+8+length]
+		}
+		pos = end
+	}
+	return nil
+}
+
+// findWebpExif walks the RIFF chunks looking for EXIF, written with or without the header of jpg
+func findWebpExif(data []byte) []byte {
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		if size < 0 || pos+8+size > len(data) {
+			return nil
+		}
+		if string(data[pos:pos+4]) == "EXIF" {
+			return bytes.TrimPrefix(data[pos+8:pos+8+size], exifHeader)
+		}
+		pos += 8 + size + size%2
+	}
+	return nil
+}
+
+func parseExif(tiff []byte) (Exif, error) {
+	order, err := tiffByteOrder(tiff)
+	if err != nil {
+		return Exif{}, err
+	}
+	ifd0, err := readIfd(tiff, order, uint64(order.Uint32(tiff[4:])))
+	if err != nil {
+		return Exif{}, err
+	}
+
+	exif := Exif{
+		Make:  ifd0[makeTag].text(),
+		Model: ifd0[modelTag].text(),
+	}
+	if orientation, ok := ifd0[orientationTag].uint(0); ok && orientation >= 1 && orientation <= 8 {
+		exif.Orientation = int(orientation)
+	}
+
+	// The sub IFDs are optional, one that can not be read leaves its tags empty
+	if subIfd, err := readSubIfd(tiff, order, ifd0[exifIfdTag]); err == nil {
+		exif.LensModel = subIfd[lensModelTag].text()
+		exif.ExposureTime = exposureTime(subIfd[exposureTimeTag])
+		exif.FNumber = roundTenth(subIfd[fNumberTag].rational(0))
+		exif.FocalLength = roundTenth(subIfd[focalLengthTag].rational(0))
+		if iso, ok := subIfd[isoTag].uint(0); ok {
+			exif.Iso = int(iso)
+		}
+		exif.CapturedAt = capturedAt(subIfd[dateTimeOriginalTag], subIfd[offsetTimeOriginalTag])
+	}
+	if gpsIfd, err := readSubIfd(tiff, order, ifd0[gpsIfdTag]); err == nil {
+		exif.Gps = gpsPosition(gpsIfd)
+	}
+
+	return exif, nil
+}
+
+// tiffEntry is a tag of an IFD with its values, read from wherever they are stored
+type tiffEntry struct {
+	order     binary.ByteOrder
+	fieldType uint16
+	count     uint64
+	value     []byte
+}
+
+// readIfd reads the entries of the IFD by tag, entries whose values lie outside of the TIFF are left out
+func readIfd(tiff []byte, order binary.ByteOrder, ifd uint64) (map[uint16]tiffEntry, error) {
+	count, err := ifdEntries(tiff, order, ifd)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(map[uint16]tiffEntry, count)
+	for i := uint64(0); i < count; i++ {
+		pos := ifd + 2 + 12*i
+		entry := tiffEntry{
+			order:     order,
+			fieldType: order.Uint16(tiff[pos+2:]),
+			count:     uint64(order.Uint32(tiff[pos+4:])),
+		}
+		size := tiffTypeSizes[entry.fieldType] * entry.count
+		if size <= 4 {
+			entry.value = tiff[pos+8 : pos+8+size]
+		} else {
+			offset := uint64(order.Uint32(tiff[pos+8:]))
+			if offset+size > uint64(len(tiff)) {
+				continue
+			}
+			entry.value = tiff[offset : offset+size]
+		}
+		entries[order.Uint16(tiff[pos:])] = entry
+	}
+	return entries, nil
+}
+
+func readSubIfd(tiff []byte, order binary.ByteOrder, pointer tiffEntry) (map[uint16]tiffEntry, error) {
+	offset, ok := pointer.uint(0)
+	if !ok {
+		return nil, errInvalidExif
+	}
+	return readIfd(tiff, order, uint64(offset))
+}
+
+// text is the ASCII value up to its terminating null, without the control characters and the padding some cameras
+// write
+func (entry tiffEntry) text() string {
+	if entry.fieldType != 2 {
+		return ""
+	}
+	value := entry.value
+	if end := bytes.IndexByte(value, 0); end >= 0 {
+		value = value[:end]
+	}
+	text := strings.Map(func(r rune) rune {
+		if r == utf8.RuneError || !unicode.IsPrint(r) {
+			return -1
+		}
+		return r
+	}, string(value))
+	text = strings.TrimSpace(text)
+
+	if runes := []rune(text); len(runes) > maxExifTextLength {
+		text = strings.TrimSpace(string(runes[:maxExifTextLength]))
+	}
+	return text
+}
+
+// uint is the i-th SHORT or LONG value
+func (entry tiffEntry) uint(i int) (uint32, bool) {
+	switch {
+	case entry.fieldType == 3 && len(entry.value) >= 2*(i+1):
+		return uint32(entry.order.Uint16(entry.value[2*i:])), true
+	case entry.fieldType == 4 && len(entry.value) >= 4*(i+1):
+		return entry.order.Uint32(entry.value[4*i:]), true
+	}
+	return 0, false
+}
+
+// fraction is the numerator and the denominator of the i-th RATIONAL value
+func (entry tiffEntry) fraction(i int) (uint32, uint32, bool) {
+	if entry.fieldType != 5 || len(entry.value) < 8*(i+1) {
+		return 0, 0, false
+	}
+	numerator, denominator := entry.order.Uint32(entry.value[8*i:]), entry.order.Uint32(entry.value[8*i+4:])
+	return numerator, denominator, denominator != 0
+}
+
+// rational is the i-th RATIONAL value, 0 when missing
+func (entry tiffEntry) rational(i int) float64 {
+	numerator, denominator, ok := entry.fraction(i)
+	if !ok {
+		return 0
+	}
+	return float64(numerator) / float64(denominator)
+}
+
+func roundTenth(value float64) float64 {
+	return math.Round(value*10) / 10
+}
+
+// exposureTime writes exposures below a second the way cameras show them, 10/2500 is 1/250
+func exposureTime(entry tiffEntry) string {
+	numerator, denominator, ok := entry.fraction(0)
+	if !ok || numerator == 0 {
+		return ""
+	}
+	if numerator >= denominator {
+		return strconv.FormatFloat(roundTenth(float64(numerator)/float64(denominator)), 'f', -1, 64)
+	}
+	return fmt.Sprintf("1/%d", int(math.Round(float64(denominator)/float64(numerator))))
+}
+
+// capturedAt is nil for the dates cameras write when their clock was never set, such as 0000:00:00 00:00:00
+func capturedAt(dateTime, offset tiffEntry) *time.Time {
+	value := dateTime.text()
+	if value == "" {
+		return nil
+	}
+
+	captured, err := time.Parse(exifTimeLayout+"-07:00", value+offset.text())
+	if err != nil {
+		if captured, err = time.Parse(exifTimeLayout, value); err != nil {
+			return nil
+		}
+	}
+	captured = captured.UTC()
+	return &captured
+}
+
+// gpsPosition converts the degrees, minutes and seconds of the GPS IFD, positions out of range are dropped
+func gpsPosition(gpsIfd map[uint16]tiffEntry) *GpsPosition {
+	latitude, ok := degrees(gpsIfd[gpsLatitudeTag], gpsIfd[gpsLatitudeRefTag], "S")
+	if !ok || math.Abs(latitude) > 90 {
+		return nil
+	}
+	longitude, ok := degrees(gpsIfd[gpsLongitudeTag], gpsIfd[gpsLongitudeRefTag], "W")
+	if !ok || math.Abs(longitude) > 180 {
+		return nil
+	}
+	return &GpsPosition{Latitude: latitude, Longitude: longitude}
+}
+
+func degrees(value, ref tiffEntry, negativeRef string) (float64, bool) {
+	var parts [3]float64
+	for i := range parts {
+		numerator, denominator, ok := value.fraction(i)
+		if !ok {
+			return 0, false
+		}
+		parts[i] = float64(numerator) / float64(denominator)
+	}
+
+	result := parts[0] + parts[1]/60 + parts[2]/3600
+	if strings.EqualFold(ref.text(), negativeRef) {
+		result = -result
+	}
+	return result, true
+}
@@ -0,0 +1,190 @@
+package content_test
+
+import (
+	"api/image"
+	"api/image/content"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// testTag is an entry of a test IFD, entries with a sub IFD point to the IFD at that index instead of holding a value
+type testTag struct {
+	Tag, Type uint16
+	Count     uint32
+	Value     []byte
+	SubIfd    int
+}
+
+// newTestTiff lays the IFDs out one after the other, each followed by the values that do not fit its entries
+func newTestTiff(ifds ...[]testTag) []byte {
+	offsets := make([]uint32, len(ifds))
+	offset := uint32(8)
+	for i, tags := range ifds {
+		offsets[i] = offset
+		offset += uint32(2 + 12*len(tags) + 4)
+		for _, tag := range tags {
+			if len(tag.Value) > 4 {
+				offset += uint32(len(tag.Value))
+			}
+		}
+	}
+
+	tiff := []byte{'I', 'I', 42, 0, 8, 0, 0, 0}
+	for i, tags := range ifds {
+		values := make([]byte, 0)
+		valuesOffset := offsets[i] + uint32(2+12*len(tags)+4)
+		tiff = append(tiff, byte(len(tags)), byte(len(tags)>>8))
+		for _, tag := range tags {
+			entry := make([]byte, 12)
+			binary.LittleEndian.PutUint16(entry, tag.Tag)
+			binary.LittleEndian.PutUint16(entry[2:], tag.Type)
+			binary.LittleEndian.PutUint32(entry[4:], tag.Count)
+			switch {
+			case tag.SubIfd > 0:
+				binary.LittleEndian.PutUint32(entry[8:], offsets[tag.SubIfd])
+			case len(tag.Value) > 4:
+				binary.LittleEndian.PutUint32(entry[8:], valuesOffset+uint32(len(values)))
+				values = append(values, tag.Value...)
+			default:
+				copy(entry[8:], tag.Value)
+			}
+			tiff = append(tiff, entry...)
+		}
+		tiff = append(tiff, 0, 0, 0, 0)
+		tiff = append(tiff, values...)
+	}
+	return tiff
+}
+
+func ascii(value string) testTag {
+	return testTag{Type: 2, Count: uint32(len(value) + 1), Value: append([]byte(value), 0)}
+}
+
+func rationals(values ...uint32) testTag {
+	data := make([]byte, 4*len(values))
+	for i, value := range values {
+		binary.LittleEndian.PutUint32(data[4*i:], value)
+	}
+	return testTag{Type: 5, Count: uint32(len(values) / 2), Value: data}
+}
+
+func withTag(tag uint16, value testTag) testTag {
+	value.Tag = tag
+	return value
+}
+
+func newTestExif() []byte {
+	return newTestTiff(
+		[]testTag{
+			withTag(0x010f, ascii("Canon")),
+			withTag(0x0110, ascii("Canon EOS R5  \x01")),
+			{Tag: 0x0112, Type: 3, Count: 1, Value: []byte{6, 0}},
+			{Tag: 0x8769, Type: 4, Count: 1, SubIfd: 1},
+			{Tag: 0x8825, Type: 4, Count: 1, SubIfd: 2},
+		},
+		[]testTag{
+			withTag(0x829a, rationals(10, 2500)),
+			withTag(0x829d, rationals(28, 10)),
+			{Tag: 0x8827, Type: 3, Count: 1, Value: []byte{0x90, 0x01}},
+			withTag(0x9003, ascii("2022:06:18 14:30:05")),
+			withTag(0x9011, ascii("+02:00")),
+			withTag(0x920a, rationals(50, 1)),
+			withTag(0xa434, ascii("RF24-105mm F4 L IS USM")),
+		},
+		[]testTag{
+			withTag(0x0001, ascii("N")),
+			withTag(0x0002, rationals(51, 1, 30, 1, 2612, 100)),
+			withTag(0x0003, ascii("W")),
+			withTag(0x0004, rationals(0, 1, 7, 1, 3960, 100)),
+		},
+	)
+}
+
+func TestReadExif(t *testing.T) {
+	values := []struct {
+		Format   image.Format
+		WithExif func(data, tiff []byte) []byte
+	}{
+		{Format: image.JpgFormat, WithExif: withJpegExif},
+		{Format: image.PngFormat, WithExif: withPngExif},
+		{Format: image.WebpFormat, WithExif: withWebpExif},
+	}
+
+	for _, data := range values {
+		t.Run(string(data.Format), func(t *testing.T) {
+			exif, err := content.ReadExif(data.WithExif(encodeTestImage(t, data.Format), newTestExif()), data.Format)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if exif.Make != "Canon" || exif.Model != "Canon EOS R5" || exif.LensModel != "RF24-105mm F4 L IS USM" {
+				t.Fatalf("Expected the camera and the lens, got %+v", exif)
+			}
+			if exif.ExposureTime != "1/250" || exif.FNumber != 2.8 || exif.FocalLength != 50 || exif.Iso != 400 {
+				t.Fatalf("Expected the exposure, got %+v", exif)
+			}
+			if exif.Orientation != 6 {
+				t.Fatalf("Expected orientation 6, got %d", exif.Orientation)
+			}
+			expectedCapture := time.Date(2022, 6, 18, 12, 30, 5, 0, time.UTC)
+			if exif.CapturedAt == nil || !exif.CapturedAt.Equal(expectedCapture) {
+				t.Fatalf("Expected capture at %s, got %v", expectedCapture, exif.CapturedAt)
+			}
+			if exif.Gps == nil || exif.Gps.Latitude < 51.5072 || exif.Gps.Latitude > 51.5073 ||
+				exif.Gps.Longitude > -0.1276 || exif.Gps.Longitude < -0.1277 {
+				t.Fatalf("Expected the position of London, got %+v", exif.Gps)
+			}
+		})
+	}
+}
+
+func TestReadExif_Empty(t *testing.T) {
+	exif, err := content.ReadExif(encodeTestImage(t, image.PngFormat), image.PngFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exif != (content.Exif{}) {
+		t.Fatalf("Expected no EXIF, got %+v", exif)
+	}
+
+	invalid := withJpegExif(encodeTestImage(t, image.JpgFormat), []byte("II*\x00\xff\xff\xff\xff"))
+	if _, err = content.ReadExif(invalid, image.JpgFormat); err == nil {
+		t.Fatal("Expected the unreadable EXIF to fail")
+	}
+}
+
+func TestReadExif_InvalidJpegSegment(t *testing.T) {
+	values := []struct {
+		Name    string
+		Segment []byte
+	}{
+		{Name: "Length 0", Segment: []byte{0xff, 0xe1, 0, 0}},
+		{Name: "Length 1", Segment: []byte{0xff, 0xe2, 0, 1}},
+		{Name: "Past the end", Segment: []byte{0xff, 0xe1, 0xff, 0xff, 'E', 'x', 'i', 'f', 0, 0}},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			jpg := withJpegSegmentAfterFrame(t, encodeTestImage(t, image.JpgFormat), data.Segment)
+			if _, err := content.ReadExif(jpg, image.JpgFormat); err == nil {
+				t.Fatal("Expected the invalid segment to fail")
+			}
+		})
+	}
+}
+
+func TestReadExif_UnsetClock(t *testing.T) {
+	tiff := newTestTiff(
+		[]testTag{{Tag: 0x8769, Type: 4, Count: 1, SubIfd: 1}},
+		[]testTag{withTag(0x9003, ascii("0000:00:00 00:00:00"))},
+	)
+
+	exif, err := content.ReadExif(withJpegExif(encodeTestImage(t, image.JpgFormat), tiff), image.JpgFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exif.CapturedAt != nil {
+		t.Fatalf("Expected no capture date, got %v", exif.CapturedAt)
+	}
+}
@@ -0,0 +1,249 @@
+package content
+
+import (
+	"api/image"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+)
+
+// gpsIfdTag is the EXIF tag pointing to the GPS IFD, the directory holding the location the image was taken at
+const gpsIfdTag = 0x8825
+
+var (
+	errInvalidExif = errors.New("invalid exif")
+	errInvalidJpeg = errors.New("invalid jpg segment")
+	exifHeader     = []byte("Exif\x00\x00")
+)
+
+// tiffTypeSizes are the sizes in bytes of the TIFF field types, by type
+var tiffTypeSizes = map[uint16]uint64{1: 1, 2: 1, 3: 2, 4: 4, 5: 8, 6: 1, 7: 1, 8: 2, 9: 4, 10: 8, 11: 4, 12: 8}
+
+// StripGps returns a copy of the data without the GPS IFD of its EXIF, the rest of the EXIF is kept. EXIF that can
+// not be read is removed as a whole, so that no location is left behind.
+func StripGps(data []byte, format image.Format) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+
+	switch format {
+	case image.JpgFormat:
+		return stripJpegGps(out)
+	case image.PngFormat:
+		return stripPngGps(out), nil
+	case image.WebpFormat:
+		return stripWebpGps(out), nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// jpegSegment is a marker segment of the jpg header starting at pos, its payload is data[start:end]
+type jpegSegment struct {
+	marker byte
+	pos    int
+	start  int
+	end    int
+}
+
+// nextJpegSegment returns the first segment with a payload at or after pos, ok is false once the image data or the
+// end of the header is reached. A length that does not fit its segment or the data is an error, so that the
+// payload can be sliced safely.
+func nextJpegSegment(data []byte, pos int) (segment jpegSegment, ok bool, err error) {
+	for pos+4 <= len(data) && data[pos] == 0xff {
+		marker := data[pos+1]
+		switch {
+		case marker == 0xff:
+			pos++
+			continue
+		case marker == 0xda || marker == 0xd9:
+			// Start of scan and end of image, no metadata follows
+			return jpegSegment{}, false, nil
+		case marker == 0x01 || (marker >= 0xd0 && marker <= 0xd7):
+			pos += 2
+			continue
+		}
+
+		// The length counts its own two bytes but not the marker
+		length := int(binary.BigEndian.Uint16(data[pos+2:]))
+		end := pos + 2 + length
+		if length < 2 || end > len(data) {
+			return jpegSegment{}, false, fmt.Errorf("%w: %#x of length %d at %d", errInvalidJpeg, marker, length, pos)
+		}
+		return jpegSegment{marker: marker, pos: pos, start: pos + 4, end: end}, true, nil
+	}
+	return jpegSegment{}, false, nil
+}
+
+// stripJpegGps walks the segments up to the image data looking for the APP1 EXIF segments
+func stripJpegGps(data []byte) ([]byte, error) {
+	pos := 2
+	for {
+		segment, ok, err := nextJpegSegment(data, pos)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			return data, nil
+		}
+
+		payload := data[segment.start:segment.end]
+		if segment.marker == 0xe1 && bytes.HasPrefix(payload, exifHeader) {
+			if err = stripTiffGps(payload[len(exifHeader):]); err != nil {
+				data = append(data[:segment.pos], data[segment.end:]...)
+				pos = segment.pos
+				continue
+			}
+		}
+		pos = segment.end
+	}
+}
+
+// stripPngGps walks the chunks looking for eXIf, whose checksum is updated once stripped
+func stripPngGps(data []byte) []byte {
+	pos := len(pngMagic)
+	for pos+12 <= len(data) {
+		length := int(binary.BigEndian.Uint32(data[pos:]))
+		end := pos + 12 + length
+		if length < 0 || end > len(data) {
+			return data
+		}
+		chunkType := string(data[pos+4 : pos+8])
+		if chunkType == "IEND" {
+			return data
+		}
+		if chunkType == "eXIf" {
+			if err := stripTiffGps(data[pos+8 : pos+8+length]); err != nil {
+				data = append(data[:pos], data[end:]...)
+				continue
+			}
+			binary.BigEndian.PutUint32(data[pos+8+length:], crc32.ChecksumIEEE(data[pos+4:pos+8+length]))
+		}
+		pos = end
+	}
+	return data
+}
+
+// stripWebpGps walks the RIFF chunks looking for EXIF, a removed chunk is also cleared from the VP8X flags
+func stripWebpGps(data []byte) []byte {
+	pos := 12
+	for pos+8 <= len(data) {
+		size := int(binary.LittleEndian.Uint32(data[pos+4:]))
+		end := pos + 8 + size + size%2
+		if size < 0 || pos+8+size > len(data) {
+			return data
+		}
+		if end > len(data) {
+			end = len(data)
+		}
+		if string(data[pos:pos+4]) == "EXIF" {
+			tiff := bytes.TrimPrefix(data[pos+8:pos+8+size], exifHeader)
+			if err := stripTiffGps(tiff); err != nil {
+				data = append(data[:pos], data[end:]...)
+				binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+				if len(data) > 20 && string(data[12:16]) == "VP8X" {
+					data[20] &^= 0x08
+				}
+				continue
+			}
+		}
+		pos = end
+	}
+	return data
+}
+
+// stripTiffGps zeroes the GPS IFD with the values it points to and removes its entry from IFD0, in place so that
+// the offsets of everything else stay valid
+func stripTiffGps(tiff []byte) error {
+	order, err := tiffByteOrder(tiff)
+	if err != nil {
+		return err
+	}
+
+	ifd := uint64(order.Uint32(tiff[4:]))
+	count, err := ifdEntries(tiff, order, ifd)
+	if err != nil {
+		return err
+	}
+	ifdEnd := ifd + 2 + 12*count + 4
+	if ifdEnd > uint64(len(tiff)) {
+		return errInvalidExif
+	}
+
+	for i := uint64(0); i < count; i++ {
+		entry := ifd + 2 + 12*i
+		if order.Uint16(tiff[entry:]) != gpsIfdTag {
+			continue
+		}
+		if err = zeroIfd(tiff, order, uint64(order.Uint32(tiff[entry+8:]))); err != nil {
+			return err
+		}
+
+		// The following entries and the offset of the next IFD move up in place of the GPS entry
+		copy(tiff[entry:], tiff[entry+12:ifdEnd])
+		for j := ifdEnd - 12; j < ifdEnd; j++ {
+			tiff[j] = 0
+		}
+		order.PutUint16(tiff[ifd:], uint16(count-1))
+		return nil
+	}
+
+	return nil
+}
+
+// tiffByteOrder reads the byte order the TIFF header declares for the rest of the EXIF
+func tiffByteOrder(tiff []byte) (binary.ByteOrder, error) {
+	if len(tiff) < 8 {
+		return nil, errInvalidExif
+	}
+	switch string(tiff[:2]) {
+	case "II":
+		return binary.LittleEndian, nil
+	case "MM":
+		return binary.BigEndian, nil
+	}
+	return nil, errInvalidExif
+}
+
+func ifdEntries(tiff []byte, order binary.ByteOrder, ifd uint64) (uint64, error) {
+	if ifd+2 > uint64(len(tiff)) {
+		return 0, errInvalidExif
+	}
+	count := uint64(order.Uint16(tiff[ifd:]))
+	if ifd+2+12*count > uint64(len(tiff)) {
+		return 0, errInvalidExif
+	}
+	return count, nil
+}
+
+// zeroIfd zeroes the entries of the IFD and the values stored outside of them
+func zeroIfd(tiff []byte, order binary.ByteOrder, ifd uint64) error {
+	count, err := ifdEntries(tiff, order, ifd)
+	if err != nil {
+		return err
+	}
+
+	for i := uint64(0); i < count; i++ {
+		entry := ifd + 2 + 12*i
+		size := tiffTypeSizes[order.Uint16(tiff[entry+2:])] * uint64(order.Uint32(tiff[entry+4:]))
+		if size <= 4 {
+			continue
+		}
+		offset := uint64(order.Uint32(tiff[entry+8:]))
+		if offset+size > uint64(len(tiff)) {
+			return errInvalidExif
+		}
+		for j := offset; j < offset+size; j++ {
+			tiff[j] = 0
+		}
+	}
+
+	end := ifd + 2 + 12*count + 4
+	if end > uint64(len(tiff)) {
+		end = uint64(len(tiff))
+	}
+	for j := ifd; j < end; j++ {
+		tiff[j] = 0
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+// Package local resizes the images in the process of the API with the pipeline instead of the remote images API,
+// keeping the files in a Store on the local disk or in an S3 compatible bucket
+package local
+
+import (
+	"api/image"
+	"api/image/content"
+	"api/image/pipeline"
+	"context"
+	"fmt"
+	"github.com/rs/xid"
+	"github.com/rs/zerolog"
+	"mime/multipart"
+	"path"
+	"strings"
+)
+
+const uploadsPath = "uploads"
+
+// Resizer is an image.Resizer storing the uploads in the store as they are, there is no signing so the signed url
+// of an upload is its key
+type Resizer struct {
+	store     Store
+	pipeline  *pipeline.Pipeline
+	domain    string
+	maxPixels int
+	logger    *zerolog.Logger
+}
+
+// NewResizer stores the images in the store resized to the breakpoints, domain is where the store is served from.
+// Uploads of more than maxPixels are refused.
+func NewResizer(
+	store Store, domain string, breakpoints pipeline.Breakpoints, maxPixels int, logger *zerolog.Logger,
+) *Resizer {
+	return &Resizer{
+		store:     store,
+		pipeline:  pipeline.New(domain, breakpoints, maxPixels),
+		domain:    domain,
+		maxPixels: maxPixels,
+		logger:    logger,
+	}
+}
+
+// formatOf returns the format of the file from the extension of its key
+func formatOf(key string) (image.Format, error) {
+	format := image.Format(strings.TrimPrefix(path.Ext(key), "."))
+	if !format.IsSupported() {
+		return "", fmt.Errorf("unsupported format of file %s", key)
+	}
+	return format, nil
+}
+
+func (resizer *Resizer) FetchSignedUrl(
+	_ context.Context, _ string, format image.Format,
+) (image.SignedResponse, error) {
+	key := fmt.Sprintf("%s/%s.%s", uploadsPath, xid.New(), format)
+	return image.SignedResponse{SignedUrl: key, FileName: key}, nil
+}
+
+func (resizer *Resizer) UploadFile(
+	ctx context.Context, signedUrl string, format image.Format, fileHeader *multipart.FileHeader,
+) error {
+	data, err := content.ReadFile(fileHeader, format, resizer.maxPixels)
+	if err != nil {
+		return err
+	}
+
+	return resizer.store.Put(ctx, signedUrl, format.ToContentType(), data)
+}
+
+// Resize stores the cropped file in every size narrower than it and the original file next to them, all in the
+// format of the upload. The uploads are removed once stored.
+func (resizer *Resizer) Resize(
+	ctx context.Context, _ string, request image.ResizeRequest,
+) (image.ResizeResponse, error) {
+	format, err := formatOf(request.FilePath)
+	if err != nil {
+		return image.ResizeResponse{}, err
+	}
+
+	cropped, err := resizer.store.Get(ctx, request.FilePath)
+	if err != nil {
+		return image.ResizeResponse{}, fmt.Errorf("failed reading upload %s: %w", request.FilePath, err)
+	}
+	original, err := resizer.store.Get(ctx, request.OriginalFilePath)
+	if err != nil {
+		return image.ResizeResponse{}, fmt.Errorf("failed reading upload %s: %w", request.OriginalFilePath, err)
+	}
+
+	res, files, err := resizer.pipeline.Resize(request.Name, format, cropped, original)
+	if err != nil {
+		return image.ResizeResponse{}, err
+	}
+	for _, file := range files {
+		if err = resizer.store.Put(ctx, file.Key, file.ContentType, file.Data); err != nil {
+			return image.ResizeResponse{}, err
+		}
+	}
+
+	for _, upload := range []string{request.FilePath, request.OriginalFilePath} {
+		if err = resizer.store.Delete(ctx, upload); err != nil {
+			resizer.logger.Warn().Err(err).Msgf("failed removing upload %s", upload)
+		}
+	}
+
+	return res, nil
+}
+
+// Rename moves every size and the original of the image to the new name
+func (resizer *Resizer) Rename(
+	ctx context.Context, _ string, request image.RenameRequest,
+) (image.ResizeResponse, error) {
+	for _, dimensions := range request.SizeMap.GetAllDimensions() {
+		err := resizer.move(
+			ctx,
+			pipeline.SizeKey(request.Name, request.Format, dimensions),
+			pipeline.SizeKey(request.NewName, request.Format, dimensions),
+			request.Format,
+		)
+		if err != nil {
+			return image.ResizeResponse{}, err
+		}
+	}
+
+	original := pipeline.OriginalKey(request.NewName, request.Format)
+	err := resizer.move(ctx, pipeline.OriginalKey(request.Name, request.Format), original, request.Format)
+	if err != nil {
+		return image.ResizeResponse{}, err
+	}
+
+	return image.ResizeResponse{
+		Format:   request.Format,
+		Original: original,
+		Name:     request.NewName,
+		Domain:   resizer.domain,
+		Path:     pipeline.Path,
+		Sizes:    request.SizeMap,
+	}, nil
+}
+
+func (resizer *Resizer) move(ctx context.Context, from, to string, format image.Format) error {
+	data, err := resizer.store.Get(ctx, from)
+	if err != nil {
+		return fmt.Errorf("failed reading %s: %w", from, err)
+	}
+	if err = resizer.store.Put(ctx, to, format.ToContentType(), data); err != nil {
+		return err
+	}
+	return resizer.store.Delete(ctx, from)
+}
+
+// Invalidate does nothing since there is no CDN in front of the store
+func (resizer *Resizer) Invalidate(_ context.Context, _ string, _ image.DeleteRequest) error {
+	return nil
+}
+
+func (resizer *Resizer) Delete(ctx context.Context, _ string, request image.DeleteRequest) error {
+	for _, dimensions := range request.Dimensions {
+		if err := resizer.store.Delete(ctx, pipeline.SizeKey(request.Name, request.Format, dimensions)); err != nil {
+			return err
+		}
+	}
+	return resizer.store.Delete(ctx, pipeline.OriginalKey(request.Name, request.Format))
+}
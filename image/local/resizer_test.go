@@ -0,0 +1,123 @@
+package local
+
+import (
+	"api/image"
+	"api/image/pipeline"
+	"api/logger"
+	"bytes"
+	"context"
+	"errors"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func newTestResizer(t *testing.T) (*Resizer, *FileStore) {
+	store := NewFileStore(t.TempDir())
+	return NewResizer(store, "http://localhost:3000/files", pipeline.DefaultBreakpoints(), 0, logger.NewLogger()), store
+}
+
+// putUpload stores a png of the dimensions like UploadFile would
+func putUpload(t *testing.T, resizer *Resizer, store *FileStore, width, height int) string {
+	img := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, x*height/width, color.RGBA{R: 200, A: 255})
+	}
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		t.Fatal(err)
+	}
+
+	signed, err := resizer.FetchSignedUrl(context.Background(), "", image.PngFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = store.Put(context.Background(), signed.SignedUrl, image.PngType, buffer.Bytes()); err != nil {
+		t.Fatal(err)
+	}
+	return signed.FileName
+}
+
+func TestResizer_Resize(t *testing.T) {
+	ctx := context.Background()
+	resizer, store := newTestResizer(t)
+	cropped := putUpload(t, resizer, store, 688, 516)
+	original := putUpload(t, resizer, store, 1000, 800)
+
+	res, err := resizer.Resize(ctx, "", image.ResizeRequest{
+		Name: "my-plane", FilePath: cropped, OriginalFilePath: original,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := image.Sizes{
+		Original: image.Dimensions{Width: 688, Height: 516},
+		Xs:       &image.Dimensions{Width: 100, Height: 75},
+		S:        &image.Dimensions{Width: 300, Height: 225},
+		M:        &image.Dimensions{Width: 500, Height: 375},
+	}
+	if !res.Sizes.IsEqualTo(expected) {
+		t.Fatalf("Expected %s, got %s", expected.ToString(), res.Sizes.ToString())
+	}
+	if res.Original != "images/my-plane.png" || res.Format != image.PngFormat || res.Path != "images" {
+		t.Fatalf("Expected my-plane stored as png in images, got %+v", res)
+	}
+
+	for _, key := range []string{"images/my-plane.png", "images/my-plane-688x516.png", "images/my-plane-100x75.png"} {
+		if _, err = store.Get(ctx, key); err != nil {
+			t.Fatalf("Expected %s to be stored, got %v", key, err)
+		}
+	}
+	if _, err = store.Get(ctx, cropped); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected upload %s to be removed, got %v", cropped, err)
+	}
+}
+
+func TestResizer_RenameAndDelete(t *testing.T) {
+	ctx := context.Background()
+	resizer, store := newTestResizer(t)
+	cropped := putUpload(t, resizer, store, 200, 100)
+	original := putUpload(t, resizer, store, 200, 100)
+
+	res, err := resizer.Resize(ctx, "", image.ResizeRequest{
+		Name: "my-plane", FilePath: cropped, OriginalFilePath: original,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := resizer.Rename(ctx, "", image.RenameRequest{
+		Name: "my-plane", NewName: "my-new-plane", Format: res.Format, SizeMap: res.Sizes,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Original != "images/my-new-plane.png" || !renamed.Sizes.IsEqualTo(res.Sizes) {
+		t.Fatalf("Expected sizes moved to my-new-plane, got %+v", renamed)
+	}
+	if _, err = store.Get(ctx, "images/my-plane-100x50.png"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected old name to be moved, got %v", err)
+	}
+
+	err = resizer.Delete(ctx, "", image.DeleteRequest{
+		Name: "my-new-plane", Format: renamed.Format, Dimensions: renamed.Sizes.GetAllDimensions(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted := []string{"images/my-new-plane.png", "images/my-new-plane-200x100.png", "images/my-new-plane-100x50.png"}
+	for _, key := range deleted {
+		if _, err = store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("Expected %s to be deleted, got %v", key, err)
+		}
+	}
+}
+
+func TestFileStore_KeysStayInDirectory(t *testing.T) {
+	store := NewFileStore("/var/data")
+	if filePath := store.filePath("../../etc/passwd"); filePath != "/var/data/etc/passwd" {
+		t.Fatalf("Expected key resolved inside the directory, got %s", filePath)
+	}
+}
@@ -0,0 +1,89 @@
+package local
+
+import (
+	"api/image"
+	"bytes"
+	"context"
+	"errors"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"io/ioutil"
+)
+
+// S3Store keeps the files in a bucket of S3 or of a compatible service like MinIO
+type S3Store struct {
+	client *s3.S3
+	bucket string
+}
+
+// S3Options locate the bucket, the credentials of the rest of the AWS services are used unless the access key is set
+type S3Options struct {
+	Endpoint        string
+	Region          string
+	Bucket          string
+	AccessKeyId     string
+	SecretAccessKey string
+}
+
+// NewS3Store connects to the endpoint when given, otherwise to S3 of the region. Compatible services are addressed
+// by path since they rarely resolve buckets as subdomains.
+func NewS3Store(options S3Options) (*S3Store, error) {
+	config := aws.Config{Region: aws.String(options.Region)}
+	if options.Endpoint != "" {
+		config.Endpoint = aws.String(options.Endpoint)
+		config.S3ForcePathStyle = aws.Bool(true)
+	}
+	if options.AccessKeyId != "" {
+		config.Credentials = credentials.NewStaticCredentials(options.AccessKeyId, options.SecretAccessKey, "")
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &S3Store{client: s3.New(sess), bucket: options.Bucket}, nil
+}
+
+func (store *S3Store) Put(ctx context.Context, key string, contentType image.ContentType, data []byte) error {
+	_, err := store.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(store.bucket),
+		Key:         aws.String(key),
+		Body:        bytes.NewReader(data),
+		ContentType: aws.String(string(contentType)),
+	})
+	return err
+}
+
+func (store *S3Store) Get(ctx context.Context, key string) ([]byte, error) {
+	output, err := store.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(store.bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		var awsErr awserr.Error
+		if errors.As(err, &awsErr) && awsErr.Code() == s3.ErrCodeNoSuchKey {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	defer func() {
+		_ = output.Body.Close()
+	}()
+
+	return ioutil.ReadAll(output.Body)
+}
+
+func (store *S3Store) Delete(ctx context.Context, key string) error {
+	_, err := store.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(store.bucket),
+		Key:    aws.String(key),
+	})
+	return err
+}
@@ -0,0 +1,60 @@
+package local
+
+import (
+	"api/image"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// ErrNotFound is returned by a Store when there is no file under the key
+var ErrNotFound = errors.New("file not found")
+
+// Store keeps the files of the images under slash separated keys, like images/my-plane-300x200.png
+type Store interface {
+	Put(ctx context.Context, key string, contentType image.ContentType, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Delete removes the file, missing files are not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// FileStore keeps the files in a directory of the local disk
+type FileStore struct {
+	dir string
+}
+
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// filePath resolves the key inside the directory, keys can not escape it
+func (store *FileStore) filePath(key string) string {
+	return filepath.Join(store.dir, filepath.FromSlash(path.Clean("/"+key)))
+}
+
+func (store *FileStore) Put(_ context.Context, key string, _ image.ContentType, data []byte) error {
+	filePath := store.filePath(key)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(filePath, data, 0644)
+}
+
+func (store *FileStore) Get(_ context.Context, key string) ([]byte, error) {
+	data, err := os.ReadFile(store.filePath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+func (store *FileStore) Delete(_ context.Context, key string) error {
+	err := os.Remove(store.filePath(key))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,238 @@
+// Package pipeline resizes the images in the process of the API, it decodes jpg, png and webp files and produces
+// every breakpoint of image.Sizes narrower than the image, encoded in the requested format
+package pipeline
+
+import (
+	"api/image"
+	"api/image/content"
+	"bytes"
+	"fmt"
+	"golang.org/x/image/draw"
+	stdimage "image"
+	"image/jpeg"
+	"image/png"
+	"math"
+)
+
+const (
+	// Path is where the resized files are kept, relative to the domain they are served from
+	Path        = "images"
+	jpegQuality = 85
+)
+
+// Breakpoints are the widths of the image.Sizes buckets, a bucket is only produced for images wider than it and a
+// width of 0 disables it
+type Breakpoints struct {
+	Xs   int
+	S    int
+	M    int
+	L    int
+	XL   int
+	XXL  int
+	XXXL int
+}
+
+func DefaultBreakpoints() Breakpoints {
+	return Breakpoints{Xs: 100, S: 300, M: 500, L: 800, XL: 1200, XXL: 1600, XXXL: 2000}
+}
+
+// NewBreakpoints takes the widths from xs up to xxxl
+func NewBreakpoints(widths []int) (Breakpoints, error) {
+	if len(widths) != 7 {
+		return Breakpoints{}, fmt.Errorf("expected 7 breakpoint widths, got %d", len(widths))
+	}
+	breakpoints := Breakpoints{
+		Xs: widths[0], S: widths[1], M: widths[2], L: widths[3], XL: widths[4], XXL: widths[5], XXXL: widths[6],
+	}
+	return breakpoints, breakpoints.Validate()
+}
+
+// Validate checks that the enabled widths grow from xs up to xxxl
+func (breakpoints Breakpoints) Validate() error {
+	previous := 0
+	for _, bucket := range breakpoints.buckets(&image.Sizes{}) {
+		if bucket.width < 0 {
+			return fmt.Errorf("breakpoint width %d must not be negative", bucket.width)
+		}
+		if bucket.width == 0 {
+			continue
+		}
+		if bucket.width <= previous {
+			return fmt.Errorf("breakpoint width %d must be greater than %d", bucket.width, previous)
+		}
+		previous = bucket.width
+	}
+	return nil
+}
+
+type bucket struct {
+	width      int
+	dimensions **image.Dimensions
+}
+
+func (breakpoints Breakpoints) buckets(sizes *image.Sizes) []bucket {
+	return []bucket{
+		{width: breakpoints.Xs, dimensions: &sizes.Xs},
+		{width: breakpoints.S, dimensions: &sizes.S},
+		{width: breakpoints.M, dimensions: &sizes.M},
+		{width: breakpoints.L, dimensions: &sizes.L},
+		{width: breakpoints.XL, dimensions: &sizes.XL},
+		{width: breakpoints.XXL, dimensions: &sizes.XXL},
+		{width: breakpoints.XXXL, dimensions: &sizes.XXXL},
+	}
+}
+
+// File is an encoded output of the pipeline, to be stored under its key
+type File struct {
+	Key         string
+	ContentType image.ContentType
+	Data        []byte
+}
+
+func OriginalKey(name string, format image.Format) string {
+	return fmt.Sprintf("%s/%s.%s", Path, name, format)
+}
+
+func SizeKey(name string, format image.Format, dimensions image.Dimensions) string {
+	return fmt.Sprintf("%s/%s-%dx%d.%s", Path, name, dimensions.Width, dimensions.Height, format)
+}
+
+type Pipeline struct {
+	domain      string
+	breakpoints Breakpoints
+	maxPixels   int
+}
+
+// New produces the breakpoints, domain is where the files are served from once stored. Images of more than
+// maxPixels are not decoded, 0 decodes any.
+func New(domain string, breakpoints Breakpoints, maxPixels int) *Pipeline {
+	return &Pipeline{domain: domain, breakpoints: breakpoints, maxPixels: maxPixels}
+}
+
+// Resize encodes the cropped image in full size and in every breakpoint narrower than it, keeping its aspect ratio,
+// together with the original. The original is kept as it is when it already has the format.
+func (pipeline *Pipeline) Resize(
+	name string, format image.Format, cropped, original []byte,
+) (image.ResizeResponse, []File, error) {
+	if !format.IsSupported() {
+		return image.ResizeResponse{}, nil, fmt.Errorf("unsupported format %s", format)
+	}
+
+	img, _, err := pipeline.decode(cropped)
+	if err != nil {
+		return image.ResizeResponse{}, nil, err
+	}
+
+	sizes := image.Sizes{Original: dimensionsOf(img)}
+	full, err := encodeFile(SizeKey(name, format, sizes.Original), img, format)
+	if err != nil {
+		return image.ResizeResponse{}, nil, err
+	}
+	files := []File{full}
+
+	for _, bucket := range pipeline.breakpoints.buckets(&sizes) {
+		if bucket.width == 0 {
+			continue
+		}
+		if bucket.width >= sizes.Original.Width {
+			break
+		}
+
+		scaled := scale(img, bucket.width)
+		dimensions := dimensionsOf(scaled)
+		file, err := encodeFile(SizeKey(name, format, dimensions), scaled, format)
+		if err != nil {
+			return image.ResizeResponse{}, nil, err
+		}
+		files = append(files, file)
+		*bucket.dimensions = &dimensions
+	}
+
+	originalFile, err := pipeline.encodeOriginal(OriginalKey(name, format), original, format)
+	if err != nil {
+		return image.ResizeResponse{}, nil, err
+	}
+	files = append(files, originalFile)
+
+	return image.ResizeResponse{
+		Format:   format,
+		Original: originalFile.Key,
+		Name:     name,
+		Domain:   pipeline.domain,
+		Path:     Path,
+		Sizes:    sizes,
+	}, files, nil
+}
+
+func (pipeline *Pipeline) encodeOriginal(key string, data []byte, format image.Format) (File, error) {
+	img, decodedFormat, err := pipeline.decode(data)
+	if err != nil {
+		return File{}, err
+	}
+	if decodedFormat == format {
+		return File{Key: key, ContentType: format.ToContentType(), Data: data}, nil
+	}
+	return encodeFile(key, img, format)
+}
+
+func encodeFile(key string, img stdimage.Image, format image.Format) (File, error) {
+	data, err := Encode(img, format)
+	if err != nil {
+		return File{}, err
+	}
+	return File{Key: key, ContentType: format.ToContentType(), Data: data}, nil
+}
+
+// decode returns the image together with the format it was in, its header is checked first so that the pixels
+// are only decoded when within the limit
+func (pipeline *Pipeline) decode(data []byte) (stdimage.Image, image.Format, error) {
+	format, dimensions, err := content.Inspect(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed decoding image: %w", err)
+	}
+	if err = content.CheckPixels(dimensions, pipeline.maxPixels); err != nil {
+		return nil, "", err
+	}
+
+	img, _, err := stdimage.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed decoding image: %w", err)
+	}
+	return img, format, nil
+}
+
+func Encode(img stdimage.Image, format image.Format) ([]byte, error) {
+	var buffer bytes.Buffer
+	var err error
+	switch format {
+	case image.JpgFormat:
+		err = jpeg.Encode(&buffer, img, &jpeg.Options{Quality: jpegQuality})
+	case image.PngFormat:
+		err = png.Encode(&buffer, img)
+	case image.WebpFormat:
+		err = encodeWebp(&buffer, img)
+	default:
+		return nil, fmt.Errorf("unsupported format %s", format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed encoding image as %s: %w", format, err)
+	}
+	return buffer.Bytes(), nil
+}
+
+// scale resizes the image to the width keeping its aspect ratio
+func scale(img stdimage.Image, width int) stdimage.Image {
+	bounds := img.Bounds()
+	height := int(math.Round(float64(bounds.Dy()) * float64(width) / float64(bounds.Dx())))
+	if height < 1 {
+		height = 1
+	}
+
+	scaled := stdimage.NewRGBA(stdimage.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(scaled, scaled.Bounds(), img, bounds, draw.Src, nil)
+	return scaled
+}
+
+func dimensionsOf(img stdimage.Image) image.Dimensions {
+	return image.Dimensions{Width: img.Bounds().Dx(), Height: img.Bounds().Dy()}
+}
@@ -0,0 +1,270 @@
+package pipeline
+
+import (
+	"api/image"
+	"bytes"
+	"flag"
+	"golang.org/x/image/webp"
+	stdimage "image"
+	"image/color"
+	"image/png"
+	"io/ioutil"
+	"math/rand"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// newTestImage draws a gradient with a diagonal, so that scaling and encoding artifacts show up in the goldens
+func newTestImage(width, height int) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			img.Set(x, y, color.NRGBA{R: uint8(x * 255 / width), G: uint8(y * 255 / height), B: 128, A: 255})
+		}
+	}
+	for x := 0; x < width; x++ {
+		img.Set(x, x*height/width, color.NRGBA{R: 255, G: 255, B: 255, A: 255})
+	}
+	return img
+}
+
+func encodePng(t *testing.T, img stdimage.Image) []byte {
+	var buffer bytes.Buffer
+	if err := png.Encode(&buffer, img); err != nil {
+		t.Fatal(err)
+	}
+	return buffer.Bytes()
+}
+
+func TestPipeline_Resize(t *testing.T) {
+	source := encodePng(t, newTestImage(400, 300))
+	pipeline := New("http://localhost:3000/files", Breakpoints{Xs: 40, S: 120, M: 250, L: 400}, 0)
+
+	expectedSizes := image.Sizes{
+		Original: image.Dimensions{Width: 400, Height: 300},
+		Xs:       &image.Dimensions{Width: 40, Height: 30},
+		S:        &image.Dimensions{Width: 120, Height: 90},
+		M:        &image.Dimensions{Width: 250, Height: 188},
+	}
+
+	for _, format := range image.SupportedFormats {
+		t.Run(string(format), func(t *testing.T) {
+			res, files, err := pipeline.Resize("my-plane", format, source, source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !res.Sizes.IsEqualTo(expectedSizes) {
+				t.Fatalf("Expected %s, got %s", expectedSizes.ToString(), res.Sizes.ToString())
+			}
+			if res.Format != format || res.Original != OriginalKey("my-plane", format) || res.Path != Path {
+				t.Fatalf("Expected my-plane stored as %s in %s, got %+v", format, Path, res)
+			}
+			if len(files) != 5 {
+				t.Fatalf("Expected 4 sizes and the original, got %d files", len(files))
+			}
+
+			for _, file := range files {
+				if file.ContentType != format.ToContentType() {
+					t.Fatalf("Expected %s of %s, got %s", format.ToContentType(), file.Key, file.ContentType)
+				}
+				decoded, decodedFormat, err := pipeline.decode(file.Data)
+				if err != nil {
+					t.Fatalf("Expected %s to decode, got %v", file.Key, err)
+				}
+				if decodedFormat != format {
+					t.Fatalf("Expected %s encoded as %s, got %s", file.Key, format, decodedFormat)
+				}
+
+				golden := filepath.Join("testdata", path.Base(file.Key))
+				if *update {
+					if err = ioutil.WriteFile(golden, file.Data, 0644); err != nil {
+						t.Fatal(err)
+					}
+					continue
+				}
+				expected, err := ioutil.ReadFile(golden)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !bytes.Equal(expected, file.Data) {
+					t.Fatalf("Expected %s to match %s, run the tests with -update if the change is intended", file.Key, golden)
+				}
+				if file.Key != res.Original && !containsDimensions(res.Sizes, dimensionsOf(decoded)) {
+					t.Fatalf("Expected dimensions of %s among %s", file.Key, res.Sizes.ToString())
+				}
+			}
+		})
+	}
+}
+
+func containsDimensions(sizes image.Sizes, dimensions image.Dimensions) bool {
+	for _, size := range sizes.GetAllDimensions() {
+		if size == dimensions {
+			return true
+		}
+	}
+	return false
+}
+
+func TestPipeline_ResizeKeepsOriginal(t *testing.T) {
+	source := encodePng(t, newTestImage(60, 40))
+
+	_, files, err := New("", DefaultBreakpoints(), 0).Resize("my-plane", image.PngFormat, source, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 {
+		t.Fatalf("Expected only the full size and the original of an image narrower than xs, got %d", len(files))
+	}
+	if !bytes.Equal(files[1].Data, source) {
+		t.Fatal("Expected the original in the same format to be kept as it is")
+	}
+}
+
+func TestEncode_WebpIsLossless(t *testing.T) {
+	translucent := newTestImage(97, 31)
+	translucent.Set(3, 4, color.NRGBA{R: 10, G: 20, B: 30, A: 40})
+
+	// Repeated stripes are written as backward references, narrow ones reach the same pixels through several codes
+	striped := func(width, height int) *stdimage.NRGBA {
+		img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				img.Set(x, y, color.NRGBA{R: uint8(x % 7 * 30), G: uint8(y % 5 * 40), B: uint8((x + y) % 3), A: 255})
+			}
+		}
+		return img
+	}
+
+	// Every pixel fades from opaque to transparent, the transparent ones keeping their colour
+	faded := func(width, height int) *stdimage.NRGBA {
+		img := newTestImage(width, height)
+		for i := 3; i < len(img.Pix); i += 4 {
+			img.Pix[i] = uint8(i / 4 % 256)
+		}
+		return img
+	}
+
+	// Noise has far more colours than a palette of 256 and leaves nothing to predict or repeat
+	noise := func(width, height int) *stdimage.NRGBA {
+		img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+		rand.New(rand.NewSource(1)).Read(img.Pix)
+		return img
+	}
+
+	// Every colour of red and green once
+	palette := stdimage.NewNRGBA(stdimage.Rect(0, 0, 256, 256))
+	for y := 0; y < 256; y++ {
+		for x := 0; x < 256; x++ {
+			palette.Set(x, y, color.NRGBA{R: uint8(x), G: uint8(y), B: uint8(x ^ y), A: 255})
+		}
+	}
+
+	values := []struct {
+		Name   string
+		Source *stdimage.NRGBA
+	}{
+		{Name: "Translucent gradient", Source: translucent},
+		{Name: "Stripes", Source: striped(300, 200)},
+		{Name: "Narrow stripes", Source: striped(3, 90)},
+		{Name: "Single pixel", Source: striped(1, 1)},
+		{Name: "Single row", Source: striped(97, 1)},
+		{Name: "Single column", Source: striped(1, 97)},
+		{Name: "Odd tiles", Source: newTestImage(33, 65)},
+		{Name: "Faded", Source: faded(45, 67)},
+		{Name: "Transparent", Source: stdimage.NewNRGBA(stdimage.Rect(0, 0, 13, 7))},
+		{Name: "Noise", Source: noise(71, 53)},
+		{Name: "Large palette", Source: palette},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			source := data.Source
+			encoded, err := Encode(source, image.WebpFormat)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoded, err := webp.Decode(bytes.NewReader(encoded))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.Bounds() != source.Bounds() {
+				t.Fatalf("Expected bounds %v, got %v", source.Bounds(), decoded.Bounds())
+			}
+			for y := 0; y < source.Bounds().Dy(); y++ {
+				for x := 0; x < source.Bounds().Dx(); x++ {
+					expected := source.NRGBAAt(x, y)
+					if actual := color.NRGBAModel.Convert(decoded.At(x, y)); actual != expected {
+						t.Fatalf("Expected %v at %d,%d, got %v", expected, x, y, actual)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncode_WebpMaxPixels(t *testing.T) {
+	if _, err := Encode(stdimage.NewNRGBA(stdimage.Rect(0, 0, 2049, 2048)), image.WebpFormat); err == nil {
+		t.Fatalf("Expected webp of more than %d pixels to be refused", WebpMaxPixels)
+	}
+}
+
+func TestSearchChainLength(t *testing.T) {
+	values := []struct {
+		Pixels   int
+		Expected int
+	}{
+		{Pixels: 1, Expected: lz77ChainLength},
+		{Pixels: 400 * 300, Expected: lz77ChainLength},
+		{Pixels: 2000 * 1500, Expected: 5},
+		{Pixels: lz77SearchBudget * 2, Expected: 1},
+	}
+
+	for _, data := range values {
+		if actual := searchChainLength(data.Pixels); actual != data.Expected {
+			t.Fatalf("Expected a chain of %d for %d pixels, got %d", data.Expected, data.Pixels, actual)
+		}
+	}
+}
+
+func TestEncode_WebpSmallerThanPng(t *testing.T) {
+	source := newTestImage(400, 300)
+
+	webpData, err := Encode(source, image.WebpFormat)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pngData := encodePng(t, source); len(webpData) > len(pngData) {
+		t.Fatalf("Expected webp of at most %d bytes as png, got %d", len(pngData), len(webpData))
+	}
+}
+
+func TestNewBreakpoints(t *testing.T) {
+	values := []struct {
+		Name    string
+		Widths  []int
+		IsValid bool
+	}{
+		{Name: "Default", Widths: []int{100, 300, 500, 800, 1200, 1600, 2000}, IsValid: true},
+		{Name: "Disabled", Widths: []int{100, 0, 500, 0, 0, 0, 2000}, IsValid: true},
+		{Name: "Not growing", Widths: []int{100, 300, 300, 800, 1200, 1600, 2000}, IsValid: false},
+		{Name: "Negative", Widths: []int{-100, 300, 500, 800, 1200, 1600, 2000}, IsValid: false},
+		{Name: "Missing", Widths: []int{100, 300}, IsValid: false},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			_, err := NewBreakpoints(data.Widths)
+			if data.IsValid && err != nil {
+				t.Fatalf("Expected %v to be valid, got %v", data.Widths, err)
+			}
+			if !data.IsValid && err == nil {
+				t.Fatalf("Expected %v to be invalid", data.Widths)
+			}
+		})
+	}
+}
@@ -0,0 +1,639 @@
+package pipeline
+
+import (
+	"container/heap"
+	"encoding/binary"
+	"fmt"
+	"golang.org/x/image/draw"
+	stdimage "image"
+	"io"
+	"math/bits"
+)
+
+// The Go image libraries can only decode webp, so the files are encoded here as lossless VP8L. Only the subtract
+// green and predictor transforms are applied, repeated runs of pixels are written as LZ77 backward references and the
+// rest as literals, all coded with prefix codes built from the image. This keeps the encoder small while the files
+// stay readable by every webp decoder. Only the local resizers encode with it, the remote images API has its own.
+
+// WebpMaxPixels caps the images encoded as webp, lossless files of photos are about as large as png and the largest
+// images take seconds to encode
+const WebpMaxPixels = 1 << 22
+
+const (
+	vp8lSignature        = 0x2f
+	vp8lMaxDimension     = 1 << 14
+	vp8lMaxCodeLength    = 15
+	vp8lMaxCodeLenLength = 7
+	vp8lPredictor        = 0
+	vp8lSubtractGreen    = 2
+	// predictorBits is the log-2 size of the tiles that pick a predictor, 32 pixels
+	predictorBits = 5
+
+	greenAlphabetSize    = 256 + 24
+	literalAlphabetSize  = 256
+	distanceAlphabetSize = 40
+	codeLengthCodes      = 19
+
+	lz77MinLength = 3
+	lz77MaxLength = 4096
+	// lz77MaxDistance keeps the distance codes within the distance alphabet
+	lz77MaxDistance = 1<<20 - len(distanceMapTable)
+	lz77HashBits    = 16
+	// lz77ChainLength is how many earlier pixels with the same hash are compared, more find longer matches slower
+	lz77ChainLength = 32
+	// lz77SearchBudget caps the comparisons of an image, larger images compare fewer pixels of the chain so that the
+	// time to encode grows with the pixels and not with the pixels times the chain
+	lz77SearchBudget = 1 << 24
+)
+
+// predictorModes are the modes tried on every tile: left, top, average of left and top and select
+var predictorModes = []uint8{1, 2, 7, 11}
+
+// distanceMapTable holds the neighbourhood of a pixel the short distance codes point to, each offset as
+// y << 4 | (8 - x) in the order of the codes
+var distanceMapTable = [120]uint8{
+	0x18, 0x07, 0x17, 0x19, 0x28, 0x06, 0x27, 0x29, 0x16, 0x1a,
+	0x26, 0x2a, 0x38, 0x05, 0x37, 0x39, 0x15, 0x1b, 0x36, 0x3a,
+	0x25, 0x2b, 0x48, 0x04, 0x47, 0x49, 0x14, 0x1c, 0x35, 0x3b,
+	0x46, 0x4a, 0x24, 0x2c, 0x58, 0x45, 0x4b, 0x34, 0x3c, 0x03,
+	0x57, 0x59, 0x13, 0x1d, 0x56, 0x5a, 0x23, 0x2d, 0x44, 0x4c,
+	0x55, 0x5b, 0x33, 0x3d, 0x68, 0x02, 0x67, 0x69, 0x12, 0x1e,
+	0x66, 0x6a, 0x22, 0x2e, 0x54, 0x5c, 0x43, 0x4d, 0x65, 0x6b,
+	0x32, 0x3e, 0x78, 0x01, 0x77, 0x79, 0x53, 0x5d, 0x11, 0x1f,
+	0x64, 0x6c, 0x42, 0x4e, 0x76, 0x7a, 0x21, 0x2f, 0x75, 0x7b,
+	0x31, 0x3f, 0x63, 0x6d, 0x52, 0x5e, 0x00, 0x74, 0x7c, 0x41,
+	0x4f, 0x10, 0x20, 0x62, 0x6e, 0x30, 0x73, 0x7d, 0x51, 0x5f,
+	0x40, 0x72, 0x7e, 0x61, 0x6f, 0x50, 0x71, 0x7f, 0x60, 0x70,
+}
+
+// codeLengthCodeOrder is the order the code lengths of the code length code are written in
+var codeLengthCodeOrder = [codeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// bitWriter writes values starting from their least significant bit, as the VP8L bitstream is read
+type bitWriter struct {
+	data  []byte
+	bits  uint64
+	nBits uint
+}
+
+func (writer *bitWriter) write(value uint32, nBits uint) {
+	writer.bits |= uint64(value) << writer.nBits
+	writer.nBits += nBits
+	for writer.nBits >= 8 {
+		writer.data = append(writer.data, byte(writer.bits))
+		writer.bits >>= 8
+		writer.nBits -= 8
+	}
+}
+
+func (writer *bitWriter) bytes() []byte {
+	if writer.nBits > 0 {
+		writer.data = append(writer.data, byte(writer.bits))
+		writer.bits, writer.nBits = 0, 0
+	}
+	return writer.data
+}
+
+// prefixCode holds the bit-reversed canonical code of every symbol and its length as written to the stream
+type prefixCode struct {
+	codes   []uint32
+	lengths []uint
+}
+
+func (code prefixCode) write(writer *bitWriter, symbol int) {
+	writer.write(code.codes[symbol], code.lengths[symbol])
+}
+
+func encodeWebp(w io.Writer, img stdimage.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > vp8lMaxDimension || height > vp8lMaxDimension {
+		return fmt.Errorf("webp can not hold an image of %dx%d", width, height)
+	}
+	if width*height > WebpMaxPixels {
+		return fmt.Errorf("webp image of %dx%d is more than %d pixels", width, height, WebpMaxPixels)
+	}
+
+	nrgba := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	draw.Draw(nrgba, nrgba.Bounds(), img, bounds.Min, draw.Src)
+	pix := nrgba.Pix
+
+	// Subtract green from red and blue, the channels are usually correlated so this narrows their histograms
+	hasAlpha := false
+	for i := 0; i < len(pix); i += 4 {
+		pix[i] -= pix[i+1]
+		pix[i+2] -= pix[i+1]
+		hasAlpha = hasAlpha || pix[i+3] != 0xff
+	}
+	residuals, modes := predict(pix, width, height)
+
+	writer := &bitWriter{}
+	writer.write(vp8lSignature, 8)
+	writer.write(uint32(width-1), 14)
+	writer.write(uint32(height-1), 14)
+	if hasAlpha {
+		writer.write(1, 1)
+	} else {
+		writer.write(0, 1)
+	}
+	writer.write(0, 3) // version
+
+	// The decoder reverts the transforms in the opposite order they are written in
+	writer.write(1, 1)
+	writer.write(vp8lSubtractGreen, 2)
+	writer.write(1, 1)
+	writer.write(vp8lPredictor, 2)
+	writer.write(predictorBits-2, 3)
+	writeImageData(writer, modes, tileCount(width), false)
+	writer.write(0, 1) // no more transforms
+
+	writeImageData(writer, residuals, width, true)
+
+	return writeRiff(w, writer.bytes())
+}
+
+// writeImageData writes the pixels in RGBA order as literals and backward references, the image itself has the choice
+// of meta prefix codes while the images of the transforms do not
+func writeImageData(writer *bitWriter, pix []byte, width int, isImage bool) {
+	tokens := backwardReferences(pix, width)
+
+	histograms := [5][]int{
+		make([]int, greenAlphabetSize),
+		make([]int, literalAlphabetSize),
+		make([]int, literalAlphabetSize),
+		make([]int, literalAlphabetSize),
+		make([]int, distanceAlphabetSize),
+	}
+	for _, token := range tokens {
+		if token.length == 0 {
+			histograms[0][pix[token.offset+1]]++
+			histograms[1][pix[token.offset]]++
+			histograms[2][pix[token.offset+2]]++
+			histograms[3][pix[token.offset+3]]++
+			continue
+		}
+		lengthSymbol, _, _ := prefixEncode(token.length)
+		histograms[0][literalAlphabetSize+lengthSymbol]++
+		distanceSymbol, _, _ := prefixEncode(token.distance)
+		histograms[4][distanceSymbol]++
+	}
+
+	writer.write(0, 1) // no color cache
+	if isImage {
+		writer.write(0, 1) // no meta prefix codes
+	}
+
+	var codes [5]prefixCode
+	for i, histogram := range histograms {
+		codes[i] = writePrefixCode(writer, histogram)
+	}
+
+	for _, token := range tokens {
+		if token.length == 0 {
+			codes[0].write(writer, int(pix[token.offset+1]))
+			codes[1].write(writer, int(pix[token.offset]))
+			codes[2].write(writer, int(pix[token.offset+2]))
+			codes[3].write(writer, int(pix[token.offset+3]))
+			continue
+		}
+		symbol, extraBits, extra := prefixEncode(token.length)
+		codes[0].write(writer, literalAlphabetSize+symbol)
+		writer.write(extra, extraBits)
+		symbol, extraBits, extra = prefixEncode(token.distance)
+		codes[4].write(writer, symbol)
+		writer.write(extra, extraBits)
+	}
+}
+
+// lz77Token is either the literal pixel at offset of the pixels or a copy of length pixels from distance back, with
+// the distance already turned into its code
+type lz77Token struct {
+	offset   int
+	length   int
+	distance int
+}
+
+// backwardReferences greedily replaces the runs of pixels seen before with copies of the longest earlier run found
+// through a hash chain of pixel pairs
+func backwardReferences(pix []byte, width int) []lz77Token {
+	count := len(pix) / 4
+	pixel := func(i int) uint32 {
+		return binary.LittleEndian.Uint32(pix[4*i:])
+	}
+	hash := func(i int) uint32 {
+		return (pixel(i)*0x1e35a7bd ^ pixel(i+1)*0x9e3779b1) >> (32 - lz77HashBits)
+	}
+
+	head := make([]int, 1<<lz77HashBits)
+	for i := range head {
+		head[i] = -1
+	}
+	previous := make([]int, count)
+	insert := func(i int) {
+		if i+1 < count {
+			h := hash(i)
+			previous[i], head[h] = head[h], i
+		}
+	}
+
+	chainLength := searchChainLength(count)
+	distanceCodes := neighbourhoodDistanceCodes(width)
+	var tokens []lz77Token
+	for i := 0; i < count; {
+		bestLength, bestDistance := 0, 0
+		if i+1 < count {
+			maxLength := count - i
+			if maxLength > lz77MaxLength {
+				maxLength = lz77MaxLength
+			}
+			candidate := head[hash(i)]
+			for tries := 0; candidate >= 0 && tries < chainLength && i-candidate <= lz77MaxDistance; tries++ {
+				length := 0
+				for length < maxLength && pixel(candidate+length) == pixel(i+length) {
+					length++
+				}
+				if length > bestLength {
+					bestLength, bestDistance = length, i-candidate
+				}
+				candidate = previous[candidate]
+			}
+		}
+
+		if bestLength < lz77MinLength {
+			tokens = append(tokens, lz77Token{offset: 4 * i})
+			insert(i)
+			i++
+			continue
+		}
+
+		distance, ok := distanceCodes[bestDistance]
+		if !ok {
+			distance = bestDistance + len(distanceMapTable)
+		}
+		tokens = append(tokens, lz77Token{length: bestLength, distance: distance})
+		for end := i + bestLength; i < end; i++ {
+			insert(i)
+		}
+	}
+
+	return tokens
+}
+
+// searchChainLength is how many earlier pixels of the chain are compared for an image of count pixels, within the
+// search budget and at least one
+func searchChainLength(count int) int {
+	chainLength := lz77SearchBudget / count
+	if chainLength > lz77ChainLength {
+		return lz77ChainLength
+	}
+	if chainLength < 1 {
+		return 1
+	}
+	return chainLength
+}
+
+// neighbourhoodDistanceCodes maps the distances to the pixels around the current one to their short codes, an image
+// narrower than 8 pixels reaches some of them through several codes and the first one is taken
+func neighbourhoodDistanceCodes(width int) map[int]int {
+	codes := make(map[int]int, len(distanceMapTable))
+	for i, offset := range distanceMapTable {
+		distance := int(offset>>4)*width + 8 - int(offset&0xf)
+		if distance < 1 {
+			distance = 1
+		}
+		if _, ok := codes[distance]; !ok {
+			codes[distance] = i + 1
+		}
+	}
+	return codes
+}
+
+// prefixEncode splits a length or a distance code into the symbol of its range and the extra bits of its position in
+// the range
+func prefixEncode(value int) (int, uint, uint32) {
+	value--
+	if value < 4 {
+		return value, 0, 0
+	}
+	highest := bits.Len(uint(value)) - 1
+	second := (value >> (highest - 1)) & 1
+	extraBits := uint(highest - 1)
+	return 2*highest + second, extraBits, uint32(value) & (1<<extraBits - 1)
+}
+
+// predict returns the difference of every pixel from its prediction together with the image of the predictor mode
+// of every tile, the mode leaving the smallest differences wins. The first pixel is predicted as opaque black, the
+// rest of the first row from the left and the first column from the top, whatever the mode of their tile.
+func predict(pix []byte, width, height int) ([]byte, []byte) {
+	tilesPerRow, tilesPerColumn := tileCount(width), tileCount(height)
+	modes := make([]byte, 4*tilesPerRow*tilesPerColumn)
+	residuals := make([]byte, len(pix))
+
+	for tileY := 0; tileY < tilesPerColumn; tileY++ {
+		for tileX := 0; tileX < tilesPerRow; tileX++ {
+			best, bestCost := predictorModes[0], -1
+			for _, mode := range predictorModes {
+				cost := 0
+				forEachTilePixel(width, height, tileX, tileY, func(x, y int) {
+					var prediction [4]byte
+					predictPixel(pix, width, x, y, mode, &prediction)
+					for c, value := range prediction {
+						cost += absInt(int(int8(pix[4*(y*width+x)+c] - value)))
+					}
+				})
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+
+			modes[4*(tileY*tilesPerRow+tileX)+1] = best
+			forEachTilePixel(width, height, tileX, tileY, func(x, y int) {
+				var prediction [4]byte
+				predictPixel(pix, width, x, y, best, &prediction)
+				for c, value := range prediction {
+					i := 4*(y*width+x) + c
+					residuals[i] = pix[i] - value
+				}
+			})
+		}
+	}
+
+	return residuals, modes
+}
+
+// tileCount is how many predictor tiles cover the pixels of a row or a column
+func tileCount(pixels int) int {
+	return (pixels + 1<<predictorBits - 1) >> predictorBits
+}
+
+func forEachTilePixel(width, height, tileX, tileY int, fn func(x, y int)) {
+	for y := tileY << predictorBits; y < height && y < (tileY+1)<<predictorBits; y++ {
+		for x := tileX << predictorBits; x < width && x < (tileX+1)<<predictorBits; x++ {
+			fn(x, y)
+		}
+	}
+}
+
+// predictPixel predicts the pixel in RGBA order from its neighbours as the decoder does
+func predictPixel(pix []byte, width, x, y int, mode uint8, prediction *[4]byte) {
+	p := 4 * (y*width + x)
+	left, top, topLeft := p-4, p-4*width, p-4*width-4
+
+	switch {
+	case x == 0 && y == 0:
+		*prediction = [4]byte{0, 0, 0, 0xff}
+		return
+	case y == 0:
+		mode = 1
+	case x == 0:
+		mode = 2
+	}
+
+	for c := 0; c < 4; c++ {
+		switch mode {
+		case 1:
+			prediction[c] = pix[left+c]
+		case 2:
+			prediction[c] = pix[top+c]
+		case 7:
+			prediction[c] = byte((int(pix[left+c]) + int(pix[top+c])) / 2)
+		}
+	}
+	if mode != 11 {
+		return
+	}
+
+	// Select takes the left or top pixel, whichever is closer to the gradient through the top left one
+	leftDistance, topDistance := 0, 0
+	for c := 0; c < 4; c++ {
+		leftDistance += absInt(int(pix[topLeft+c]) - int(pix[top+c]))
+		topDistance += absInt(int(pix[topLeft+c]) - int(pix[left+c]))
+	}
+	from := top
+	if leftDistance < topDistance {
+		from = left
+	}
+	copy(prediction[:], pix[from:from+4])
+}
+
+func absInt(value int) int {
+	if value < 0 {
+		return -value
+	}
+	return value
+}
+
+func writeRiff(w io.Writer, chunk []byte) error {
+	padding := len(chunk) % 2
+	header := make([]byte, 20)
+	copy(header[0:], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:], uint32(4+8+len(chunk)+padding))
+	copy(header[8:], "WEBPVP8L")
+	binary.LittleEndian.PutUint32(header[16:], uint32(len(chunk)))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(chunk); err != nil {
+		return err
+	}
+	if padding != 0 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writePrefixCode writes the code of the histogram and returns it, up to two symbols that fit a byte are written as
+// a simple code, everything else as code lengths that are themselves prefix coded
+func writePrefixCode(writer *bitWriter, histogram []int) prefixCode {
+	code := prefixCode{codes: make([]uint32, len(histogram)), lengths: make([]uint, len(histogram))}
+
+	var symbols []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			symbols = append(symbols, symbol)
+		}
+	}
+	if len(symbols) == 0 {
+		symbols = []int{0}
+	}
+
+	if len(symbols) <= 2 && symbols[len(symbols)-1] < 256 {
+		writer.write(1, 1) // simple code
+		writer.write(uint32(len(symbols)-1), 1)
+		if symbols[0] < 2 {
+			writer.write(0, 1)
+			writer.write(uint32(symbols[0]), 1)
+		} else {
+			writer.write(1, 1)
+			writer.write(uint32(symbols[0]), 8)
+		}
+		if len(symbols) == 2 {
+			writer.write(uint32(symbols[1]), 8)
+			code.codes[symbols[1]], code.lengths[symbols[0]], code.lengths[symbols[1]] = 1, 1, 1
+		}
+		return code
+	}
+
+	lengths := codeLengths(histogram, vp8lMaxCodeLength)
+
+	lengthHistogram := make([]int, codeLengthCodes)
+	for _, length := range lengths {
+		lengthHistogram[length]++
+	}
+	lengthLengths := codeLengths(lengthHistogram, vp8lMaxCodeLenLength)
+	lengthCode := canonicalCode(lengthLengths)
+
+	count := 4
+	for i, symbol := range codeLengthCodeOrder {
+		if lengthLengths[symbol] != 0 && i+1 > count {
+			count = i + 1
+		}
+	}
+	writer.write(0, 1) // normal code
+	writer.write(uint32(count-4), 4)
+	for _, symbol := range codeLengthCodeOrder[:count] {
+		writer.write(uint32(lengthLengths[symbol]), 3)
+	}
+	writer.write(0, 1) // the lengths of the whole alphabet follow
+	for _, length := range lengths {
+		lengthCode.write(writer, int(length))
+	}
+
+	return canonicalCode(lengths)
+}
+
+// canonicalCode assigns the codes in the order of their length and symbol, the decoder reads the codes from their
+// most significant bit so they are reversed. A single used symbol takes no bits at all.
+func canonicalCode(lengths []uint) prefixCode {
+	code := prefixCode{codes: make([]uint32, len(lengths)), lengths: make([]uint, len(lengths))}
+
+	used := 0
+	var counts [vp8lMaxCodeLength + 1]uint32
+	for _, length := range lengths {
+		if length > 0 {
+			used++
+			counts[length]++
+		}
+	}
+	if used < 2 {
+		return code
+	}
+
+	var next [vp8lMaxCodeLength + 1]uint32
+	current := uint32(0)
+	for length := 1; length <= vp8lMaxCodeLength; length++ {
+		current = (current + counts[length-1]) << 1
+		next[length] = current
+	}
+
+	for symbol, length := range lengths {
+		if length == 0 {
+			continue
+		}
+		value := next[length]
+		next[length]++
+
+		reversed := uint32(0)
+		for i := uint(0); i < length; i++ {
+			reversed = reversed<<1 | (value>>i)&1
+		}
+		code.codes[symbol] = reversed
+		code.lengths[symbol] = length
+	}
+	return code
+}
+
+// codeLengths returns the Huffman code length of every symbol of the histogram, no longer than maxLength. When the
+// tree gets too deep the counts are flattened until it fits.
+func codeLengths(histogram []int, maxLength uint) []uint {
+	counts := make([]int, len(histogram))
+	copy(counts, histogram)
+
+	for {
+		lengths := huffmanLengths(counts)
+		longest := uint(0)
+		for _, length := range lengths {
+			if length > longest {
+				longest = length
+			}
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+
+		for i, count := range counts {
+			if count > 0 {
+				counts[i] = count/2 + 1
+			}
+		}
+	}
+}
+
+type huffmanNode struct {
+	count       int
+	symbol      int
+	left, right *huffmanNode
+}
+
+// huffmanQueue orders the nodes by count and then by symbol, so that the codes do not depend on the heap internals
+type huffmanQueue []*huffmanNode
+
+func (queue huffmanQueue) Len() int { return len(queue) }
+func (queue huffmanQueue) Less(i, j int) bool {
+	if queue[i].count != queue[j].count {
+		return queue[i].count < queue[j].count
+	}
+	return queue[i].symbol < queue[j].symbol
+}
+func (queue huffmanQueue) Swap(i, j int)       { queue[i], queue[j] = queue[j], queue[i] }
+func (queue *huffmanQueue) Push(x interface{}) { *queue = append(*queue, x.(*huffmanNode)) }
+func (queue *huffmanQueue) Pop() interface{} {
+	old := *queue
+	node := old[len(old)-1]
+	*queue = old[:len(old)-1]
+	return node
+}
+
+func huffmanLengths(counts []int) []uint {
+	lengths := make([]uint, len(counts))
+
+	queue := huffmanQueue{}
+	for symbol, count := range counts {
+		if count > 0 {
+			queue = append(queue, &huffmanNode{count: count, symbol: symbol})
+		}
+	}
+	switch len(queue) {
+	case 0:
+		return lengths
+	case 1:
+		lengths[queue[0].symbol] = 1
+		return lengths
+	}
+
+	heap.Init(&queue)
+	// Merged nodes get symbols past the alphabet to keep the order total
+	next := len(counts)
+	for queue.Len() > 1 {
+		left := heap.Pop(&queue).(*huffmanNode)
+		right := heap.Pop(&queue).(*huffmanNode)
+		heap.Push(&queue, &huffmanNode{count: left.count + right.count, symbol: next, left: left, right: right})
+		next++
+	}
+
+	var walk func(node *huffmanNode, depth uint)
+	walk = func(node *huffmanNode, depth uint) {
+		if node.left == nil {
+			lengths[node.symbol] = depth
+			return
+		}
+		walk(node.left, depth+1)
+		walk(node.right, depth+1)
+	}
+	walk(queue[0], 0)
+
+	return lengths
+}
@@ -0,0 +1,86 @@
+// Package placeholder computes what the frontend shows while the sizes of an image load, a BlurHash of the image and
+// its dominant color
+package placeholder
+
+import (
+	"fmt"
+	"golang.org/x/image/draw"
+	stdimage "image"
+	"math"
+)
+
+const (
+	// sampleWidth is the width the image is shrunk to first, placeholders carry no detail so more pixels only cost time
+	sampleWidth = 32
+	// xComponents and yComponents are the cosines of the BlurHash along the width and height, 4x3 suits landscapes
+	xComponents = 4
+	yComponents = 3
+)
+
+type Placeholder struct {
+	BlurHash string
+	// Color is the dominant color as #rrggbb
+	Color string
+}
+
+func Compute(img stdimage.Image) Placeholder {
+	sample := shrink(img)
+	return Placeholder{BlurHash: blurHash(sample), Color: dominantColor(sample)}
+}
+
+// shrink scales the image to the sample width keeping its aspect ratio, images narrower than it are only copied
+func shrink(img stdimage.Image) *stdimage.NRGBA {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > sampleWidth {
+		height = int(math.Max(1, math.Round(float64(height)*sampleWidth/float64(width))))
+		width = sampleWidth
+	}
+
+	sample := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	draw.BiLinear.Scale(sample, sample.Bounds(), img, bounds, draw.Src, nil)
+	return sample
+}
+
+// colorBits are kept of every channel when counting the colors, so that shades of the same color count together
+const colorBits = 4
+
+// dominantColor is the average of the pixels in the most common bucket of similar colors
+func dominantColor(img *stdimage.NRGBA) string {
+	type bucket struct {
+		count   int
+		r, g, b int
+	}
+	buckets := make(map[int]*bucket)
+	var dominant *bucket
+
+	for y := 0; y < img.Rect.Dy(); y++ {
+		for x := 0; x < img.Rect.Dx(); x++ {
+			c := img.NRGBAAt(x, y)
+			if c.A == 0 {
+				continue
+			}
+			shift := 8 - colorBits
+			key := int(c.R>>shift)<<(2*colorBits) | int(c.G>>shift)<<colorBits | int(c.B>>shift)
+			current, ok := buckets[key]
+			if !ok {
+				current = &bucket{}
+				buckets[key] = current
+			}
+			current.count++
+			current.r += int(c.R)
+			current.g += int(c.G)
+			current.b += int(c.B)
+			if dominant == nil || current.count > dominant.count {
+				dominant = current
+			}
+		}
+	}
+
+	if dominant == nil {
+		return "#000000"
+	}
+	return fmt.Sprintf(
+		"#%02x%02x%02x", dominant.r/dominant.count, dominant.g/dominant.count, dominant.b/dominant.count,
+	)
+}
@@ -0,0 +1,42 @@
+package placeholder
+
+import (
+	stdimage "image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func newFilledImage(width, height int, fill color.Color) *stdimage.NRGBA {
+	img := stdimage.NewNRGBA(stdimage.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &stdimage.Uniform{C: fill}, stdimage.Point{}, draw.Src)
+	return img
+}
+
+func TestCompute_SolidColor(t *testing.T) {
+	placeholder := Compute(newFilledImage(300, 200, color.NRGBA{R: 255, A: 255}))
+
+	// L stands for 4x3 components and TI:j for the average color of #ff0000
+	if len(placeholder.BlurHash) != 28 || placeholder.BlurHash[0] != 'L' || placeholder.BlurHash[2:6] != "TI:j" {
+		t.Fatalf("Expected a BlurHash of 4x3 components averaging to red, got %s", placeholder.BlurHash)
+	}
+	if placeholder.Color != "#ff0000" {
+		t.Fatalf("Expected dominant color #ff0000, got %s", placeholder.Color)
+	}
+}
+
+func TestCompute_DominantColor(t *testing.T) {
+	img := newFilledImage(400, 300, color.NRGBA{R: 20, G: 40, B: 200, A: 255})
+	draw.Draw(img, stdimage.Rect(0, 0, 100, 300), &stdimage.Uniform{C: color.NRGBA{R: 250, A: 255}}, stdimage.Point{}, draw.Src)
+
+	placeholder := Compute(img)
+	if placeholder.Color != "#1428c8" {
+		t.Fatalf("Expected the blue covering most of the image, got %s", placeholder.Color)
+	}
+	if len(placeholder.BlurHash) != 28 {
+		t.Fatalf("Expected a BlurHash of 28 characters, got %s", placeholder.BlurHash)
+	}
+	if placeholder.BlurHash == Compute(newFilledImage(400, 300, color.NRGBA{R: 20, G: 40, B: 200, A: 255})).BlurHash {
+		t.Fatalf("Expected the red stripe to show in the BlurHash, got %s", placeholder.BlurHash)
+	}
+}
@@ -2,16 +2,22 @@ package resize
 
 import (
 	"api/core"
+	"api/image"
+	"bytes"
+	"context"
+	"encoding/json"
 	"fmt"
 	"github.com/rs/zerolog"
+	"io/ioutil"
 	"net/http"
 	"time"
 )
 
 type Client struct {
-	domain string
-	client *http.Client
-	logger *zerolog.Logger
+	domain    string
+	client    *http.Client
+	maxPixels int
+	logger    *zerolog.Logger
 }
 
 func NewClient(
@@ -23,7 +29,8 @@ func NewClient(
 		client: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		logger: logger,
+		maxPixels: int(config.ImagesMaxPixels),
+		logger:    logger,
 	}
 }
 
@@ -34,3 +41,83 @@ func (client *Client) url(relativePath string) string {
 func isResponseOk(statusCode int) bool {
 	return statusCode >= 200 && statusCode < 300
 }
+
+// postJson sends the request as json and returns the body of a successful response, failures are returned as
+// image.Forbidden or image.BadRequest
+func (client *Client) postJson(
+	ctx context.Context,
+	relativePath string,
+	authorizationHeader string,
+	request interface{},
+	action string,
+) ([]byte, error) {
+	jsonData, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+
+	requestUrl := client.url(relativePath)
+
+	req, err := http.NewRequestWithContext(
+		ctx,
+		"POST",
+		requestUrl,
+		bytes.NewBuffer(jsonData),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Add("Authorization", authorizationHeader)
+	req.Header.Add("Content-Type", "application/json")
+
+	client.logger.Info().Msgf("issuing %s request", action)
+
+	res, err := client.client.Do(req)
+	if err != nil {
+		var statusCode int
+		if res != nil {
+			statusCode = res.StatusCode
+		}
+		return nil, &image.BadRequest{
+			RequestError: image.RequestError{
+				Url:        requestUrl,
+				StatusCode: statusCode,
+				Message:    fmt.Sprintf("Failed making %s request", action),
+				Err:        err,
+			},
+		}
+	}
+	defer func() {
+		if closeErr := res.Body.Close(); closeErr != nil {
+			client.logger.Warn().Msgf("failed closing body: %s", closeErr.Error())
+		}
+	}()
+
+	body, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !isResponseOk(res.StatusCode) {
+		if res.StatusCode == 403 {
+			return nil, &image.Forbidden{
+				RequestError: image.RequestError{
+					Url:        requestUrl,
+					StatusCode: res.StatusCode,
+					Message:    "Forbidden request",
+				},
+				Body: string(body),
+			}
+		}
+		return nil, &image.BadRequest{
+			RequestError: image.RequestError{
+				Url:        requestUrl,
+				StatusCode: res.StatusCode,
+				Message:    fmt.Sprintf("Failed %s request", action),
+			},
+			Body: string(body),
+		}
+	}
+
+	return body, nil
+}
@@ -3,12 +3,36 @@ package resize
 import (
 	"api/image"
 	"context"
+	"encoding/json"
 )
 
+// Rename moves all the sizes of the image to the new name and invalidates the old paths on the CDN.
+// Failed invalidation is only logged, as the files are already moved and cached copies expire on their own.
 func (client *Client) Rename(
 	ctx context.Context,
 	authorizationHeader string,
 	request image.RenameRequest,
 ) (image.ResizeResponse, error) {
-	return image.ResizeResponse{}, nil
+	body, err := client.postJson(ctx, "/rename", authorizationHeader, request, "rename")
+	if err != nil {
+		return image.ResizeResponse{}, err
+	}
+
+	var response image.ResizeResponse
+
+	err = json.Unmarshal(body, &response)
+	if err != nil {
+		return image.ResizeResponse{}, err
+	}
+
+	invalidateRequest := image.DeleteRequest{
+		Name:       request.Name,
+		Format:     request.Format,
+		Dimensions: request.SizeMap.GetAllDimensions(),
+	}
+	if err = client.Invalidate(ctx, authorizationHeader, invalidateRequest); err != nil {
+		client.logger.Warn().Err(err).Msgf("failed invalidating renamed image %s", request.Name)
+	}
+
+	return response, nil
 }
@@ -0,0 +1,116 @@
+package resize
+
+import (
+	"api/image"
+	"api/logger"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func newTestClient(t *testing.T, handler http.Handler) *Client {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &Client{domain: server.URL, client: server.Client(), logger: logger.NewLogger()}
+}
+
+func TestClient_Rename(t *testing.T) {
+	request := image.RenameRequest{
+		Name:    "my-plane",
+		NewName: "my-new-plane",
+		Format:  image.PngFormat,
+		SizeMap: image.Sizes{
+			Original: image.Dimensions{Width: 800, Height: 600},
+			Xs:       &image.Dimensions{Width: 100, Height: 75},
+		},
+	}
+	expected := image.ResizeResponse{
+		Format:   image.PngFormat,
+		Original: "images/my-new-plane.png",
+		Name:     "my-new-plane",
+		Domain:   "https://random.cloudfront.net",
+		Path:     "images",
+		Sizes:    request.SizeMap,
+	}
+
+	var invalidated image.DeleteRequest
+	mux := http.NewServeMux()
+	mux.HandleFunc("/rename", func(w http.ResponseWriter, r *http.Request) {
+		var received image.RenameRequest
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Error(err)
+		}
+		if r.Header.Get("Authorization") != "Bearer token" || received.NewName != request.NewName {
+			t.Errorf("Expected authorized rename to %s, got %+v", request.NewName, received)
+		}
+		_ = json.NewEncoder(w).Encode(expected)
+	})
+	mux.HandleFunc("/invalidate", func(w http.ResponseWriter, r *http.Request) {
+		if err := json.NewDecoder(r.Body).Decode(&invalidated); err != nil {
+			t.Error(err)
+		}
+	})
+	client := newTestClient(t, mux)
+
+	response, err := client.Rename(context.Background(), "Bearer token", request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if response.Name != expected.Name || response.Original != expected.Original || !response.Sizes.IsEqualTo(expected.Sizes) {
+		t.Fatalf("Expected %+v, got %+v", expected, response)
+	}
+	if invalidated.Name != request.Name || invalidated.Format != request.Format || len(invalidated.Dimensions) != 2 {
+		t.Fatalf("Expected old paths of %s to be invalidated, got %+v", request.Name, invalidated)
+	}
+}
+
+func TestClient_RenameFailure(t *testing.T) {
+	data := []struct {
+		testName   string
+		statusCode int
+		expected   func(err error) bool
+	}{
+		{
+			testName:   "Forbidden",
+			statusCode: http.StatusForbidden,
+			expected: func(err error) bool {
+				var forbidden *image.Forbidden
+				return errors.As(err, &forbidden)
+			},
+		},
+		{
+			testName:   "Bad request",
+			statusCode: http.StatusConflict,
+			expected: func(err error) bool {
+				var badRequest *image.BadRequest
+				return errors.As(err, &badRequest) && badRequest.StatusCode == http.StatusConflict
+			},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			isInvalidated := false
+			mux := http.NewServeMux()
+			mux.HandleFunc("/rename", func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(d.statusCode)
+			})
+			mux.HandleFunc("/invalidate", func(w http.ResponseWriter, r *http.Request) {
+				isInvalidated = true
+			})
+			client := newTestClient(t, mux)
+
+			_, err := client.Rename(context.Background(), "Bearer token", image.RenameRequest{Name: "my-plane"})
+			if !d.expected(err) {
+				t.Fatalf("Unexpected error %v", err)
+			}
+			if isInvalidated {
+				t.Fatal("Expected no invalidation after failed rename")
+			}
+		})
+	}
+}
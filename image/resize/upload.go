@@ -2,6 +2,8 @@ package resize
 
 import (
 	"api/image"
+	"api/image/content"
+	"bytes"
 	"context"
 	"fmt"
 	"io/ioutil"
@@ -15,20 +17,24 @@ func (client *Client) UploadFile(
 	format image.Format,
 	fileHeader *multipart.FileHeader,
 ) error {
-	file, err := fileHeader.Open()
+	data, err := content.ReadFile(fileHeader, format, client.maxPixels)
+	if err != nil {
+		return err
+	}
 	contentType := string(format.ToContentType())
 
 	req, err := http.NewRequestWithContext(
 		ctx,
 		http.MethodPut,
 		signedUrl,
-		file,
+		bytes.NewReader(data),
 	)
 	if err != nil {
 		return err
 	}
 
-	req.ContentLength = fileHeader.Size
+	req.ContentLength = int64(len(data))
+	req.Header.Set("Content-Type", contentType)
 
 	res, err := client.client.Do(req)
 	if err != nil {
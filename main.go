@@ -38,8 +38,13 @@ func Bootstrap(app *core.App, logger *zerolog.Logger, configurations ...Configur
 
 	server, err := http_server.StartNewConfiguredAndListenChannel(logger,
 		http_server.Handlers{
-			ImagesHandler: app.ImagesService,
-			Authenticator: app.Auth,
+			ImagesHandler:   app.ImagesService,
+			TagsHandler:     app.ImagesService,
+			WebhooksHandler: app.WebhooksService,
+			AuditHandler:    app.AuditLogger,
+			TrashHandler:    app.ImagesService,
+			UsersHandler:    app.UsersService,
+			Authenticator:   app.Auth,
 		}, errChannel)
 	if err != nil {
 		logger.Fatal().Msgf("failed starting the server: %s", err.Error())
@@ -0,0 +1,16 @@
+package clientip
+
+import "context"
+
+type key struct{}
+
+// NewContext stores the IP of the client making the request
+func NewContext(ctx context.Context, ip string) context.Context {
+	return context.WithValue(ctx, key{}, ip)
+}
+
+// FromContext returns the IP of the client, empty outside of a request
+func FromContext(ctx context.Context) string {
+	ip, _ := ctx.Value(key{}).(string)
+	return ip
+}
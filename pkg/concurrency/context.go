@@ -20,6 +20,19 @@ func SleepSecondsWithContext(ctx context.Context, seconds uint) error {
 	return nil
 }
 
+// SleepWithContext sleeps for the duration unless the context ends first
+func SleepWithContext(ctx context.Context, duration time.Duration) error {
+	timer := time.NewTimer(duration)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
 type Value []byte
 
 func SlowOperation(ctx context.Context, seconds uint) (Value, error) {
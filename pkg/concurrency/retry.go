@@ -24,9 +24,16 @@ func NewRetry(retries uint, delay time.Duration) *Retry {
 	}
 }
 
+// WithBackoff sets the backoff before the first retry and the maximum one it doubles up to
+func (r *Retry) WithBackoff(base, maximum time.Duration) *Retry {
+	r.baseBackoff = base
+	r.maximumBackoff = maximum
+	return r
+}
+
 // Execute will retry failed request with a jitter backoff algorithm. Please
 // execute a rand.Seed(time.Now().UTC().UnixNano()) at the start of the main
-// function to have different numbers generated
+// function to have different numbers generated. The backoff ends with the context, whose error is returned then.
 func (r *Retry) Execute(ctx context.Context, effector RetryEffector) error {
 	err := effector(ctx, 0)
 
@@ -40,7 +47,9 @@ func (r *Retry) Execute(ctx context.Context, effector RetryEffector) error {
 
 		jitter := rand.Int63n(int64(backoff * 3))
 		sleep := r.baseBackoff + time.Duration(jitter)
-		time.Sleep(sleep)
+		if sleepErr := SleepWithContext(ctx, sleep); sleepErr != nil {
+			return sleepErr
+		}
 		err = effector(ctx, retryCount)
 	}
 
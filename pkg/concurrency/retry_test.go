@@ -21,6 +21,14 @@ func TestNewRetry(t *testing.T) {
 	}
 }
 
+func TestRetry_WithBackoff(t *testing.T) {
+	newRetry := NewRetry(2, 3*time.Second).WithBackoff(time.Millisecond, 5*time.Millisecond)
+
+	if newRetry.baseBackoff != time.Millisecond || newRetry.maximumBackoff != 5*time.Millisecond {
+		t.Fatalf("Expected backoff between 1ms and 5ms, got %s and %s", newRetry.baseBackoff, newRetry.maximumBackoff)
+	}
+}
+
 func TestRetry_Execute(t *testing.T) {
 	newRetry := Retry{
 		retries:        3,
@@ -103,3 +111,25 @@ func TestRetry_Execute_Err(t *testing.T) {
 		t.Fatal("didn't receive expected execution counts")
 	}
 }
+
+func TestRetry_Execute_Canceled(t *testing.T) {
+	newRetry := NewRetry(3, 0).WithBackoff(time.Minute, time.Minute)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	counter := 0
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+	}()
+	start := time.Now()
+	err := newRetry.Execute(ctx, func(_ context.Context, retryCount uint) error {
+		counter++
+		return errors.New("an error")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context canceled, got %v", err)
+	}
+	if counter != 1 || time.Since(start) > time.Second {
+		t.Fatalf("Expected the backoff to end with the context, got %d executions in %s", counter, time.Since(start))
+	}
+}
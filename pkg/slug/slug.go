@@ -0,0 +1,149 @@
+package slug
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxLength leaves room for suffixes and file extensions within the 255 characters of image names
+const DefaultMaxLength = 200
+
+// maxSuffix limits the number of lookups when searching for a free slug
+const maxSuffix = 100
+
+type Mode string
+
+const (
+	// Strict fails with ErrTaken when the slug already exists
+	Strict Mode = "strict"
+	// AutoSuffix appends -2, -3... until it finds a free slug
+	AutoSuffix Mode = "auto-suffix"
+)
+
+func (mode Mode) IsValid() bool {
+	return mode == Strict || mode == AutoSuffix
+}
+
+var (
+	ErrEmpty = errors.New("slug is empty")
+	ErrTaken = errors.New("slug is already taken")
+)
+
+// Make creates an ASCII, lowercase and hyphen separated slug of at most maxLength characters, example:
+// from: _Čačak Ünïcode -- Ωμέγα 2
+// to: cacak-unicode-omega-2
+func Make(text string, maxLength int) string {
+	var builder strings.Builder
+	hasSeparator := false
+
+	for _, char := range strings.ToLower(text) {
+		if isRemoved(char) {
+			continue
+		}
+
+		value := transliterate(char)
+		if value == "" {
+			// Letters of other scripts separate the words around them
+			hasSeparator = true
+		}
+
+		for _, ascii := range value {
+			if (ascii >= 'a' && ascii <= 'z') || (ascii >= '0' && ascii <= '9') {
+				if hasSeparator && builder.Len() > 0 {
+					builder.WriteByte('-')
+				}
+				hasSeparator = false
+				builder.WriteRune(ascii)
+				continue
+			}
+			hasSeparator = true
+		}
+	}
+
+	return truncate(builder.String(), maxLength)
+}
+
+// isRemoved tells that the rune is dropped without separating words, like apostrophes and combining marks
+func isRemoved(char rune) bool {
+	if unicode.Is(unicode.Mn, char) {
+		return true
+	}
+	value, ok := transliterations[char]
+	return ok && value == ""
+}
+
+// truncate cuts the slug at the last word that fits into the max length, a single long word is cut in the middle
+func truncate(slug string, maxLength int) string {
+	if maxLength <= 0 || len(slug) <= maxLength {
+		return slug
+	}
+
+	cut := slug[:maxLength]
+	if slug[maxLength] != '-' {
+		if index := strings.LastIndexByte(cut, '-'); index > 0 {
+			cut = cut[:index]
+		}
+	}
+
+	return strings.TrimRight(cut, "-")
+}
+
+// ExistsFunc reports whether the slug is already in use
+type ExistsFunc func(ctx context.Context, slug string) (bool, error)
+
+type Generator struct {
+	exists    ExistsFunc
+	maxLength int
+}
+
+func NewGenerator(exists ExistsFunc, maxLength int) *Generator {
+	return &Generator{exists: exists, maxLength: maxLength}
+}
+
+// Generate makes a slug of the text that is not in use, depending on the mode a taken slug either fails with
+// ErrTaken or gets the first free numeric suffix
+func (generator *Generator) Generate(ctx context.Context, text string, mode Mode) (string, error) {
+	slug := Make(text, generator.maxLength)
+	if slug == "" {
+		return "", ErrEmpty
+	}
+
+	isTaken, err := generator.exists(ctx, slug)
+	if err != nil {
+		return "", err
+	}
+	if !isTaken {
+		return slug, nil
+	}
+	if mode != AutoSuffix {
+		return "", fmt.Errorf("%w: %s", ErrTaken, slug)
+	}
+
+	for suffix := 2; suffix <= maxSuffix; suffix++ {
+		candidate := WithSuffix(slug, suffix, generator.maxLength)
+
+		isTaken, err = generator.exists(ctx, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !isTaken {
+			return candidate, nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s and its first %d suffixes", ErrTaken, slug, maxSuffix)
+}
+
+// WithSuffix appends the numeric suffix, shortening the slug when needed to stay within the max length
+func WithSuffix(slug string, suffix int, maxLength int) string {
+	ending := "-" + strconv.Itoa(suffix)
+	if maxLength > 0 && len(slug)+len(ending) > maxLength {
+		slug = strings.TrimRight(slug[:maxLength-len(ending)], "-")
+	}
+
+	return slug + ending
+}
@@ -0,0 +1,108 @@
+package slug
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	values := []struct {
+		Name      string
+		Value     string
+		MaxLength int
+		Expected  string
+	}{
+		{Name: "Badly formatted data", Value: "_some1 ran----dom __test -- to add-5- ", Expected: "some1-ran-dom-test-to-add-5"},
+		{Name: "Only bad data", Value: " -_--__- _ --_ ", Expected: ""},
+		{Name: "Empty string", Value: "", Expected: ""},
+		{Name: "Latin diacritics", Value: "Čačak Ünïcode", Expected: "cacak-unicode"},
+		{Name: "Latin special letters", Value: "Straße Đurđevdan Łódź Øresund", Expected: "strasse-djurdjevdan-lodz-oresund"},
+		{Name: "Serbian cyrillic", Value: "Чачак Ђурђевдан Љубљана", Expected: "cacak-djurdjevdan-ljubljana"},
+		{Name: "Russian cyrillic", Value: "Юрий Гагарин", Expected: "jurij-gagarin"},
+		{Name: "Greek with accents", Value: "Ωμέγα Θεσσαλονίκη", Expected: "omega-thessaloniki"},
+		{Name: "Apostrophes", Value: "Don't stop", Expected: "dont-stop"},
+		{Name: "Decomposed input", Value: "čačak", Expected: "cacak"},
+		{Name: "Other scripts separate words", Value: "plane日本ship", Expected: "plane-ship"},
+		{Name: "Cut at a word", Value: "world war plane", MaxLength: 12, Expected: "world-war"},
+		{Name: "Cut exactly at a word", Value: "world war plane", MaxLength: 9, Expected: "world-war"},
+		{Name: "Cut a long word", Value: "supercalifragilistic", MaxLength: 5, Expected: "super"},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			maxLength := data.MaxLength
+			if maxLength == 0 {
+				maxLength = DefaultMaxLength
+			}
+			result := Make(data.Value, maxLength)
+			if result != data.Expected {
+				t.Fatalf("Expected %s, got %s", data.Expected, result)
+			}
+		})
+	}
+}
+
+func TestWithSuffix(t *testing.T) {
+	if result := WithSuffix("world-war", 2, 20); result != "world-war-2" {
+		t.Fatalf("Expected world-war-2, got %s", result)
+	}
+	if result := WithSuffix("world-war", 12, 10); result != "world-w-12" {
+		t.Fatalf("Expected world-w-12, got %s", result)
+	}
+	if result := WithSuffix("world-war", 3, 8); result != "world-3" {
+		t.Fatalf("Expected world-3, got %s", result)
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	taken := map[string]bool{"my-plane": true, "my-plane-2": true}
+	exists := func(_ context.Context, slug string) (bool, error) {
+		return taken[slug], nil
+	}
+	generator := NewGenerator(exists, DefaultMaxLength)
+
+	values := []struct {
+		Name        string
+		Value       string
+		Mode        Mode
+		Expected    string
+		ExpectedErr error
+	}{
+		{Name: "Free slug", Value: "My Ship", Mode: Strict, Expected: "my-ship"},
+		{Name: "Taken in strict mode", Value: "My Plane", Mode: Strict, ExpectedErr: ErrTaken},
+		{Name: "Taken in auto suffix mode", Value: "My Plane", Mode: AutoSuffix, Expected: "my-plane-3"},
+		{Name: "Empty", Value: "!!!", Mode: AutoSuffix, ExpectedErr: ErrEmpty},
+	}
+
+	for _, data := range values {
+		t.Run(data.Name, func(t *testing.T) {
+			result, err := generator.Generate(context.Background(), data.Value, data.Mode)
+			if data.ExpectedErr != nil {
+				if !errors.Is(err, data.ExpectedErr) {
+					t.Fatalf("Expected %v, got %v", data.ExpectedErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != data.Expected {
+				t.Fatalf("Expected %s, got %s", data.Expected, result)
+			}
+		})
+	}
+}
+
+func TestGenerator_GenerateAllTaken(t *testing.T) {
+	exists := func(_ context.Context, slug string) (bool, error) {
+		return strings.HasPrefix(slug, "my-plane"), nil
+	}
+	generator := NewGenerator(exists, DefaultMaxLength)
+
+	_, err := generator.Generate(context.Background(), "my plane", AutoSuffix)
+	if !errors.Is(err, ErrTaken) {
+		t.Fatalf("Expected %v, got %v", ErrTaken, err)
+	}
+}
@@ -0,0 +1,56 @@
+package slug
+
+import (
+	"golang.org/x/text/unicode/norm"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// transliterations of letters that do not decompose into an ASCII letter with diacritics. Cyrillic follows the
+// Serbian latinization, so "Чачак" and "Čačak" produce the same slug.
+var transliterations = map[rune]string{
+	// Latin
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "dj", 'ł': "l", 'þ': "th", 'ð': "d", 'ħ': "h", 'ı': "i",
+	'ŋ': "n", 'ĸ': "k", 'ŀ': "l", 'ŉ': "n", 'ſ': "s", 'ŧ': "t",
+	// Cyrillic
+	'а': "a", 'б': "b", 'в': "v", 'г': "g", 'ґ': "g", 'д': "d", 'ђ': "dj", 'е': "e", 'ё': "e", 'є': "je",
+	'ж': "z", 'з': "z", 'и': "i", 'і': "i", 'ї': "ji", 'й': "j", 'ј': "j", 'к': "k", 'л': "l", 'љ': "lj",
+	'м': "m", 'н': "n", 'њ': "nj", 'о': "o", 'п': "p", 'р': "r", 'с': "s", 'т': "t", 'ћ': "c", 'у': "u",
+	'ў': "u", 'ф': "f", 'х': "h", 'ц': "c", 'ч': "c", 'џ': "dz", 'ш': "s", 'щ': "sc", 'ъ': "", 'ы': "y",
+	'ь': "", 'э': "e", 'ю': "ju", 'я': "ja",
+	// Greek
+	'α': "a", 'β': "v", 'γ': "g", 'δ': "d", 'ε': "e", 'ζ': "z", 'η': "i", 'θ': "th", 'ι': "i", 'κ': "k",
+	'λ': "l", 'μ': "m", 'ν': "n", 'ξ': "x", 'ο': "o", 'π': "p", 'ρ': "r", 'σ': "s", 'ς': "s", 'τ': "t",
+	'υ': "y", 'φ': "f", 'χ': "ch", 'ψ': "ps", 'ω': "o",
+	// Removed without separating words, so "don't" becomes "dont"
+	'\'': "", '’': "",
+}
+
+// transliterate converts a lowercase rune to ASCII, letters of other scripts become empty
+func transliterate(char rune) string {
+	if char < utf8.RuneSelf {
+		if value, ok := transliterations[char]; ok {
+			return value
+		}
+		return string(char)
+	}
+	if value, ok := transliterations[char]; ok {
+		return value
+	}
+
+	// Decomposing drops the diacritics, like "č" to "c" or "ά" to "α"
+	var builder strings.Builder
+	for _, decomposed := range norm.NFD.String(string(char)) {
+		if unicode.Is(unicode.Mn, decomposed) {
+			continue
+		}
+		if value, ok := transliterations[decomposed]; ok {
+			builder.WriteString(value)
+		} else if decomposed < utf8.RuneSelf {
+			builder.WriteRune(decomposed)
+		}
+	}
+
+	return builder.String()
+}
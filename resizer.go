@@ -0,0 +1,37 @@
+package api
+
+import (
+	"api/core"
+	"api/image"
+	"api/image/local"
+	"api/image/resize"
+	"github.com/rs/zerolog"
+)
+
+// newResizer picks where the images are resized and stored from the config, the local and s3 resizers need no
+// images API so that the whole upload flow runs on a developer machine or in CI
+func newResizer(config core.Config, logger *zerolog.Logger) (image.Resizer, error) {
+	switch config.ImagesResizer {
+	case core.LocalImagesResizer:
+		store := local.NewFileStore(config.ImagesLocalDir)
+		return local.NewResizer(
+			store, config.ImagesDomain, config.ImagesBreakpoints, int(config.ImagesMaxPixels), logger,
+		), nil
+	case core.S3ImagesResizer:
+		store, err := local.NewS3Store(local.S3Options{
+			Endpoint:        config.ImagesS3Endpoint,
+			Region:          config.AwsRegion,
+			Bucket:          config.ImagesS3Bucket,
+			AccessKeyId:     config.ImagesS3AccessKeyId,
+			SecretAccessKey: config.ImagesS3SecretAccessKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return local.NewResizer(
+			store, config.ImagesDomain, config.ImagesBreakpoints, int(config.ImagesMaxPixels), logger,
+		), nil
+	default:
+		return resize.NewClient(config, logger), nil
+	}
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+type AuditAction string
+
+const (
+	AuditImageCreated    AuditAction = "image.created"
+	AuditImageUpdated    AuditAction = "image.updated"
+	AuditImageDeleted    AuditAction = "image.deleted"
+	AuditImageRestored   AuditAction = "image.restored"
+	AuditImageTranslated AuditAction = "image.translated"
+	AuditTagCreated      AuditAction = "tag.created"
+	AuditTagUpdated      AuditAction = "tag.updated"
+	AuditTagDeleted      AuditAction = "tag.deleted"
+	AuditWebhookCreated  AuditAction = "webhook.created"
+	AuditWebhookUpdated  AuditAction = "webhook.updated"
+	AuditWebhookDeleted  AuditAction = "webhook.deleted"
+	AuditUserRoleChanged AuditAction = "user.role_changed"
+)
+
+// AuditEvent records an action of the actor on the target, Before is empty for creations and After for deletions
+type AuditEvent struct {
+	Id        string          `json:"id"`
+	ActorId   string          `json:"actorId"`
+	Action    AuditAction     `json:"action"`
+	TargetId  string          `json:"targetId"`
+	Before    json.RawMessage `json:"before"`
+	After     json.RawMessage `json:"after"`
+	RequestId string          `json:"requestId"`
+	ClientIp  string          `json:"clientIp"`
+	CreatedAt *time.Time      `json:"createdAt"`
+}
+
+type AuditEventFilter struct {
+	ActorId  string
+	Action   AuditAction
+	TargetId string
+}
+
+type AuditEventPage struct {
+	Events  []AuditEvent
+	Total   int
+	HasNext bool
+}
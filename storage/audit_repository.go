@@ -0,0 +1,9 @@
+package storage
+
+import "context"
+
+type AuditRepository interface {
+	Create(ctx context.Context, event AuditEvent) (AuditEvent, error)
+	// Get returns the events matching the filter, newest first
+	Get(ctx context.Context, filter AuditEventFilter, limit, offset int) (AuditEventPage, error)
+}
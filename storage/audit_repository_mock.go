@@ -0,0 +1,14 @@
+package storage
+
+import "context"
+
+type AuditRepoMock struct {
+}
+
+func (repo AuditRepoMock) Create(_ context.Context, event AuditEvent) (AuditEvent, error) {
+	return event, nil
+}
+
+func (repo AuditRepoMock) Get(_ context.Context, _ AuditEventFilter, _, _ int) (AuditEventPage, error) {
+	return AuditEventPage{Events: []AuditEvent{}}, nil
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points to the last item of a page by its (created_at, id) pair, clients receive it as an opaque string
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	Id        string    `json:"i"`
+}
+
+func NewCursorFrom(image Image) *Cursor {
+	if image.CreatedAt == nil {
+		return nil
+	}
+
+	return &Cursor{CreatedAt: image.CreatedAt.UTC(), Id: image.Id}
+}
+
+func (cursor Cursor) Encode() string {
+	data, _ := json.Marshal(cursor)
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func DecodeCursor(value string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var cursor Cursor
+	if err = json.Unmarshal(data, &cursor); err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if cursor.CreatedAt.IsZero() || cursor.Id == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	cursor.CreatedAt = cursor.CreatedAt.UTC()
+
+	return cursor, nil
+}
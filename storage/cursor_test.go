@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCursor_EncodeDecode(t *testing.T) {
+	createdAt := time.Date(2021, 5, 1, 10, 30, 15, 123456000, time.UTC)
+	cursor := Cursor{CreatedAt: createdAt, Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263"}
+
+	decoded, err := DecodeCursor(cursor.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !decoded.CreatedAt.Equal(createdAt) || decoded.Id != cursor.Id {
+		t.Fatalf("Expected %+v, got %+v", cursor, decoded)
+	}
+}
+
+func TestDecodeCursor_Invalid(t *testing.T) {
+	data := []struct {
+		testName string
+		value    string
+	}{
+		{testName: "Not base64", value: "%%%"},
+		{testName: "Not json", value: "bm90LWpzb24"},
+		{testName: "Missing fields", value: Cursor{}.Encode()},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			_, err := DecodeCursor(d.value)
+			if !errors.Is(err, ErrInvalidCursor) {
+				t.Fatalf("Expected invalid cursor error, got %v", err)
+			}
+		})
+	}
+}
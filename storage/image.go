@@ -16,6 +16,20 @@ type Image struct {
 	CreatedAt *time.Time  `json:"createdAt"`
 	UpdatedAt *time.Time  `json:"updatedAt"`
 	AuthorId  string      `json:"authorId"`
+	Tags      TagList     `json:"tags"`
+	ImageDescription
+	// DeletedAt is only set on the images in the trash
+	DeletedAt      *time.Time      `json:"deletedAt,omitempty"`
+	PerceptualHash *PerceptualHash `json:"perceptualHash,omitempty"`
+	// BlurHash and DominantColor are shown while the sizes load, missing on images not yet backfilled
+	BlurHash      *string `json:"blurHash,omitempty"`
+	DominantColor *string `json:"dominantColor,omitempty"`
+	// Metadata is only set when the image is created or its files are replaced, it is fetched on its own otherwise
+	Metadata *ImageMetadata `json:"metadata,omitempty"`
+	// Locale is the locale of the alt text and the caption, only set on images fetched in a locale
+	Locale string `json:"locale,omitempty"`
+	// Duplicates is only set on a newly uploaded image that looks like images already stored
+	Duplicates []SimilarImage `json:"duplicates,omitempty"`
 }
 
 func (image Image) IsEqualTo(img Image) bool {
@@ -57,6 +71,15 @@ func (image Image) toString() string {
 
 type ImageList []Image
 
+// ImagePage is a single page of images, Total counts all the images matching regardless of paging.
+// NextCursor is nil on the last page or when the page can not be continued by keyset.
+type ImagePage struct {
+	Images     ImageList
+	Total      int
+	HasNext    bool
+	NextCursor *Cursor
+}
+
 type ImagePredicate func(img Image) bool
 
 func (images ImageList) findBy(predicate ImagePredicate) *Image {
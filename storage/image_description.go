@@ -0,0 +1,42 @@
+package storage
+
+// ImageDescription are the texts shown with the image, the alt text is read out by screen readers in place of it
+type ImageDescription struct {
+	AltText string `json:"altText"`
+	Title   string `json:"title"`
+	Caption string `json:"caption"`
+	Credit  string `json:"credit"`
+	// License is one of the license codes, empty when not set
+	License string `json:"license"`
+}
+
+// ImageDescriptionUpdate changes only the fields that are set, a field set to empty clears it
+type ImageDescriptionUpdate struct {
+	AltText *string
+	Title   *string
+	Caption *string
+	Credit  *string
+	License *string
+}
+
+func (update ImageDescriptionUpdate) IsEmpty() bool {
+	return update.AltText == nil &&
+		update.Title == nil &&
+		update.Caption == nil &&
+		update.Credit == nil &&
+		update.License == nil
+}
+
+func (update ImageDescriptionUpdate) ApplyTo(description ImageDescription) ImageDescription {
+	apply := func(field *string, value *string) {
+		if value != nil {
+			*field = *value
+		}
+	}
+	apply(&description.AltText, update.AltText)
+	apply(&description.Title, update.Title)
+	apply(&description.Caption, update.Caption)
+	apply(&description.Credit, update.Credit)
+	apply(&description.License, update.License)
+	return description
+}
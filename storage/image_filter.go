@@ -0,0 +1,25 @@
+package storage
+
+import "time"
+
+// ImageFilter narrows down the image list, all set fields are combined with AND
+type ImageFilter struct {
+	Tag           string
+	Format        ImageFormat
+	AuthorId      string
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	// CapturedAfter and CapturedBefore match the capture date from the EXIF, images without one never match
+	CapturedAfter  *time.Time
+	CapturedBefore *time.Time
+}
+
+func (filter ImageFilter) IsEmpty() bool {
+	return filter.Tag == "" &&
+		filter.Format == "" &&
+		filter.AuthorId == "" &&
+		filter.CreatedAfter == nil &&
+		filter.CreatedBefore == nil &&
+		filter.CapturedAfter == nil &&
+		filter.CapturedBefore == nil
+}
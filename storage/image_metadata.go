@@ -0,0 +1,25 @@
+package storage
+
+import "time"
+
+// ImageMetadata is the sanitized subset of the EXIF of the original file, empty for files without one
+type ImageMetadata struct {
+	CameraMake  string `json:"cameraMake,omitempty"`
+	CameraModel string `json:"cameraModel,omitempty"`
+	LensModel   string `json:"lensModel,omitempty"`
+	// ExposureTime is in seconds, written as a fraction such as 1/250 below one second
+	ExposureTime string  `json:"exposureTime,omitempty"`
+	FNumber      float64 `json:"fNumber,omitempty"`
+	FocalLength  float64 `json:"focalLength,omitempty"`
+	Iso          int     `json:"iso,omitempty"`
+	// CapturedAt is in UTC, images can be filtered by it
+	CapturedAt  *time.Time `json:"capturedAt,omitempty"`
+	Orientation int        `json:"orientation,omitempty"`
+	// Gps is only kept when the configuration allows it
+	Gps *GpsPosition `json:"gps,omitempty"`
+}
+
+type GpsPosition struct {
+	Latitude  float64 `json:"latitude"`
+	Longitude float64 `json:"longitude"`
+}
@@ -2,15 +2,53 @@ package storage
 
 import (
 	"context"
+	"time"
 )
 
 type ImagesRepository interface {
-	Get(ctx context.Context, limit, offset int, order Order) (ImageList, error)
+	Get(ctx context.Context, paging Paging, filter ImageFilter) (ImagePage, error)
+	Search(ctx context.Context, text string, limit, offset int) (ImagePage, error)
+	// GetSimilar returns the images whose perceptual hash is within maxDistance bits of the hash, closest first
+	GetSimilar(
+		ctx context.Context, hash PerceptualHash, maxDistance int, excludeId string, limit int,
+	) ([]SimilarImage, error)
 	GetOne(ctx context.Context, imageId string) (Image, error)
 	GetOneByName(ctx context.Context, name string) (Image, error)
+	GetOneByOldName(ctx context.Context, slug string) (Image, error)
 	DoesImageExist(ctx context.Context, name string) (bool, error)
 	Create(ctx context.Context, image Image) (Image, error)
 	SetNameById(ctx context.Context, imageId, newName string) (Image, error)
 	UpdateOne(ctx context.Context, updates Image) error
+	// ReplaceFiles updates the image like UpdateOne, keeping its current files as the next version. The replaced
+	// version tells where the files were moved, its empty paths keep the ones of the image.
+	ReplaceFiles(ctx context.Context, updates Image, replaced ImageVersion) error
+	// RestoreVersion replaces the files like ReplaceFiles and removes the restored version, its files are the ones of
+	// the image again
+	RestoreVersion(ctx context.Context, updates Image, replaced ImageVersion, version int) error
+	// GetVersions returns the previous versions of the image, newest first
+	GetVersions(ctx context.Context, imageId string) ([]ImageVersion, error)
+	GetVersion(ctx context.Context, imageId string, version int) (ImageVersion, error)
+	// DeleteOne moves the image to the trash, where it is hidden from every other query until restored or purged
 	DeleteOne(ctx context.Context, imageId string) error
+	// GetDeleted returns a page of the trash, most recently deleted first
+	GetDeleted(ctx context.Context, limit, offset int) (ImagePage, error)
+	Restore(ctx context.Context, imageId string) (Image, error)
+	// GetDeletedBefore returns the oldest images in the trash that were deleted before the time
+	GetDeletedBefore(ctx context.Context, before time.Time, limit int) (ImageList, error)
+	// Purge removes the image from the trash for good
+	Purge(ctx context.Context, imageId string) error
+	// GetWithoutPlaceholders returns, by id, the images after afterId that were stored before their placeholders
+	// were computed on upload
+	GetWithoutPlaceholders(ctx context.Context, afterId string, limit int) (ImageList, error)
+	// SetPlaceholders stores the placeholders computed for the image, the image itself is not updated
+	SetPlaceholders(ctx context.Context, imageId, blurHash, dominantColor string) error
+	// SetDescription overwrites the texts describing the image
+	SetDescription(ctx context.Context, imageId string, description ImageDescription) (Image, error)
+	// GetTranslations returns the translations of the images ordered by image and locale
+	GetTranslations(ctx context.Context, imageIds ...string) ([]ImageTranslation, error)
+	// SetTranslation creates or overwrites the translation of the image in its locale
+	SetTranslation(ctx context.Context, translation ImageTranslation) (ImageTranslation, error)
+	DeleteTranslation(ctx context.Context, imageId, locale string) error
+	// GetMetadata returns the metadata read from the original file of the image, empty when it had none
+	GetMetadata(ctx context.Context, imageId string) (ImageMetadata, error)
 }
@@ -2,14 +2,16 @@ package storage
 
 import (
 	"context"
+	"fmt"
+	"time"
 )
 
 type ImageRepoMock struct {
 }
 
 func (repo ImageRepoMock) Get(
-	_ context.Context, _, _ int, _ Order,
-) (ImageList, error) {
+	_ context.Context, _ Paging, _ ImageFilter,
+) (ImagePage, error) {
 	images := ImageList{
 		{
 			Id:       "3c47d736-6c4e-4a1c-a04b-3744cc30b263",
@@ -41,7 +43,17 @@ func (repo ImageRepoMock) Get(
 		},
 	}
 
-	return images, nil
+	return ImagePage{Images: images, Total: len(images)}, nil
+}
+
+func (repo ImageRepoMock) Search(_ context.Context, _ string, _, _ int) (ImagePage, error) {
+	return ImagePage{Images: ImageList{}}, nil
+}
+
+func (repo ImageRepoMock) GetSimilar(
+	_ context.Context, _ PerceptualHash, _ int, _ string, _ int,
+) ([]SimilarImage, error) {
+	return []SimilarImage{}, nil
 }
 
 func (repo ImageRepoMock) GetOne(_ context.Context, _ string) (Image, error) {
@@ -52,6 +64,10 @@ func (repo ImageRepoMock) GetOneByName(_ context.Context, _ string) (Image, erro
 	return Image{}, nil
 }
 
+func (repo ImageRepoMock) GetOneByOldName(_ context.Context, _ string) (Image, error) {
+	return Image{}, NotFound{}
+}
+
 func (repo ImageRepoMock) DoesImageExist(_ context.Context, _ string) (bool, error) {
 	return false, nil
 }
@@ -68,6 +84,68 @@ func (repo ImageRepoMock) UpdateOne(_ context.Context, _ Image) error {
 	return nil
 }
 
+func (repo ImageRepoMock) ReplaceFiles(_ context.Context, _ Image, _ ImageVersion) error {
+	return nil
+}
+
+func (repo ImageRepoMock) RestoreVersion(_ context.Context, _ Image, _ ImageVersion, _ int) error {
+	return nil
+}
+
+func (repo ImageRepoMock) GetVersions(_ context.Context, _ string) ([]ImageVersion, error) {
+	return []ImageVersion{}, nil
+}
+
+func (repo ImageRepoMock) GetVersion(_ context.Context, imageId string, version int) (ImageVersion, error) {
+	return ImageVersion{}, NotFound{Msg: fmt.Sprintf("Version %d of image %s not found", version, imageId)}
+}
+
 func (repo ImageRepoMock) DeleteOne(_ context.Context, _ string) error {
 	return nil
 }
+
+func (repo ImageRepoMock) GetDeleted(_ context.Context, _, _ int) (ImagePage, error) {
+	return ImagePage{Images: ImageList{}}, nil
+}
+
+func (repo ImageRepoMock) Restore(_ context.Context, _ string) (Image, error) {
+	return Image{}, nil
+}
+
+func (repo ImageRepoMock) GetDeletedBefore(_ context.Context, _ time.Time, _ int) (ImageList, error) {
+	return ImageList{}, nil
+}
+
+func (repo ImageRepoMock) Purge(_ context.Context, _ string) error {
+	return nil
+}
+
+func (repo ImageRepoMock) GetWithoutPlaceholders(_ context.Context, _ string, _ int) (ImageList, error) {
+	return ImageList{}, nil
+}
+
+func (repo ImageRepoMock) SetPlaceholders(_ context.Context, _, _, _ string) error {
+	return nil
+}
+
+func (repo ImageRepoMock) GetMetadata(_ context.Context, _ string) (ImageMetadata, error) {
+	return ImageMetadata{}, nil
+}
+
+func (repo ImageRepoMock) SetDescription(
+	_ context.Context, imageId string, description ImageDescription,
+) (Image, error) {
+	return Image{Id: imageId, ImageDescription: description}, nil
+}
+
+func (repo ImageRepoMock) GetTranslations(_ context.Context, _ ...string) ([]ImageTranslation, error) {
+	return []ImageTranslation{}, nil
+}
+
+func (repo ImageRepoMock) SetTranslation(_ context.Context, translation ImageTranslation) (ImageTranslation, error) {
+	return translation, nil
+}
+
+func (repo ImageRepoMock) DeleteTranslation(_ context.Context, _, _ string) error {
+	return nil
+}
@@ -0,0 +1,42 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"strconv"
+)
+
+// PerceptualHash is the dHash of the cropped file of an image, the hashes of images that look alike differ in few
+// bits. The 64 bits are kept in an int64 to fit a BIGINT column and are shown as hex to clients.
+type PerceptualHash int64
+
+// Distance is the number of bits in which the hashes differ, from 0 for the same picture up to 64
+func (hash PerceptualHash) Distance(other PerceptualHash) int {
+	return bits.OnesCount64(uint64(hash ^ other))
+}
+
+func (hash PerceptualHash) MarshalJSON() ([]byte, error) {
+	return json.Marshal(fmt.Sprintf("%016x", uint64(hash)))
+}
+
+func (hash *PerceptualHash) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	parsed, err := strconv.ParseUint(value, 16, 64)
+	if err != nil {
+		return fmt.Errorf("invalid perceptual hash %s: %w", value, err)
+	}
+	*hash = PerceptualHash(parsed)
+
+	return nil
+}
+
+// SimilarImage is an image that looks like another one, Distance being the bits in which their hashes differ
+type SimilarImage struct {
+	Image
+	Distance int `json:"distance"`
+}
@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestPerceptualHash_Distance(t *testing.T) {
+	data := []struct {
+		testName string
+		hash     PerceptualHash
+		other    PerceptualHash
+		distance int
+	}{
+		{testName: "Same", hash: 0x0f0f, other: 0x0f0f, distance: 0},
+		{testName: "Few bits", hash: 0x0f0f, other: 0x0f0e, distance: 1},
+		{testName: "Sign bit", hash: -1, other: 0x7fffffffffffffff, distance: 1},
+		{testName: "All bits", hash: 0, other: -1, distance: 64},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			if distance := d.hash.Distance(d.other); distance != d.distance {
+				t.Fatalf("Expected distance %d, got %d", d.distance, distance)
+			}
+		})
+	}
+}
+
+func TestPerceptualHash_Json(t *testing.T) {
+	hash := PerceptualHash(-0x0123456789abcdef)
+
+	data, err := json.Marshal(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `"fedcba9876543211"` {
+		t.Fatalf("Expected the hash as hex, got %s", data)
+	}
+
+	var decoded PerceptualHash
+	if err = json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded != hash {
+		t.Fatalf("Expected %d, got %d", hash, decoded)
+	}
+}
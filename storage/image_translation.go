@@ -0,0 +1,15 @@
+package storage
+
+import "time"
+
+// ImageTranslation are the texts of an image in a locale other than the default one, the texts in the default
+// locale are the description of the image itself
+type ImageTranslation struct {
+	ImageId string `json:"imageId"`
+	// Locale is a BCP 47 language tag such as de or pt-BR
+	Locale    string     `json:"locale"`
+	AltText   string     `json:"altText"`
+	Caption   string     `json:"caption"`
+	CreatedAt *time.Time `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
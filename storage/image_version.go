@@ -0,0 +1,43 @@
+package storage
+
+import "time"
+
+// ImageVersion is a previous state of the files of an image, kept when an update replaced them. Versions of an
+// image are numbered from 1 in the order they were replaced.
+type ImageVersion struct {
+	ImageId   string      `json:"imageId"`
+	Version   int         `json:"version"`
+	Name      string      `json:"name"`
+	Format    ImageFormat `json:"format"`
+	Original  string      `json:"original"`
+	Domain    string      `json:"domain"`
+	Path      string      `json:"path"`
+	Sizes     ImageSizes  `json:"sizes"`
+	CreatedAt time.Time   `json:"createdAt"`
+	// FileName is the name the files of the version are stored under, Name is the one the image had
+	FileName string `json:"-"`
+	// PerceptualHash is missing on versions replaced before the hashes were computed
+	PerceptualHash *PerceptualHash `json:"perceptualHash,omitempty"`
+	BlurHash       *string         `json:"blurHash,omitempty"`
+	DominantColor  *string         `json:"dominantColor,omitempty"`
+	Metadata       *ImageMetadata  `json:"metadata,omitempty"`
+}
+
+// ApplyTo returns the image with the files of the version, the image keeps its name since the files are moved back
+// under it
+func (version ImageVersion) ApplyTo(img Image) Image {
+	img.Format = version.Format
+	img.Original = version.Original
+	img.Domain = version.Domain
+	img.Path = version.Path
+	img.Sizes = version.Sizes
+	img.PerceptualHash = version.PerceptualHash
+	img.BlurHash = version.BlurHash
+	img.DominantColor = version.DominantColor
+	// Versions replaced before the metadata was read get none, rather than keeping the one of the replaced file
+	img.Metadata = version.Metadata
+	if img.Metadata == nil {
+		img.Metadata = &ImageMetadata{}
+	}
+	return img
+}
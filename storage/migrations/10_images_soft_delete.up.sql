@@ -0,0 +1,3 @@
+-- Deleted images stay in the trash until they are purged, names stay unique so that they can always be restored
+ALTER TABLE images ADD COLUMN IF NOT EXISTS deleted_at timestamp;
+CREATE INDEX IF NOT EXISTS idx_images_deletedAt ON images (deleted_at) WHERE deleted_at IS NOT NULL;
@@ -0,0 +1,20 @@
+-- IMAGE_VERSIONS, previous files of images replaced by an update so that they can be restored. The files of a
+-- version are stored under a file name of their own, so that an update keeping the name does not overwrite them.
+CREATE TABLE IF NOT EXISTS image_versions
+(
+    image_id   UUID         NOT NULL,
+    version    INTEGER      NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    file_name  VARCHAR(255) NOT NULL,
+    format     VARCHAR(30)  NOT NULL,
+    original   VARCHAR(255) NOT NULL,
+    domain     VARCHAR(255) NOT NULL,
+    path       VARCHAR(255) NOT NULL,
+    sizes      jsonb        NOT NULL,
+    created_at timestamp    NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (image_id, version),
+    CONSTRAINT image_fk
+        FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_image_versions_file_name ON image_versions (file_name);
@@ -0,0 +1,2 @@
+ALTER TABLE image_versions DROP COLUMN IF EXISTS phash;
+ALTER TABLE images DROP COLUMN IF EXISTS phash;
@@ -0,0 +1,3 @@
+-- Perceptual hash of the cropped file, images whose hashes differ in few bits look alike
+ALTER TABLE images ADD COLUMN IF NOT EXISTS phash BIGINT;
+ALTER TABLE image_versions ADD COLUMN IF NOT EXISTS phash BIGINT;
@@ -0,0 +1,4 @@
+ALTER TABLE image_versions DROP COLUMN IF EXISTS dominant_color;
+ALTER TABLE image_versions DROP COLUMN IF EXISTS blur_hash;
+ALTER TABLE images DROP COLUMN IF EXISTS dominant_color;
+ALTER TABLE images DROP COLUMN IF EXISTS blur_hash;
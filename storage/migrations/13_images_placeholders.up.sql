@@ -0,0 +1,5 @@
+-- Placeholders shown while the sizes of an image load, computed from the cropped file
+ALTER TABLE images ADD COLUMN IF NOT EXISTS blur_hash VARCHAR(64);
+ALTER TABLE images ADD COLUMN IF NOT EXISTS dominant_color CHAR(7);
+ALTER TABLE image_versions ADD COLUMN IF NOT EXISTS blur_hash VARCHAR(64);
+ALTER TABLE image_versions ADD COLUMN IF NOT EXISTS dominant_color CHAR(7);
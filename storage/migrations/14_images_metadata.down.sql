@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_images_captured_at;
+ALTER TABLE image_versions DROP COLUMN IF EXISTS captured_at;
+ALTER TABLE image_versions DROP COLUMN IF EXISTS metadata;
+ALTER TABLE images DROP COLUMN IF EXISTS captured_at;
+ALTER TABLE images DROP COLUMN IF EXISTS metadata;
@@ -0,0 +1,6 @@
+-- Sanitized EXIF of the original file, the capture date is kept in its own column to filter by it
+ALTER TABLE images ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE images ADD COLUMN IF NOT EXISTS captured_at TIMESTAMP;
+ALTER TABLE image_versions ADD COLUMN IF NOT EXISTS metadata JSONB;
+ALTER TABLE image_versions ADD COLUMN IF NOT EXISTS captured_at TIMESTAMP;
+CREATE INDEX IF NOT EXISTS idx_images_captured_at ON images (captured_at);
@@ -0,0 +1,12 @@
+DROP INDEX IF EXISTS idx_images_search_vector;
+ALTER TABLE images DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE images
+    ADD COLUMN search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('simple', translate(name, '-_', '  '))) STORED;
+CREATE INDEX IF NOT EXISTS idx_images_search_vector ON images USING GIN (search_vector);
+
+ALTER TABLE images DROP COLUMN IF EXISTS license;
+ALTER TABLE images DROP COLUMN IF EXISTS credit;
+ALTER TABLE images DROP COLUMN IF EXISTS caption;
+ALTER TABLE images DROP COLUMN IF EXISTS title;
+ALTER TABLE images DROP COLUMN IF EXISTS alt_text;
@@ -0,0 +1,21 @@
+-- Texts describing the image for accessibility and attribution, all of them are searched along with the name
+ALTER TABLE images ADD COLUMN IF NOT EXISTS alt_text VARCHAR(250) NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN IF NOT EXISTS title VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN IF NOT EXISTS caption VARCHAR(1000) NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN IF NOT EXISTS credit VARCHAR(200) NOT NULL DEFAULT '';
+ALTER TABLE images ADD COLUMN IF NOT EXISTS license VARCHAR(32) NOT NULL DEFAULT '';
+
+-- The name and the title rank above the other texts
+DROP INDEX IF EXISTS idx_images_search_vector;
+ALTER TABLE images DROP COLUMN IF EXISTS search_vector;
+ALTER TABLE images
+    ADD COLUMN search_vector tsvector
+        GENERATED ALWAYS AS (
+            setweight(to_tsvector('simple', translate(name, '-_', '  ')), 'A') ||
+            setweight(to_tsvector('simple', title), 'A') ||
+            setweight(to_tsvector('simple', alt_text), 'B') ||
+            setweight(to_tsvector('simple', caption), 'C') ||
+            setweight(to_tsvector('simple', credit), 'D')
+        ) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_images_search_vector ON images USING GIN (search_vector);
@@ -0,0 +1,14 @@
+-- IMAGE_TRANSLATIONS, alt texts and captions of images in locales other than the default one
+CREATE TABLE IF NOT EXISTS image_translations
+(
+    image_id   UUID          NOT NULL,
+    locale     VARCHAR(35)   NOT NULL,
+    alt_text   VARCHAR(250)  NOT NULL DEFAULT '',
+    caption    VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at timestamp     NOT NULL DEFAULT now(),
+    updated_at timestamp     NOT NULL DEFAULT now(),
+
+    PRIMARY KEY (image_id, locale),
+    CONSTRAINT image_fk
+        FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
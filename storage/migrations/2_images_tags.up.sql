@@ -0,0 +1,21 @@
+-- IMAGES_TAGS
+-- Remove duplicated pairs before enforcing uniqueness
+DELETE
+FROM images_tags duplicate
+    USING images_tags kept
+WHERE duplicate.tag_id = kept.tag_id
+  AND duplicate.image_id = kept.image_id
+  AND duplicate.id > kept.id;
+
+ALTER TABLE images_tags
+    DROP CONSTRAINT IF EXISTS tag_fk,
+    DROP CONSTRAINT IF EXISTS image_fk;
+
+ALTER TABLE images_tags
+    ADD CONSTRAINT tag_fk
+        FOREIGN KEY (tag_id) REFERENCES tags (id) ON DELETE CASCADE,
+    ADD CONSTRAINT image_fk
+        FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_images_tags_unique ON images_tags (tag_id, image_id);
+CREATE INDEX IF NOT EXISTS idx_images_tags_imageId ON images_tags (image_id);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_images_search_vector;
+
+ALTER TABLE images
+    DROP COLUMN IF EXISTS search_vector;
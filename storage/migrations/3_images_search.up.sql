@@ -0,0 +1,6 @@
+-- IMAGES full-text search over names, hyphens and underscores of SEO names are treated as spaces
+ALTER TABLE images
+    ADD COLUMN IF NOT EXISTS search_vector tsvector
+        GENERATED ALWAYS AS (to_tsvector('simple', translate(name, '-_', '  '))) STORED;
+
+CREATE INDEX IF NOT EXISTS idx_images_search_vector ON images USING GIN (search_vector);
@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_images_createdAt_id ON images (created_at, id);
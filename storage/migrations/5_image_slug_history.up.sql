@@ -0,0 +1,12 @@
+-- IMAGE_SLUG_HISTORY, previous names of renamed images so that old links can be redirected
+CREATE TABLE IF NOT EXISTS image_slug_history
+(
+    id         UUID PRIMARY KEY    NOT NULL DEFAULT uuid_generate_v4(),
+    image_id   UUID                NOT NULL,
+    slug       VARCHAR(255) UNIQUE NOT NULL,
+    created_at timestamp           NOT NULL DEFAULT now(),
+
+    CONSTRAINT image_fk
+        FOREIGN KEY (image_id) REFERENCES images (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_image_slug_history_imageId ON image_slug_history (image_id);
@@ -0,0 +1,18 @@
+-- UPLOAD_SAGAS, steps of image uploads so that the ones interrupted by a failure or a crash can be rolled back. An
+-- unfinished upload reserves its image name so that no other upload resizes over its files.
+CREATE TABLE IF NOT EXISTS upload_sagas
+(
+    id         UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    image_name VARCHAR(255)     NOT NULL,
+    format     VARCHAR(30)      NOT NULL DEFAULT '',
+    author_id  UUID             NOT NULL,
+    step       VARCHAR(30)      NOT NULL,
+    image      jsonb,
+    error      TEXT             NOT NULL DEFAULT '',
+    created_at timestamp        NOT NULL DEFAULT now(),
+    updated_at timestamp        NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_upload_sagas_unfinished ON upload_sagas (updated_at)
+    WHERE step IN ('started', 'resized');
+CREATE UNIQUE INDEX IF NOT EXISTS idx_upload_sagas_reserved_name ON upload_sagas (image_name)
+    WHERE step IN ('started', 'resized');
@@ -0,0 +1,17 @@
+-- OUTBOX, image lifecycle events written with the change they describe and published by the relay. Events are
+-- leased to one relay at a time and given up on after too many failed attempts.
+CREATE TABLE IF NOT EXISTS outbox
+(
+    id           UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    seq          BIGSERIAL        NOT NULL,
+    event_type   VARCHAR(100)     NOT NULL,
+    image_id     UUID             NOT NULL,
+    payload      jsonb            NOT NULL,
+    created_at   timestamp        NOT NULL DEFAULT now(),
+    published_at timestamp,
+    attempts     INTEGER          NOT NULL DEFAULT 0,
+    last_error   TEXT             NOT NULL DEFAULT '',
+    locked_until timestamp,
+    dead_at      timestamp
+);
+CREATE INDEX IF NOT EXISTS idx_outbox_unpublished ON outbox (seq) WHERE published_at IS NULL AND dead_at IS NULL;
@@ -0,0 +1,30 @@
+-- WEBHOOK_SUBSCRIPTIONS, partner endpoints receiving the image events, no events means all of them
+CREATE TABLE IF NOT EXISTS webhook_subscriptions
+(
+    id         UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    url        VARCHAR(2000)    NOT NULL,
+    secret     VARCHAR(255)     NOT NULL,
+    events     TEXT[]           NOT NULL DEFAULT '{}',
+    created_at timestamp        NOT NULL DEFAULT now(),
+    updated_at timestamp
+);
+
+-- WEBHOOK_DELIVERIES, log of the delivered events including the retries
+CREATE TABLE IF NOT EXISTS webhook_deliveries
+(
+    id              UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    subscription_id UUID             NOT NULL,
+    event_id        UUID             NOT NULL,
+    event_type      VARCHAR(100)     NOT NULL,
+    payload         jsonb            NOT NULL,
+    status_code     INTEGER          NOT NULL DEFAULT 0,
+    success         BOOLEAN          NOT NULL,
+    attempts        INTEGER          NOT NULL,
+    error           TEXT             NOT NULL DEFAULT '',
+    created_at      timestamp        NOT NULL DEFAULT now(),
+
+    CONSTRAINT subscription_fk
+        FOREIGN KEY (subscription_id) REFERENCES webhook_subscriptions (id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_subscriptionId ON webhook_deliveries (subscription_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_eventId ON webhook_deliveries (event_id);
@@ -0,0 +1,16 @@
+-- AUDIT_EVENTS, who changed what through the admin actions, with the state before and after the change
+CREATE TABLE IF NOT EXISTS audit_events
+(
+    id         UUID PRIMARY KEY NOT NULL DEFAULT uuid_generate_v4(),
+    actor_id   UUID,
+    action     VARCHAR(100)     NOT NULL,
+    target_id  UUID             NOT NULL,
+    before     jsonb,
+    after      jsonb,
+    request_id VARCHAR(100)     NOT NULL DEFAULT '',
+    client_ip  VARCHAR(100)     NOT NULL DEFAULT '',
+    created_at timestamp        NOT NULL DEFAULT now()
+);
+CREATE INDEX IF NOT EXISTS idx_audit_events_createdAt ON audit_events (created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_actorId ON audit_events (actor_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_audit_events_targetId ON audit_events (target_id, created_at);
@@ -0,0 +1,22 @@
+package storage
+
+import "time"
+
+type EventType string
+
+const (
+	EventImageCreated  EventType = "image.created"
+	EventImageUpdated  EventType = "image.updated"
+	EventImageDeleted  EventType = "image.deleted"
+	EventImageRestored EventType = "image.restored"
+)
+
+// OutboxEvent is an image change waiting to be published, the payload is the image JSON after the change
+type OutboxEvent struct {
+	Id        string
+	Type      EventType
+	ImageId   string
+	Payload   []byte
+	CreatedAt time.Time
+	Attempts  int
+}
@@ -0,0 +1,21 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxRepository reads the events that ImagesRepository writes together with the image changes
+type OutboxRepository interface {
+	// ClaimUnpublished leases the oldest unpublished events for the lease duration and returns them in the order they
+	// were written. Events leased by another relay and the later events of their images are left out.
+	ClaimUnpublished(ctx context.Context, limit int, lease time.Duration) ([]OutboxEvent, error)
+	MarkPublished(ctx context.Context, eventId string) error
+	// MarkFailed records the failed attempt and keeps the event, with the later events of its image, leased until
+	// retryAfter passes
+	MarkFailed(ctx context.Context, eventId string, reason string, retryAfter time.Duration) error
+	// MarkDead records the failed attempt and gives up on publishing the event
+	MarkDead(ctx context.Context, eventId string, reason string) error
+	// Release ends the lease of events that were claimed but not published
+	Release(ctx context.Context, eventIds ...string) error
+}
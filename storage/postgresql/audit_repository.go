@@ -0,0 +1,161 @@
+package postgresql
+
+import (
+	"api/storage"
+	"context"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/sync/errgroup"
+	"strings"
+)
+
+type AuditRepo struct {
+	database *Database
+}
+
+func NewAuditRepository(db *Database) *AuditRepo {
+	return &AuditRepo{database: db}
+}
+
+func (repo *AuditRepo) Create(ctx context.Context, event storage.AuditEvent) (storage.AuditEvent, error) {
+	query := `INSERT INTO audit_events
+ ("actor_id", "action", "target_id", "before", "after", "request_id", "client_ip")
+ VALUES ($1, $2, $3, $4, $5, $6, $7)
+ RETURNING id, created_at
+`
+	err := repo.database.dbPool.QueryRow(
+		ctx,
+		query,
+		nullableString(event.ActorId),
+		event.Action,
+		event.TargetId,
+		nullableJson(event.Before),
+		nullableJson(event.After),
+		event.RequestId,
+		event.ClientIp,
+	).Scan(&event.Id, &event.CreatedAt)
+	if err != nil {
+		return storage.AuditEvent{}, err
+	}
+
+	return event, nil
+}
+
+func (repo *AuditRepo) Get(
+	ctx context.Context, filter storage.AuditEventFilter, limit, offset int,
+) (storage.AuditEventPage, error) {
+	var events []storage.AuditEvent
+	var total int
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		where, args := auditEventConditions(filter, []interface{}{limit + 1, offset})
+		query := `SELECT id, coalesce(actor_id::text, ''), action, target_id, before, after, request_id, client_ip,
+ created_at
+ FROM audit_events` + where + `
+ ORDER BY created_at DESC, id DESC
+ LIMIT $1
+ OFFSET $2
+`
+		rows, err := repo.database.dbPool.Query(gCtx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed querying audit events: %w", err)
+		}
+		defer rows.Close()
+
+		events, err = scanAuditEvents(rows)
+		return err
+	})
+
+	g.Go(func() error {
+		where, args := auditEventConditions(filter, nil)
+		query := "SELECT count(*) FROM audit_events" + where
+		if err := repo.database.dbPool.QueryRow(gCtx, query, args...).Scan(&total); err != nil {
+			return fmt.Errorf("failed counting audit events: %w", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return storage.AuditEventPage{}, err
+	}
+
+	if len(events) <= limit {
+		return storage.AuditEventPage{Events: events, Total: total}, nil
+	}
+
+	return storage.AuditEventPage{Events: events[:limit], Total: total, HasNext: true}, nil
+}
+
+func (repo *AuditRepo) DeleteAll(ctx context.Context) (int64, error) {
+	tag, err := repo.database.dbPool.Exec(ctx, "DELETE FROM audit_events")
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// auditEventConditions appends the filter values to args and returns the matching WHERE clause
+func auditEventConditions(filter storage.AuditEventFilter, args []interface{}) (string, []interface{}) {
+	var conditions []string
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.ActorId != "" {
+		addCondition("actor_id = $%d", filter.ActorId)
+	}
+	if filter.Action != "" {
+		addCondition("action = $%d", filter.Action)
+	}
+	if filter.TargetId != "" {
+		addCondition("target_id = $%d", filter.TargetId)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanAuditEvents(rows pgx.Rows) ([]storage.AuditEvent, error) {
+	events := []storage.AuditEvent{}
+
+	for rows.Next() {
+		var event storage.AuditEvent
+		var before, after []byte
+		err := rows.Scan(
+			&event.Id, &event.ActorId, &event.Action, &event.TargetId, &before, &after,
+			&event.RequestId, &event.ClientIp, &event.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		event.Before = before
+		event.After = after
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+// nullableString stores an empty value as NULL
+func nullableString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+// nullableJson stores a missing document as NULL
+func nullableJson(value []byte) interface{} {
+	if len(value) == 0 {
+		return nil
+	}
+	return string(value)
+}
@@ -0,0 +1,48 @@
+package postgresql
+
+import (
+	"api/storage"
+	"api/test"
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestAuditRepo_Get(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	db, err := setupDb(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewAuditRepository(db)
+	defer func() {
+		if _, err := repo.DeleteAll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	targetId := "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+	events := []storage.AuditEvent{
+		{Action: storage.AuditImageCreated, TargetId: targetId, After: json.RawMessage(`{"name":"my-plane"}`)},
+		{Action: storage.AuditImageDeleted, TargetId: targetId, Before: json.RawMessage(`{"name":"my-plane"}`)},
+	}
+	for _, event := range events {
+		if _, err = repo.Create(ctx, event); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	page, err := repo.Get(ctx, storage.AuditEventFilter{TargetId: targetId}, 1, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || !page.HasNext || len(page.Events) != 1 {
+		t.Fatalf("Expected first of 2 events, got %+v", page)
+	}
+	if page.Events[0].Action != storage.AuditImageDeleted || page.Events[0].After != nil {
+		t.Fatalf("Expected the deletion first without state after, got %+v", page.Events[0])
+	}
+}
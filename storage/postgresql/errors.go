@@ -0,0 +1,20 @@
+package postgresql
+
+import (
+	"errors"
+	"github.com/jackc/pgconn"
+)
+
+// PostgreSQL error codes https://www.postgresql.org/docs/13/errcodes-appendix.html
+const (
+	uniqueViolationCode     = "23505"
+	foreignKeyViolationCode = "23503"
+)
+
+func hasErrorCode(err error, code string) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == code
+	}
+	return false
+}
@@ -0,0 +1,69 @@
+package postgresql
+
+import (
+	"api/storage"
+	"fmt"
+	"strings"
+)
+
+// imageFilterConditions builds the WHERE clause for the filter, appending its values to args so that
+// placeholders continue after the ones already used by the caller. Images in the trash never match.
+func imageFilterConditions(filter storage.ImageFilter, args []interface{}) (string, []interface{}) {
+	conditions := []string{"deleted_at IS NULL"}
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.Tag != "" {
+		addCondition(`EXISTS (
+   SELECT 1 FROM images_tags it JOIN tags t ON t.id = it.tag_id
+   WHERE it.image_id = images.id AND t.value = $%d
+ )`, filter.Tag)
+	}
+	if filter.Format != "" {
+		addCondition("format = $%d", string(filter.Format))
+	}
+	if filter.AuthorId != "" {
+		addCondition("author_id = $%d", filter.AuthorId)
+	}
+	if filter.CreatedAfter != nil {
+		addCondition("created_at >= $%d", *filter.CreatedAfter)
+	}
+	if filter.CreatedBefore != nil {
+		addCondition("created_at < $%d", *filter.CreatedBefore)
+	}
+	if filter.CapturedAfter != nil {
+		addCondition("captured_at >= $%d", *filter.CapturedAfter)
+	}
+	if filter.CapturedBefore != nil {
+		addCondition("captured_at < $%d", *filter.CapturedBefore)
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+// imageCursorCondition extends the where clause to only rows after the cursor, the comparison follows the order
+// so that (created_at, id) keyset can use the index in both directions
+func imageCursorCondition(
+	where string, cursor *storage.Cursor, order storage.Order, args []interface{},
+) (string, []interface{}) {
+	if cursor == nil {
+		return where, args
+	}
+
+	comparison := "<"
+	if order == storage.OrderAscending {
+		comparison = ">"
+	}
+
+	args = append(args, cursor.CreatedAt, cursor.Id)
+	condition := fmt.Sprintf("(created_at, id) %s ($%d, $%d)", comparison, len(args)-1, len(args))
+
+	if where == "" {
+		return " WHERE " + condition, args
+	}
+
+	return where + " AND " + condition, args
+}
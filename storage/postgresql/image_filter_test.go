@@ -0,0 +1,116 @@
+package postgresql
+
+import (
+	"api/storage"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestImageFilterConditions(t *testing.T) {
+	after := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	before := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	data := []struct {
+		testName           string
+		filter             storage.ImageFilter
+		args               []interface{}
+		expectedConditions []string
+		expectedArgs       []interface{}
+	}{
+		{
+			testName:           "Empty filter hides the trash",
+			filter:             storage.ImageFilter{},
+			args:               []interface{}{10, 0},
+			expectedConditions: []string{"deleted_at IS NULL"},
+			expectedArgs:       []interface{}{},
+		},
+		{
+			testName:           "Format and author",
+			filter:             storage.ImageFilter{Format: storage.PngFormat, AuthorId: "user-1"},
+			args:               []interface{}{10, 0},
+			expectedConditions: []string{"format = $3", "author_id = $4"},
+			expectedArgs:       []interface{}{"png", "user-1"},
+		},
+		{
+			testName:           "Tag and date range",
+			filter:             storage.ImageFilter{Tag: "planes", CreatedAfter: &after, CreatedBefore: &before},
+			expectedConditions: []string{"t.value = $1", "created_at >= $2", "created_at < $3"},
+			expectedArgs:       []interface{}{"planes", after, before},
+		},
+		{
+			testName:           "Capture date range",
+			filter:             storage.ImageFilter{CapturedAfter: &after, CapturedBefore: &before},
+			expectedConditions: []string{"captured_at >= $1", "captured_at < $2"},
+			expectedArgs:       []interface{}{after, before},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			where, args := imageFilterConditions(d.filter, d.args)
+
+			if len(d.expectedConditions) == 0 {
+				if where != "" {
+					t.Fatalf("Expected empty where clause, got %s", where)
+				}
+				return
+			}
+
+			if !strings.HasPrefix(where, " WHERE ") {
+				t.Fatalf("Expected where clause, got %s", where)
+			}
+			for _, condition := range d.expectedConditions {
+				if !strings.Contains(where, condition) {
+					t.Fatalf("Expected %s to contain %s", where, condition)
+				}
+			}
+			if !reflect.DeepEqual(args[len(d.args):], d.expectedArgs) {
+				t.Fatalf("Expected args %v, got %v", d.expectedArgs, args[len(d.args):])
+			}
+		})
+	}
+}
+
+func TestImageCursorCondition(t *testing.T) {
+	createdAt := time.Date(2021, 5, 1, 0, 0, 0, 0, time.UTC)
+	cursor := &storage.Cursor{CreatedAt: createdAt, Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263"}
+
+	data := []struct {
+		testName      string
+		where         string
+		cursor        *storage.Cursor
+		order         storage.Order
+		expectedWhere string
+	}{
+		{testName: "No cursor", where: " WHERE format = $3", order: storage.OrderDescending, expectedWhere: " WHERE format = $3"},
+		{
+			testName:      "Descending without filter",
+			cursor:        cursor,
+			order:         storage.OrderDescending,
+			expectedWhere: " WHERE (created_at, id) < ($3, $4)",
+		},
+		{
+			testName:      "Ascending with filter",
+			where:         " WHERE format = $3",
+			cursor:        cursor,
+			order:         storage.OrderAscending,
+			expectedWhere: " WHERE format = $3 AND (created_at, id) > ($4, $5)",
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			args := []interface{}{10, 0}
+			if d.where != "" {
+				args = append(args, "png")
+			}
+
+			where, _ := imageCursorCondition(d.where, d.cursor, d.order, args)
+			if where != d.expectedWhere {
+				t.Fatalf("Expected %s, got %s", d.expectedWhere, where)
+			}
+		})
+	}
+}
@@ -7,9 +7,21 @@ import (
 	"errors"
 	"fmt"
 	"github.com/jackc/pgx/v4"
+	"golang.org/x/sync/errgroup"
 	"time"
 )
 
+// imageTagsColumn aggregates tags of the selected image into a json array
+const imageTagsColumn = `COALESCE(
+  (SELECT json_agg(json_build_object('id', t.id, 'value', t.value) ORDER BY t.value)
+   FROM images_tags it JOIN tags t ON t.id = it.tag_id
+   WHERE it.image_id = images.id),
+  '[]'
+ ) AS tags`
+
+// imageDescriptionColumns are selected right before the tags
+const imageDescriptionColumns = "alt_text, title, caption, credit, license"
+
 type ImageRepo struct {
 	database *Database
 }
@@ -18,46 +30,111 @@ func NewImageRepository(db *Database) *ImageRepo {
 	return &ImageRepo{database: db}
 }
 
-func (repo ImageRepo) Get(ctx context.Context, limit, offset int, order storage.Order) (storage.ImageList, error) {
-	query := `SELECT
- id, name, format, original, domain, path, sizes, created_at, updated_at, author_id
- FROM images
- ORDER BY created_at ` + string(order) + `
+// Get fetches one row more than the limit to find out whether there is a next page, the total count of images
+// matching the filter is queried concurrently
+func (repo ImageRepo) Get(
+	ctx context.Context, paging storage.Paging, filter storage.ImageFilter,
+) (storage.ImagePage, error) {
+	var images storage.ImageList
+	var total int
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		where, args := imageFilterConditions(filter, []interface{}{paging.Limit + 1, paging.Offset})
+		where, args = imageCursorCondition(where, paging.Cursor, paging.Order, args)
+
+		query := `SELECT
+ id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, deleted_at, phash, blur_hash,
+ dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+ FROM images` + where + `
+ ORDER BY created_at ` + string(paging.Order) + `, id ` + string(paging.Order) + `
  LIMIT $1
  OFFSET $2
 `
-	rows, err := repo.database.dbPool.Query(ctx, query, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed querying images: %w", err)
+		rows, err := repo.database.dbPool.Query(gCtx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed querying images: %w", err)
+		}
+		defer rows.Close()
+
+		images, err = scanImageList(rows)
+		return err
+	})
+
+	g.Go(func() error {
+		where, args := imageFilterConditions(filter, nil)
+
+		return repo.count(gCtx, "SELECT count(*) FROM images"+where, args, &total)
+	})
+
+	if err := g.Wait(); err != nil {
+		return storage.ImagePage{}, err
 	}
-	defer rows.Close()
 
+	if len(images) <= paging.Limit {
+		return storage.ImagePage{Images: images, Total: total}, nil
+	}
+	images = images[:paging.Limit]
+
+	return storage.ImagePage{
+		Images:     images,
+		Total:      total,
+		HasNext:    true,
+		NextCursor: storage.NewCursorFrom(images[len(images)-1]),
+	}, nil
+}
+
+func (repo ImageRepo) count(ctx context.Context, query string, args []interface{}, total *int) error {
+	if err := repo.database.dbPool.QueryRow(ctx, query, args...).Scan(total); err != nil {
+		return fmt.Errorf("failed counting images: %w", err)
+	}
+
+	return nil
+}
+
+func scanImageList(rows pgx.Rows) (storage.ImageList, error) {
 	var imageList []storage.Image
 
 	for rows.Next() {
 		var id, name, format, original, domain, path, authorId string
 		var sizes storage.ImageSizes
-		var createdAt, updatedAt *time.Time
+		var createdAt, updatedAt, deletedAt *time.Time
+		var phash *storage.PerceptualHash
+		var blurHash, dominantColor *string
+		var description storage.ImageDescription
+		var tags storage.TagList
 
-		err = rows.Scan(
-			&id, &name, &format, &original, &domain, &path, &sizes, &createdAt, &updatedAt, &authorId,
+		err := rows.Scan(
+			&id, &name, &format, &original, &domain, &path, &sizes, &createdAt, &updatedAt, &authorId, &deletedAt,
+			&phash, &blurHash, &dominantColor, &description.AltText, &description.Title, &description.Caption,
+			&description.Credit, &description.License, &tags,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed scaning images: %w", err)
 		}
 		imageList = append(imageList, storage.Image{
-			Id:        id,
-			Name:      name,
-			Format:    storage.ImageFormat(format),
-			Original:  original,
-			Domain:    domain,
-			Path:      path,
-			Sizes:     sizes,
-			CreatedAt: createdAt,
-			UpdatedAt: updatedAt,
-			AuthorId:  authorId,
+			Id:               id,
+			Name:             name,
+			Format:           storage.ImageFormat(format),
+			Original:         original,
+			Domain:           domain,
+			Path:             path,
+			Sizes:            sizes,
+			CreatedAt:        createdAt,
+			UpdatedAt:        updatedAt,
+			AuthorId:         authorId,
+			Tags:             tags,
+			DeletedAt:        deletedAt,
+			PerceptualHash:   phash,
+			BlurHash:         blurHash,
+			DominantColor:    dominantColor,
+			ImageDescription: description,
 		})
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed reading images: %w", err)
+	}
 
 	if imageList == nil {
 		return storage.ImageList{}, nil
@@ -66,22 +143,126 @@ func (repo ImageRepo) Get(ctx context.Context, limit, offset int, order storage.
 	return imageList, nil
 }
 
+// Search finds images by name, title, alt text, caption and credit with a prefix match of every term in the query,
+// most relevant first
+func (repo *ImageRepo) Search(ctx context.Context, text string, limit, offset int) (storage.ImagePage, error) {
+	tsQuery := toPrefixTsQuery(text)
+	if tsQuery == "" {
+		return storage.ImagePage{Images: storage.ImageList{}}, nil
+	}
+
+	var images storage.ImageList
+	var total int
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		query := `SELECT
+ id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, deleted_at, phash, blur_hash,
+ dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+ FROM images, to_tsquery('simple', $1) query
+ WHERE search_vector @@ query AND deleted_at IS NULL
+ ORDER BY ts_rank(search_vector, query) DESC, created_at DESC
+ LIMIT $2
+ OFFSET $3
+`
+		rows, err := repo.database.dbPool.Query(gCtx, query, tsQuery, limit, offset)
+		if err != nil {
+			return fmt.Errorf("failed searching images: %w", err)
+		}
+		defer rows.Close()
+
+		images, err = scanImageList(rows)
+		return err
+	})
+
+	g.Go(func() error {
+		query := "SELECT count(*) FROM images WHERE search_vector @@ to_tsquery('simple', $1) AND deleted_at IS NULL"
+
+		return repo.count(gCtx, query, []interface{}{tsQuery}, &total)
+	})
+
+	if err := g.Wait(); err != nil {
+		return storage.ImagePage{}, err
+	}
+
+	return storage.ImagePage{Images: images, Total: total, HasNext: offset+len(images) < total}, nil
+}
+
+// GetSimilar orders the images by the number of bits their perceptual hash differs from the hash in. Postgres 13
+// has no bit_count, so the bits of the xor are counted from its text form.
+func (repo *ImageRepo) GetSimilar(
+	ctx context.Context, hash storage.PerceptualHash, maxDistance int, excludeId string, limit int,
+) ([]storage.SimilarImage, error) {
+	query := `SELECT * FROM (
+  SELECT
+   id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, deleted_at, phash,
+   blur_hash, dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `,
+   length(replace((phash # $1)::bit(64)::text, '0', '')) AS distance
+  FROM images
+  WHERE phash IS NOT NULL AND deleted_at IS NULL AND id::text <> $3
+ ) similar
+ WHERE distance <= $2
+ ORDER BY distance, created_at DESC
+ LIMIT $4
+`
+	rows, err := repo.database.dbPool.Query(ctx, query, hash, maxDistance, excludeId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying similar images: %w", err)
+	}
+	defer rows.Close()
+
+	similar := make([]storage.SimilarImage, 0)
+	for rows.Next() {
+		var img storage.SimilarImage
+		err = rows.Scan(
+			&img.Id, &img.Name, &img.Format, &img.Original, &img.Domain, &img.Path, &img.Sizes, &img.CreatedAt,
+			&img.UpdatedAt, &img.AuthorId, &img.DeletedAt, &img.PerceptualHash, &img.BlurHash, &img.DominantColor,
+			&img.AltText, &img.Title, &img.Caption, &img.Credit, &img.License, &img.Tags, &img.Distance,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed scaning similar images: %w", err)
+		}
+		similar = append(similar, img)
+	}
+
+	return similar, rows.Err()
+}
+
 func (repo *ImageRepo) GetOne(ctx context.Context, imageId string) (storage.Image, error) {
+	return repo.getOneBy(ctx, "id = $1", imageId)
+}
+
+func (repo *ImageRepo) GetOneByName(ctx context.Context, name string) (storage.Image, error) {
+	return repo.getOneBy(ctx, "name = $1", name)
+}
+
+// GetOneByOldName finds the image that was named by the slug before it was renamed
+func (repo *ImageRepo) GetOneByOldName(ctx context.Context, slug string) (storage.Image, error) {
+	return repo.getOneBy(ctx, "id = (SELECT image_id FROM image_slug_history WHERE slug = $1)", slug)
+}
+
+// getOneBy selects a single image, that is not in the trash, matching the condition with a single placeholder,
+// condition must never come from user input
+func (repo *ImageRepo) getOneBy(ctx context.Context, condition string, value string) (storage.Image, error) {
 	query := `SELECT
-id, name, format, original, domain, path, sizes, created_at, updated_at, author_id
+id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, phash, blur_hash, dominant_color,
+` + imageDescriptionColumns + `, ` + imageTagsColumn + `
 FROM images
-WHERE id = $1
+WHERE ` + condition + ` AND deleted_at IS NULL
 LIMIT 1
 `
 	var image storage.Image
 
-	err := repo.database.dbPool.QueryRow(ctx, query, imageId).Scan(
+	err := repo.database.dbPool.QueryRow(ctx, query, value).Scan(
 		&image.Id, &image.Name, &image.Format, &image.Original, &image.Domain, &image.Path,
-		&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId,
+		&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId, &image.PerceptualHash, &image.BlurHash,
+		&image.DominantColor, &image.AltText, &image.Title, &image.Caption, &image.Credit, &image.License,
+		&image.Tags,
 	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return storage.Image{}, storage.NotFound{}
+			return storage.Image{}, storage.NotFound{Msg: "Image not found " + value}
 		}
 		return storage.Image{}, err
 	}
@@ -89,40 +270,50 @@ LIMIT 1
 	return image, nil
 }
 
-func (repo *ImageRepo) GetOneByName(ctx context.Context, name string) (storage.Image, error) {
-	return storage.Image{}, nil
-}
-
+// DoesImageExist also counts the names reserved by unfinished uploads, their files may already be stored, and the
+// names versions keep their files under
 func (repo *ImageRepo) DoesImageExist(ctx context.Context, name string) (bool, error) {
-	query := "SELECT name FROM images WHERE name = $1 LIMIT 1"
+	query := `SELECT EXISTS (SELECT 1 FROM images WHERE name = $1)
+ OR EXISTS (SELECT 1 FROM upload_sagas WHERE image_name = $1 AND step IN ($2, $3))
+ OR EXISTS (SELECT 1 FROM image_versions WHERE file_name = $1)`
 
-	var imageName string
-	err := repo.database.dbPool.QueryRow(ctx, query, name).Scan(&imageName)
-	if err != nil {
-		if errors.Is(err, pgx.ErrNoRows) {
-			return false, nil
-		}
-		return false, err
-	}
+	var exists bool
+	err := repo.database.dbPool.QueryRow(
+		ctx, query, name, storage.UploadSagaStarted, storage.UploadSagaResized,
+	).Scan(&exists)
 
-	return imageName != "", nil
+	return exists, err
 }
 
 func (repo *ImageRepo) Create(ctx context.Context, image storage.Image) (storage.Image, error) {
 	query := `INSERT INTO
- images ("name", "format", "original", "domain", "path", "sizes", "author_id")
- VALUES ($1, $2, $3, $4, $5, $6, $7)
- RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id
+ images ("name", "format", "original", "domain", "path", "sizes", "author_id", "phash", "blur_hash", "dominant_color",
+  "metadata", "captured_at", "alt_text", "title", "caption", "credit", "license")
+ VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+ RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, phash, blur_hash,
+  dominant_color, metadata, ` + imageDescriptionColumns + `
 `
 	data, err := json.Marshal(image.Sizes)
 	if err != nil {
 		return storage.Image{}, err
 	}
 
+	tx, err := repo.database.dbPool.Begin(ctx)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
 	var id, name, format, original, domain, path, sizes, authorId string
 	var createdAt, updatedAt *time.Time
+	var phash *storage.PerceptualHash
+	var blurHash, dominantColor *string
+	var metadata *storage.ImageMetadata
+	var description storage.ImageDescription
 
-	err = repo.database.dbPool.QueryRow(
+	err = tx.QueryRow(
 		ctx,
 		query,
 		image.Name,
@@ -132,9 +323,27 @@ func (repo *ImageRepo) Create(ctx context.Context, image storage.Image) (storage
 		image.Path,
 		string(data),
 		image.AuthorId,
+		image.PerceptualHash,
+		image.BlurHash,
+		image.DominantColor,
+		image.Metadata,
+		capturedAt(image.Metadata),
+		image.AltText,
+		image.Title,
+		image.Caption,
+		image.Credit,
+		image.License,
 	).Scan(
-		&id, &name, &format, &original, &domain, &path, &sizes, &createdAt, &updatedAt, &authorId,
+		&id, &name, &format, &original, &domain, &path, &sizes, &createdAt, &updatedAt, &authorId, &phash,
+		&blurHash, &dominantColor, &metadata, &description.AltText, &description.Title, &description.Caption,
+		&description.Credit, &description.License,
 	)
+	if err != nil {
+		if hasErrorCode(err, uniqueViolationCode) {
+			return storage.Image{}, storage.ErrDuplicate
+		}
+		return storage.Image{}, err
+	}
 
 	var sizesConverted storage.ImageSizes
 	if len(sizes) > 0 {
@@ -145,47 +354,574 @@ func (repo *ImageRepo) Create(ctx context.Context, image storage.Image) (storage
 	}
 
 	createdImage := storage.Image{
-		Id:        id,
-		Name:      name,
-		Format:    storage.ImageFormat(format),
-		Original:  original,
-		Domain:    domain,
-		Path:      path,
-		Sizes:     sizesConverted,
-		CreatedAt: createdAt,
-		UpdatedAt: updatedAt,
-		AuthorId:  authorId,
+		Id:               id,
+		Name:             name,
+		Format:           storage.ImageFormat(format),
+		Original:         original,
+		Domain:           domain,
+		Path:             path,
+		Sizes:            sizesConverted,
+		CreatedAt:        createdAt,
+		UpdatedAt:        updatedAt,
+		AuthorId:         authorId,
+		Tags:             storage.TagList{},
+		PerceptualHash:   phash,
+		BlurHash:         blurHash,
+		DominantColor:    dominantColor,
+		Metadata:         metadata,
+		ImageDescription: description,
+	}
+	if err = insertOutboxEvent(ctx, tx, storage.EventImageCreated, createdImage); err != nil {
+		return storage.Image{}, err
 	}
 
-	return createdImage, err
+	return createdImage, tx.Commit(ctx)
+}
+
+// withSlugHistory runs the update in a transaction that locks the image, when the name changes the previous one
+// is recorded in the image_slug_history
+func (repo *ImageRepo) withSlugHistory(
+	ctx context.Context, imageId, newName string, update func(tx pgx.Tx) error,
+) error {
+	tx, err := repo.database.dbPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var oldName string
+	query := "SELECT name FROM images WHERE id = $1 AND deleted_at IS NULL FOR UPDATE"
+	err = tx.QueryRow(ctx, query, imageId).Scan(&oldName)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.NotFound{Msg: "Image not found " + imageId}
+		}
+		return err
+	}
+
+	if err = update(tx); err != nil {
+		return err
+	}
+
+	if oldName != newName {
+		// The slug is given to the latest image that used it, the current name never redirects
+		query = `INSERT INTO image_slug_history (image_id, slug) VALUES ($1, $2)
+ ON CONFLICT (slug) DO UPDATE SET image_id = EXCLUDED.image_id, created_at = now()
+`
+		if _, err = tx.Exec(ctx, query, imageId, oldName); err != nil {
+			return err
+		}
+		if _, err = tx.Exec(ctx, "DELETE FROM image_slug_history WHERE slug = $1", newName); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
 }
 
 func (repo *ImageRepo) SetNameById(ctx context.Context, imageId, newName string) (storage.Image, error) {
-	return storage.Image{}, nil
+	query := `UPDATE images SET name = $2, updated_at = now()
+ WHERE id = $1
+ RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, phash,
+  blur_hash, dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+`
+	var image storage.Image
+
+	err := repo.withSlugHistory(ctx, imageId, newName, func(tx pgx.Tx) error {
+		err := tx.QueryRow(ctx, query, imageId, newName).Scan(
+			&image.Id, &image.Name, &image.Format, &image.Original, &image.Domain, &image.Path,
+			&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId, &image.PerceptualHash, &image.BlurHash,
+			&image.DominantColor, &image.AltText, &image.Title, &image.Caption, &image.Credit, &image.License,
+			&image.Tags,
+		)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvent(ctx, tx, storage.EventImageUpdated, image)
+	})
+	if err != nil {
+		if hasErrorCode(err, uniqueViolationCode) {
+			return storage.Image{}, storage.ErrDuplicate
+		}
+		return storage.Image{}, err
+	}
+
+	return image, nil
 }
 
+// UpdateOne overwrites the name, files and sizes of the image with the matching id, author and creation date
+// stay untouched. The perceptual hash, the placeholders and the metadata are only overwritten when set.
 func (repo *ImageRepo) UpdateOne(ctx context.Context, updates storage.Image) error {
+	return repo.updateOne(ctx, updates, nil)
+}
+
+func (repo *ImageRepo) ReplaceFiles(ctx context.Context, updates storage.Image, replaced storage.ImageVersion) error {
+	return repo.updateOne(ctx, updates, func(tx pgx.Tx) error {
+		return insertVersion(ctx, tx, updates.Id, replaced)
+	})
+}
+
+func (repo *ImageRepo) RestoreVersion(
+	ctx context.Context, updates storage.Image, replaced storage.ImageVersion, version int,
+) error {
+	return repo.updateOne(ctx, updates, func(tx pgx.Tx) error {
+		if err := insertVersion(ctx, tx, updates.Id, replaced); err != nil {
+			return err
+		}
+		query := "DELETE FROM image_versions WHERE image_id = $1 AND version = $2"
+		commandTag, err := tx.Exec(ctx, query, updates.Id, version)
+		if err != nil {
+			return err
+		}
+		if commandTag.RowsAffected() == 0 {
+			return storage.NotFound{Msg: fmt.Sprintf("Version %d of image %s not found", version, updates.Id)}
+		}
+		return nil
+	})
+}
+
+// insertVersion keeps the current files of the image as its next version, the image row must be locked by
+// withSlugHistory so that the next version number can not be taken concurrently
+func insertVersion(ctx context.Context, tx pgx.Tx, imageId string, replaced storage.ImageVersion) error {
+	query := `INSERT INTO image_versions
+  (image_id, version, name, file_name, format, original, domain, path, sizes, phash, blur_hash, dominant_color,
+   metadata, captured_at)
+ SELECT id, COALESCE((SELECT max(version) FROM image_versions WHERE image_id = $1), 0) + 1,
+  name, $2, format, COALESCE(NULLIF($3, ''), original), COALESCE(NULLIF($4, ''), domain),
+  COALESCE(NULLIF($5, ''), path), sizes, phash, blur_hash, dominant_color, metadata, captured_at
+ FROM images
+ WHERE id = $1
+`
+	_, err := tx.Exec(ctx, query, imageId, replaced.FileName, replaced.Original, replaced.Domain, replaced.Path)
+	return err
+}
+
+// updateOne runs keepVersion, when set, in the transaction of the update before the image is overwritten
+func (repo *ImageRepo) updateOne(
+	ctx context.Context, updates storage.Image, keepVersion func(tx pgx.Tx) error,
+) error {
+	query := `UPDATE images
+ SET name = $2, format = $3, original = $4, domain = $5, path = $6, sizes = $7, phash = COALESCE($8, phash),
+  blur_hash = COALESCE($9, blur_hash), dominant_color = COALESCE($10, dominant_color),
+  metadata = COALESCE($11, metadata), captured_at = CASE WHEN $11 IS NULL THEN captured_at ELSE $12 END,
+  updated_at = now()
+ WHERE id = $1
+ RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, phash,
+  blur_hash, dominant_color, metadata, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+`
+	data, err := json.Marshal(updates.Sizes)
+	if err != nil {
+		return err
+	}
+
+	err = repo.withSlugHistory(ctx, updates.Id, updates.Name, func(tx pgx.Tx) error {
+		if keepVersion != nil {
+			if err := keepVersion(tx); err != nil {
+				return err
+			}
+		}
+
+		var image storage.Image
+		err := tx.QueryRow(
+			ctx,
+			query,
+			updates.Id,
+			updates.Name,
+			updates.Format,
+			updates.Original,
+			updates.Domain,
+			updates.Path,
+			string(data),
+			updates.PerceptualHash,
+			updates.BlurHash,
+			updates.DominantColor,
+			updates.Metadata,
+			capturedAt(updates.Metadata),
+		).Scan(
+			&image.Id, &image.Name, &image.Format, &image.Original, &image.Domain, &image.Path,
+			&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId, &image.PerceptualHash, &image.BlurHash,
+			&image.DominantColor, &image.Metadata, &image.AltText, &image.Title, &image.Caption, &image.Credit,
+			&image.License, &image.Tags,
+		)
+		if err != nil {
+			return err
+		}
+		return insertOutboxEvent(ctx, tx, storage.EventImageUpdated, image)
+	})
+	if err != nil {
+		if hasErrorCode(err, uniqueViolationCode) {
+			return storage.ErrDuplicate
+		}
+		return err
+	}
+
 	return nil
 }
 
+// SetDescription overwrites the texts describing the image, its files stay untouched
+func (repo *ImageRepo) SetDescription(
+	ctx context.Context, imageId string, description storage.ImageDescription,
+) (storage.Image, error) {
+	query := `UPDATE images SET alt_text = $2, title = $3, caption = $4, credit = $5, license = $6, updated_at = now()
+ WHERE id = $1 AND deleted_at IS NULL
+ RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, phash,
+  blur_hash, dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+`
+	tx, err := repo.database.dbPool.Begin(ctx)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var image storage.Image
+	err = tx.QueryRow(
+		ctx,
+		query,
+		imageId,
+		description.AltText,
+		description.Title,
+		description.Caption,
+		description.Credit,
+		description.License,
+	).Scan(
+		&image.Id, &image.Name, &image.Format, &image.Original, &image.Domain, &image.Path,
+		&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId, &image.PerceptualHash, &image.BlurHash,
+		&image.DominantColor, &image.AltText, &image.Title, &image.Caption, &image.Credit, &image.License,
+		&image.Tags,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.Image{}, storage.NotFound{Msg: "Image not found " + imageId}
+		}
+		return storage.Image{}, err
+	}
+	if err = insertOutboxEvent(ctx, tx, storage.EventImageUpdated, image); err != nil {
+		return storage.Image{}, err
+	}
+
+	return image, tx.Commit(ctx)
+}
+
 func (repo *ImageRepo) DeleteOne(ctx context.Context, imageId string) error {
-	query := "DELETE FROM images WHERE id = $1"
+	query := `UPDATE images SET deleted_at = now()
+ WHERE id = $1 AND deleted_at IS NULL
+ RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id
+`
+	tx, err := repo.database.dbPool.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
 
-	commandTag, err := repo.database.dbPool.Exec(ctx, query, imageId)
+	var image storage.Image
+	err = tx.QueryRow(ctx, query, imageId).Scan(
+		&image.Id, &image.Name, &image.Format, &image.Original, &image.Domain, &image.Path,
+		&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId,
+	)
 	if err != nil {
 		if errors.Is(err, pgx.ErrNoRows) {
-			return storage.NotFound{}
+			return storage.NotFound{Msg: "Image not found " + imageId}
 		}
 		return err
 	}
+	if err = insertOutboxEvent(ctx, tx, storage.EventImageDeleted, image); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (repo *ImageRepo) GetDeleted(ctx context.Context, limit, offset int) (storage.ImagePage, error) {
+	var images storage.ImageList
+	var total int
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		query := `SELECT
+ id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, deleted_at, phash, blur_hash,
+ dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+ FROM images
+ WHERE deleted_at IS NOT NULL
+ ORDER BY deleted_at DESC, id DESC
+ LIMIT $1
+ OFFSET $2
+`
+		rows, err := repo.database.dbPool.Query(gCtx, query, limit, offset)
+		if err != nil {
+			return fmt.Errorf("failed querying deleted images: %w", err)
+		}
+		defer rows.Close()
+
+		images, err = scanImageList(rows)
+		return err
+	})
+
+	g.Go(func() error {
+		return repo.count(gCtx, "SELECT count(*) FROM images WHERE deleted_at IS NOT NULL", nil, &total)
+	})
+
+	if err := g.Wait(); err != nil {
+		return storage.ImagePage{}, err
+	}
+
+	return storage.ImagePage{Images: images, Total: total, HasNext: offset+len(images) < total}, nil
+}
+
+// Restore takes the image out of the trash, names of deleted images are never reused so it keeps its own
+func (repo *ImageRepo) Restore(ctx context.Context, imageId string) (storage.Image, error) {
+	query := `UPDATE images SET deleted_at = NULL, updated_at = now()
+ WHERE id = $1 AND deleted_at IS NOT NULL
+ RETURNING id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, phash,
+  blur_hash, dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+`
+	tx, err := repo.database.dbPool.Begin(ctx)
+	if err != nil {
+		return storage.Image{}, err
+	}
+	defer func() {
+		_ = tx.Rollback(ctx)
+	}()
+
+	var image storage.Image
+	err = tx.QueryRow(ctx, query, imageId).Scan(
+		&image.Id, &image.Name, &image.Format, &image.Original, &image.Domain, &image.Path,
+		&image.Sizes, &image.CreatedAt, &image.UpdatedAt, &image.AuthorId, &image.PerceptualHash, &image.BlurHash,
+		&image.DominantColor, &image.AltText, &image.Title, &image.Caption, &image.Credit, &image.License,
+		&image.Tags,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.Image{}, storage.NotFound{Msg: "Deleted image not found " + imageId}
+		}
+		return storage.Image{}, err
+	}
+	if err = insertOutboxEvent(ctx, tx, storage.EventImageRestored, image); err != nil {
+		return storage.Image{}, err
+	}
+
+	return image, tx.Commit(ctx)
+}
+
+func (repo *ImageRepo) GetDeletedBefore(
+	ctx context.Context, before time.Time, limit int,
+) (storage.ImageList, error) {
+	query := `SELECT
+ id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, deleted_at, phash, blur_hash,
+ dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+ FROM images
+ WHERE deleted_at < $1
+ ORDER BY deleted_at
+ LIMIT $2
+`
+	rows, err := repo.database.dbPool.Query(ctx, query, before, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying deleted images: %w", err)
+	}
+	defer rows.Close()
+
+	return scanImageList(rows)
+}
+
+func (repo *ImageRepo) Purge(ctx context.Context, imageId string) error {
+	query := "DELETE FROM images WHERE id = $1 AND deleted_at IS NOT NULL"
+
+	tag, err := repo.database.dbPool.Exec(ctx, query, imageId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Deleted image not found " + imageId}
+	}
+
+	return nil
+}
+
+func (repo *ImageRepo) GetWithoutPlaceholders(
+	ctx context.Context, afterId string, limit int,
+) (storage.ImageList, error) {
+	query := `SELECT
+ id, name, format, original, domain, path, sizes, created_at, updated_at, author_id, deleted_at, phash, blur_hash,
+ dominant_color, ` + imageDescriptionColumns + `, ` + imageTagsColumn + `
+ FROM images
+ WHERE (blur_hash IS NULL OR dominant_color IS NULL) AND deleted_at IS NULL AND id::text > $1
+ ORDER BY id::text
+ LIMIT $2
+`
+	rows, err := repo.database.dbPool.Query(ctx, query, afterId, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying images without placeholders: %w", err)
+	}
+	defer rows.Close()
+
+	return scanImageList(rows)
+}
+
+func (repo *ImageRepo) SetPlaceholders(ctx context.Context, imageId, blurHash, dominantColor string) error {
+	query := "UPDATE images SET blur_hash = $2, dominant_color = $3 WHERE id = $1 AND deleted_at IS NULL"
+
+	tag, err := repo.database.dbPool.Exec(ctx, query, imageId, blurHash, dominantColor)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Image not found " + imageId}
+	}
+
+	return nil
+}
+
+func (repo *ImageRepo) GetMetadata(ctx context.Context, imageId string) (storage.ImageMetadata, error) {
+	query := "SELECT metadata FROM images WHERE id = $1 AND deleted_at IS NULL"
 
+	var metadata *storage.ImageMetadata
+	if err := repo.database.dbPool.QueryRow(ctx, query, imageId).Scan(&metadata); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ImageMetadata{}, storage.NotFound{Msg: "Image not found " + imageId}
+		}
+		return storage.ImageMetadata{}, err
+	}
+	if metadata == nil {
+		return storage.ImageMetadata{}, nil
+	}
+
+	return *metadata, nil
+}
+
+// capturedAt is stored next to the metadata so that images can be filtered by it
+func capturedAt(metadata *storage.ImageMetadata) *time.Time {
+	if metadata == nil {
+		return nil
+	}
+	return metadata.CapturedAt
+}
+
+// GetTranslations leaves out the translations of images in the trash
+func (repo *ImageRepo) GetTranslations(ctx context.Context, imageIds ...string) ([]storage.ImageTranslation, error) {
+	query := `SELECT t.image_id, t.locale, t.alt_text, t.caption, t.created_at, t.updated_at
+ FROM image_translations t JOIN images i ON i.id = t.image_id
+ WHERE t.image_id = ANY($1::uuid[]) AND i.deleted_at IS NULL
+ ORDER BY t.image_id, t.locale
+`
+	rows, err := repo.database.dbPool.Query(ctx, query, imageIds)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying image translations: %w", err)
+	}
+	defer rows.Close()
+
+	translations := make([]storage.ImageTranslation, 0)
+	for rows.Next() {
+		var translation storage.ImageTranslation
+		err = rows.Scan(
+			&translation.ImageId, &translation.Locale, &translation.AltText, &translation.Caption,
+			&translation.CreatedAt, &translation.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		translations = append(translations, translation)
+	}
+
+	return translations, rows.Err()
+}
+
+// SetTranslation inserts the translation only for an image outside the trash, so that no row means not found
+func (repo *ImageRepo) SetTranslation(
+	ctx context.Context, translation storage.ImageTranslation,
+) (storage.ImageTranslation, error) {
+	query := `INSERT INTO image_translations (image_id, locale, alt_text, caption)
+ SELECT id, $2, $3, $4 FROM images WHERE id = $1 AND deleted_at IS NULL
+ ON CONFLICT (image_id, locale) DO UPDATE SET alt_text = $3, caption = $4, updated_at = now()
+ RETURNING image_id, locale, alt_text, caption, created_at, updated_at
+`
+	var saved storage.ImageTranslation
+	err := repo.database.dbPool.QueryRow(
+		ctx, query, translation.ImageId, translation.Locale, translation.AltText, translation.Caption,
+	).Scan(&saved.ImageId, &saved.Locale, &saved.AltText, &saved.Caption, &saved.CreatedAt, &saved.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ImageTranslation{}, storage.NotFound{Msg: "Image not found " + translation.ImageId}
+		}
+		return storage.ImageTranslation{}, err
+	}
+
+	return saved, nil
+}
+
+func (repo *ImageRepo) DeleteTranslation(ctx context.Context, imageId, locale string) error {
+	query := "DELETE FROM image_translations WHERE image_id = $1 AND locale = $2"
+
+	commandTag, err := repo.database.dbPool.Exec(ctx, query, imageId, locale)
+	if err != nil {
+		return err
+	}
 	if commandTag.RowsAffected() == 0 {
-		return storage.NotFound{}
+		return storage.NotFound{Msg: fmt.Sprintf("Translation %s of image %s not found", locale, imageId)}
 	}
 
 	return nil
 }
 
+func (repo *ImageRepo) GetVersions(ctx context.Context, imageId string) ([]storage.ImageVersion, error) {
+	query := `SELECT image_id, version, name, file_name, format, original, domain, path, sizes, created_at, phash,
+  blur_hash, dominant_color, metadata
+ FROM image_versions
+ WHERE image_id = $1
+ ORDER BY version DESC
+`
+	rows, err := repo.database.dbPool.Query(ctx, query, imageId)
+	if err != nil {
+		return nil, fmt.Errorf("failed querying image versions: %w", err)
+	}
+	defer rows.Close()
+
+	versions := make([]storage.ImageVersion, 0)
+	for rows.Next() {
+		var version storage.ImageVersion
+		err = rows.Scan(
+			&version.ImageId, &version.Version, &version.Name, &version.FileName, &version.Format, &version.Original,
+			&version.Domain, &version.Path, &version.Sizes, &version.CreatedAt, &version.PerceptualHash,
+			&version.BlurHash, &version.DominantColor, &version.Metadata,
+		)
+		if err != nil {
+			return nil, err
+		}
+		versions = append(versions, version)
+	}
+
+	return versions, rows.Err()
+}
+
+func (repo *ImageRepo) GetVersion(ctx context.Context, imageId string, version int) (storage.ImageVersion, error) {
+	query := `SELECT image_id, version, name, file_name, format, original, domain, path, sizes, created_at, phash,
+  blur_hash, dominant_color, metadata
+ FROM image_versions
+ WHERE image_id = $1 AND version = $2
+`
+	var found storage.ImageVersion
+	err := repo.database.dbPool.QueryRow(ctx, query, imageId, version).Scan(
+		&found.ImageId, &found.Version, &found.Name, &found.FileName, &found.Format, &found.Original,
+		&found.Domain, &found.Path, &found.Sizes, &found.CreatedAt, &found.PerceptualHash, &found.BlurHash,
+		&found.DominantColor, &found.Metadata,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.ImageVersion{}, storage.NotFound{
+				Msg: fmt.Sprintf("Version %d of image %s not found", version, imageId),
+			}
+		}
+		return storage.ImageVersion{}, err
+	}
+
+	return found, nil
+}
+
 func (repo *ImageRepo) InsertMany(ctx context.Context, images storage.ImageList) (count int64, err error) {
 	for _, image := range images {
 		if _, err = repo.Create(ctx, image); err != nil {
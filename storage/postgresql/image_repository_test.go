@@ -4,8 +4,10 @@ import (
 	"api/storage"
 	"api/test"
 	"context"
+	"errors"
 	"fmt"
 	"testing"
+	"time"
 )
 
 func setupImageRepo(ctx context.Context) (*ImageRepo, error) {
@@ -96,10 +98,13 @@ func TestImageRepository_Get(t *testing.T) {
 		t.Error(fmt.Errorf("error inserting images %w", err))
 	}
 
-	imageList, err := repo.Get(context.Background(), 10, 0, storage.OrderDescending)
+	page, err := repo.Get(
+		context.Background(), storage.Paging{Limit: 10, Order: storage.OrderDescending}, storage.ImageFilter{},
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
+	imageList := page.Images
 
 	if imageList == nil {
 		t.Fatal("Got nil images")
@@ -165,10 +170,13 @@ func TestImageRepo_GetOne(t *testing.T) {
 		t.Error(fmt.Errorf("error inserting images %w", err))
 	}
 
-	imageList, err := repo.Get(context.Background(), 10, 0, storage.OrderDescending)
+	page, err := repo.Get(
+		context.Background(), storage.Paging{Limit: 10, Order: storage.OrderDescending}, storage.ImageFilter{},
+	)
 	if err != nil {
 		t.Fatal(err)
 	}
+	imageList := page.Images
 
 	if imageList == nil {
 		t.Fatal("Got nil images")
@@ -315,3 +323,862 @@ func TestImageRepository_DoesImageExist(t *testing.T) {
 		t.Fatal("image unknown should not exist")
 	}
 }
+
+func TestImageRepository_GetFiltered(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tagRepo, err := setupTagRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+	defer cleanTagRepo(t, tagRepo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	page, err := repo.Get(ctx, storage.Paging{Limit: 10, Order: storage.OrderAscending}, storage.ImageFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	images := page.Images
+	tag, err := tagRepo.Create(ctx, "planes", images[0].AuthorId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = tagRepo.Attach(ctx, images[0].Id, tag.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	future := time.Now().UTC().Add(time.Hour)
+	past := time.Now().UTC().Add(-time.Hour)
+
+	data := []struct {
+		testName      string
+		filter        storage.ImageFilter
+		expectedNames []string
+	}{
+		{
+			testName:      "By format",
+			filter:        storage.ImageFilter{Format: storage.PngFormat},
+			expectedNames: []string{"testing-image-one"},
+		},
+		{
+			testName:      "By author",
+			filter:        storage.ImageFilter{AuthorId: images[0].AuthorId},
+			expectedNames: []string{"testing-image-one", "testing-image-two"},
+		},
+		{
+			testName:      "By tag",
+			filter:        storage.ImageFilter{Tag: "planes"},
+			expectedNames: []string{images[0].Name},
+		},
+		{
+			testName:      "By date range",
+			filter:        storage.ImageFilter{CreatedAfter: &past, CreatedBefore: &future},
+			expectedNames: []string{"testing-image-one", "testing-image-two"},
+		},
+		{
+			testName:      "Combined with no match",
+			filter:        storage.ImageFilter{Format: storage.JpgFormat, CreatedAfter: &future},
+			expectedNames: []string{},
+		},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			page, err := repo.Get(ctx, storage.Paging{Limit: 10, Order: storage.OrderAscending}, d.filter)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := page.Images
+			if len(result) != len(d.expectedNames) {
+				t.Fatalf("Expected %d images, got %d", len(d.expectedNames), len(result))
+			}
+			for _, name := range d.expectedNames {
+				found := false
+				for _, img := range result {
+					if img.Name == name {
+						found = true
+					}
+				}
+				if !found {
+					t.Fatalf("Expected %s in result", name)
+				}
+			}
+		})
+	}
+}
+
+func TestImageRepository_GetWithCursor(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, order := range []storage.Order{storage.OrderDescending, storage.OrderAscending} {
+		t.Run(string(order), func(t *testing.T) {
+			all, err := repo.Get(ctx, storage.Paging{Limit: 10, Order: order}, storage.ImageFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if all.NextCursor != nil {
+				t.Fatalf("Expected no next cursor on the last page, got %+v", all.NextCursor)
+			}
+
+			first, err := repo.Get(ctx, storage.Paging{Limit: 1, Order: order}, storage.ImageFilter{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(first.Images) != 1 || first.NextCursor == nil || !first.HasNext {
+				t.Fatalf("Expected 1 image and a next cursor, got %+v", first)
+			}
+			if first.Total != 2 {
+				t.Fatalf("Expected total of 2, got %d", first.Total)
+			}
+
+			second, err := repo.Get(
+				ctx, storage.Paging{Limit: 1, Order: order, Cursor: first.NextCursor}, storage.ImageFilter{},
+			)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(second.Images) != 1 || second.NextCursor != nil {
+				t.Fatalf("Expected 1 image without next cursor, got %+v", second)
+			}
+			if first.Images[0].Id != all.Images[0].Id || second.Images[0].Id != all.Images[1].Id {
+				t.Fatalf("Expected pages to follow the order of %+v", all.Images)
+			}
+		})
+	}
+}
+
+func TestImageRepository_Search(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		testName      string
+		text          string
+		expectedNames []string
+	}{
+		{testName: "Free text", text: "image two", expectedNames: []string{"testing-image-two"}},
+		{testName: "Slug", text: "testing-image-one", expectedNames: []string{"testing-image-one"}},
+		{testName: "Prefix", text: "test", expectedNames: []string{"testing-image-one", "testing-image-two"}},
+		{testName: "No match", text: "plane", expectedNames: []string{}},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			page, err := repo.Search(ctx, d.text, 10, 0)
+			if err != nil {
+				t.Fatal(err)
+			}
+			result := page.Images
+			if len(result) != len(d.expectedNames) || page.Total != len(d.expectedNames) {
+				t.Fatalf("Expected %d images, got %d with total %d", len(d.expectedNames), len(result), page.Total)
+			}
+			for _, name := range d.expectedNames {
+				found := false
+				for _, img := range result {
+					found = found || img.Name == name
+				}
+				if !found {
+					t.Fatalf("Expected %s to be found, got %v", name, result)
+				}
+			}
+		})
+	}
+}
+
+func TestImageRepository_GetOneByName(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	img, err := repo.GetOneByName(ctx, "testing-image-two")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Name != "testing-image-two" || img.Id == "" || img.Tags == nil {
+		t.Fatalf("Expected testing-image-two, got %+v", img)
+	}
+
+	_, err = repo.GetOneByName(ctx, "missing-image")
+	var notFound storage.NotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
+
+func TestImageRepository_SetNameById(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	renamed, err := repo.SetNameById(ctx, img.Id, "testing-image-renamed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if renamed.Id != img.Id || renamed.Name != "testing-image-renamed" || renamed.UpdatedAt == nil {
+		t.Fatalf("Expected renamed image with updatedAt, got %+v", renamed)
+	}
+
+	persisted, err := repo.GetOne(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if persisted.Name != "testing-image-renamed" {
+		t.Fatalf("Expected persisted name testing-image-renamed, got %s", persisted.Name)
+	}
+
+	_, err = repo.SetNameById(ctx, img.Id, "testing-image-two")
+	if !errors.Is(err, storage.ErrDuplicate) {
+		t.Fatalf("Expected duplicate error, got %v", err)
+	}
+
+	_, err = repo.SetNameById(ctx, "3c47d736-6c4e-4a1c-a04b-3744cc30b263", "testing-image-missing")
+	var notFound storage.NotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
+
+func TestImageRepository_UpdateOne(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updates := img
+	updates.Name = "testing-image-updated"
+	updates.Format = storage.WebpFormat
+	updates.Original = "images/testing-image-updated.webp"
+	updates.Sizes = storage.ImageSizes{
+		Original: storage.Dimensions{Width: 800, Height: 600},
+		Xs:       &storage.Dimensions{Width: 100, Height: 75},
+	}
+	if err = repo.UpdateOne(ctx, updates); err != nil {
+		t.Fatal(err)
+	}
+
+	persisted, err := repo.GetOne(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !persisted.IsEqualTo(updates) || persisted.UpdatedAt == nil || persisted.AuthorId != img.AuthorId {
+		t.Fatalf("Expected %+v, got %+v", updates, persisted)
+	}
+
+	updates.Name = "testing-image-two"
+	if err = repo.UpdateOne(ctx, updates); !errors.Is(err, storage.ErrDuplicate) {
+		t.Fatalf("Expected duplicate error, got %v", err)
+	}
+
+	updates.Id = "3c47d736-6c4e-4a1c-a04b-3744cc30b263"
+	updates.Name = "testing-image-missing"
+	var notFound storage.NotFound
+	if err = repo.UpdateOne(ctx, updates); !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
+
+func TestImageRepository_SlugHistory(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = repo.SetNameById(ctx, img.Id, "testing-image-renamed"); err != nil {
+		t.Fatal(err)
+	}
+	updates, err := repo.GetOne(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	updates.Name = "testing-image-updated"
+	if err = repo.UpdateOne(ctx, updates); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, slug := range []string{"testing-image-one", "testing-image-renamed"} {
+		found, err := repo.GetOneByOldName(ctx, slug)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if found.Id != img.Id || found.Name != "testing-image-updated" {
+			t.Fatalf("Expected %s to resolve to testing-image-updated, got %+v", slug, found)
+		}
+	}
+
+	// Renaming back to an old slug makes it current again
+	if _, err = repo.SetNameById(ctx, img.Id, "testing-image-one"); err != nil {
+		t.Fatal(err)
+	}
+	var notFound storage.NotFound
+	if _, err = repo.GetOneByOldName(ctx, "testing-image-one"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected current name to not be in history, got %v", err)
+	}
+
+	if err = repo.DeleteOne(ctx, img.Id); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.GetOneByOldName(ctx, "testing-image-updated"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected history to be deleted with the image, got %v", err)
+	}
+}
+
+func TestImageRepository_Trash(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repo.DeleteOne(ctx, img.Id); err != nil {
+		t.Fatal(err)
+	}
+	var notFound storage.NotFound
+	if _, err = repo.GetOne(ctx, img.Id); !errors.As(err, &notFound) {
+		t.Fatalf("Expected deleted image to be hidden, got %v", err)
+	}
+
+	trash, err := repo.GetDeleted(ctx, 10, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if trash.Total != 1 || len(trash.Images) != 1 || trash.Images[0].DeletedAt == nil {
+		t.Fatalf("Expected the deleted image in the trash, got %+v", trash)
+	}
+
+	expired, err := repo.GetDeletedBefore(ctx, time.Now().Add(-time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 0 {
+		t.Fatalf("Expected no image past the retention period, got %+v", expired)
+	}
+
+	restored, err := repo.Restore(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if restored.DeletedAt != nil || restored.Name != img.Name {
+		t.Fatalf("Expected restored image %s, got %+v", img.Name, restored)
+	}
+	if _, err = repo.Restore(ctx, img.Id); !errors.As(err, &notFound) {
+		t.Fatalf("Expected image outside of the trash to not be restored, got %v", err)
+	}
+
+	if err = repo.DeleteOne(ctx, img.Id); err != nil {
+		t.Fatal(err)
+	}
+	expired, err = repo.GetDeletedBefore(ctx, time.Now().Add(time.Hour), 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(expired) != 1 {
+		t.Fatalf("Expected the deleted image past the retention period, got %+v", expired)
+	}
+	if err = repo.Purge(ctx, img.Id); err != nil {
+		t.Fatal(err)
+	}
+	if trash, err = repo.GetDeleted(ctx, 10, 0); err != nil || trash.Total != 0 {
+		t.Fatalf("Expected an empty trash, got %+v %v", trash, err)
+	}
+}
+
+func TestImageRepository_Versions(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, format := range []storage.ImageFormat{storage.JpgFormat, storage.WebpFormat} {
+		updates := img
+		updates.Format = format
+		updates.Original = "images/testing-image-one." + string(format)
+		replaced := storage.ImageVersion{FileName: fmt.Sprintf("versions/%s/%d", img.Id, i+1)}
+		if err = repo.ReplaceFiles(ctx, updates, replaced); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	versions, err := repo.GetVersions(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 2 || versions[1].Version != 1 {
+		t.Fatalf("Expected versions 2 and 1, got %+v", versions)
+	}
+	if versions[1].Original != img.Original || versions[0].Format != storage.JpgFormat {
+		t.Fatalf("Expected the replaced files in the versions, got %+v", versions)
+	}
+
+	first, err := repo.GetVersion(ctx, img.Id, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !first.ApplyTo(img).IsEqualTo(img) {
+		t.Fatalf("Expected first version to have the original files, got %+v", first)
+	}
+	if first.FileName != "versions/"+img.Id+"/1" {
+		t.Fatalf("Expected the files of the first version under its own name, got %s", first.FileName)
+	}
+	if exists, err := repo.DoesImageExist(ctx, first.FileName); err != nil || !exists {
+		t.Fatalf("Expected the file name of the version to be taken, got %v %v", exists, err)
+	}
+	var notFound storage.NotFound
+	if _, err = repo.GetVersion(ctx, img.Id, 3); !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+
+	restored := storage.ImageVersion{FileName: "versions/" + img.Id + "/restored"}
+	if err = repo.RestoreVersion(ctx, first.ApplyTo(img), restored, 1); err != nil {
+		t.Fatal(err)
+	}
+	if versions, err = repo.GetVersions(ctx, img.Id); err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 || versions[0].Version != 3 || versions[1].Version != 2 {
+		t.Fatalf("Expected the restored version replaced by version 3, got %+v", versions)
+	}
+	if err = repo.RestoreVersion(ctx, first.ApplyTo(img), restored, 1); !errors.As(err, &notFound) {
+		t.Fatalf("Expected not found, got %v", err)
+	}
+}
+
+func TestImageRepository_GetSimilar(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	one, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := repo.GetOneByName(ctx, "testing-image-two")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The sign bit is set so that the hash is stored as a negative BIGINT
+	hash := storage.PerceptualHash(-0x0f0f0f0f0f0f0f10)
+	closeHash := hash ^ 0b101
+	one.PerceptualHash = &hash
+	two.PerceptualHash = &closeHash
+	for _, img := range []storage.Image{one, two} {
+		if err = repo.UpdateOne(ctx, img); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	similar, err := repo.GetSimilar(ctx, hash, 2, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 2 || similar[0].Id != one.Id || similar[0].Distance != 0 || similar[1].Distance != 2 {
+		t.Fatalf("Expected both images closest first, got %+v", similar)
+	}
+	if *similar[0].PerceptualHash != hash {
+		t.Fatalf("Expected hash %d, got %d", hash, *similar[0].PerceptualHash)
+	}
+
+	similar, err = repo.GetSimilar(ctx, hash, 1, one.Id, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(similar) != 0 {
+		t.Fatalf("Expected no image within 1 bit other than the excluded one, got %+v", similar)
+	}
+}
+
+func TestImageRepository_Placeholders(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+
+	missing, err := repo.GetWithoutPlaceholders(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 2 {
+		t.Fatalf("Expected both images without placeholders, got %d", len(missing))
+	}
+
+	if err = repo.SetPlaceholders(ctx, missing[0].Id, "LFTI:j;$fQ;$|co1fQo1fQfQfQfQ", "#ff0000"); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOne(ctx, missing[0].Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.BlurHash == nil || *img.BlurHash != "LFTI:j;$fQ;$|co1fQo1fQfQfQfQ" || *img.DominantColor != "#ff0000" {
+		t.Fatalf("Expected the placeholders to be stored, got %+v", img)
+	}
+
+	missing, err = repo.GetWithoutPlaceholders(ctx, "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0].Id == img.Id {
+		t.Fatalf("Expected only the other image without placeholders, got %+v", missing)
+	}
+}
+
+func TestImageRepository_Metadata(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err := repo.GetMetadata(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata != (storage.ImageMetadata{}) {
+		t.Fatalf("Expected no metadata, got %+v", metadata)
+	}
+
+	capturedAt := time.Date(2022, 6, 18, 12, 30, 5, 0, time.UTC)
+	img.Metadata = &storage.ImageMetadata{CameraModel: "Canon EOS R5", Iso: 400, CapturedAt: &capturedAt}
+	if err = repo.ReplaceFiles(ctx, img, storage.ImageVersion{FileName: "versions/" + img.Id + "/1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	metadata, err = repo.GetMetadata(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metadata.CameraModel != "Canon EOS R5" || metadata.Iso != 400 || !metadata.CapturedAt.Equal(capturedAt) {
+		t.Fatalf("Expected the metadata to be stored, got %+v", metadata)
+	}
+
+	after := capturedAt.Add(-time.Hour)
+	page, err := repo.Get(
+		ctx, storage.Paging{Limit: 10, Order: storage.OrderAscending}, storage.ImageFilter{CapturedAfter: &after},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Images) != 1 || page.Images[0].Id != img.Id {
+		t.Fatalf("Expected only the image captured after %s, got %+v", after, page.Images)
+	}
+
+	version, err := repo.GetVersion(ctx, img.Id, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if version.Metadata != nil {
+		t.Fatalf("Expected the replaced files to have no metadata, got %+v", version.Metadata)
+	}
+}
+
+func TestImageRepository_SetDescription(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	description := storage.ImageDescription{
+		AltText: "A fighter plane flying over white cliffs",
+		Title:   "Spitfire over Dover",
+		Credit:  "John Doe",
+		License: "cc-by",
+	}
+	updated, err := repo.SetDescription(ctx, img.Id, description)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.ImageDescription != description || updated.Name != img.Name {
+		t.Fatalf("Expected the description to be stored, got %+v", updated)
+	}
+
+	for _, query := range []string{"spitfire", "cliffs", "doe"} {
+		page, err := repo.Search(ctx, query, 10, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page.Images) != 1 || page.Images[0].Id != img.Id {
+			t.Fatalf("Expected %s to find the described image, got %+v", query, page.Images)
+		}
+	}
+
+	if _, err = repo.SetDescription(ctx, "3c47d736-6c4e-4a1c-a04b-3744cc30b263", description); err == nil {
+		t.Fatal("Expected an unknown image to fail")
+	}
+}
+
+func TestImageRepository_Translations(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	one, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	two, err := repo.GetOneByName(ctx, "testing-image-two")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	translations := []storage.ImageTranslation{
+		{ImageId: one.Id, Locale: "fr", AltText: "Un avion", Caption: "Au-dessus des falaises"},
+		{ImageId: one.Id, Locale: "de", AltText: "Ein Flugzeug"},
+		{ImageId: two.Id, Locale: "de", AltText: "Ein Panzer"},
+	}
+	for _, translation := range translations {
+		if _, err = repo.SetTranslation(ctx, translation); err != nil {
+			t.Fatal(err)
+		}
+	}
+	updated, err := repo.SetTranslation(ctx, storage.ImageTranslation{ImageId: one.Id, Locale: "de", AltText: "Ein Jet"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.AltText != "Ein Jet" || updated.CreatedAt == nil || updated.UpdatedAt == nil {
+		t.Fatalf("Expected the translation to be overwritten, got %+v", updated)
+	}
+
+	found, err := repo.GetTranslations(ctx, one.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 2 || found[0].Locale != "de" || found[1].Locale != "fr" || found[1].Caption == "" {
+		t.Fatalf("Expected de and fr translations of the first image, got %+v", found)
+	}
+	if found, err = repo.GetTranslations(ctx, one.Id, two.Id); err != nil || len(found) != 3 {
+		t.Fatalf("Expected translations of both images, got %+v %v", found, err)
+	}
+
+	if err = repo.DeleteTranslation(ctx, one.Id, "fr"); err != nil {
+		t.Fatal(err)
+	}
+	var notFound storage.NotFound
+	if err = repo.DeleteTranslation(ctx, one.Id, "fr"); !errors.As(err, &notFound) {
+		t.Fatalf("Expected the deleted translation to be not found, got %v", err)
+	}
+	unknown := storage.ImageTranslation{ImageId: "3c47d736-6c4e-4a1c-a04b-3744cc30b263", Locale: "de"}
+	if _, err = repo.SetTranslation(ctx, unknown); !errors.As(err, &notFound) {
+		t.Fatalf("Expected an unknown image to be not found, got %v", err)
+	}
+
+	if err = repo.DeleteOne(ctx, two.Id); err != nil {
+		t.Fatal(err)
+	}
+	if found, err = repo.GetTranslations(ctx, two.Id); err != nil || len(found) != 0 {
+		t.Fatalf("Expected no translations of an image in the trash, got %+v %v", found, err)
+	}
+}
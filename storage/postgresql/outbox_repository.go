@@ -0,0 +1,142 @@
+package postgresql
+
+import (
+	"api/storage"
+	"context"
+	"encoding/json"
+	"github.com/jackc/pgx/v4"
+	"time"
+)
+
+type OutboxRepo struct {
+	database *Database
+}
+
+func NewOutboxRepository(db *Database) *OutboxRepo {
+	return &OutboxRepo{database: db}
+}
+
+// insertOutboxEvent records the event in the transaction of the change it describes, so that the event exists
+// only when the change is committed
+func insertOutboxEvent(ctx context.Context, tx pgx.Tx, eventType storage.EventType, image storage.Image) error {
+	payload, err := json.Marshal(image)
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO outbox ("event_type", "image_id", "payload") VALUES ($1, $2, $3)`
+	_, err = tx.Exec(ctx, query, eventType, image.Id, string(payload))
+
+	return err
+}
+
+// ClaimUnpublished skips the rows another relay is claiming at the same time. An event also waits while an earlier
+// event of its image is leased, so that two relays never publish the events of an image out of order.
+func (repo *OutboxRepo) ClaimUnpublished(
+	ctx context.Context, limit int, lease time.Duration,
+) ([]storage.OutboxEvent, error) {
+	query := `WITH claimed AS (
+    UPDATE outbox
+    SET locked_until = now() + $2::interval
+    WHERE id IN (
+        SELECT id
+        FROM outbox AS pending
+        WHERE published_at IS NULL
+          AND dead_at IS NULL
+          AND (locked_until IS NULL OR locked_until < now())
+          AND NOT EXISTS (
+            SELECT 1
+            FROM outbox AS leased
+            WHERE leased.image_id = pending.image_id
+              AND leased.seq < pending.seq
+              AND leased.published_at IS NULL
+              AND leased.dead_at IS NULL
+              AND leased.locked_until >= now()
+          )
+        ORDER BY seq
+        LIMIT $1
+        FOR UPDATE SKIP LOCKED
+    )
+    RETURNING id, seq, event_type, image_id, payload, created_at, attempts
+)
+SELECT id, event_type, image_id, payload, created_at, attempts
+FROM claimed
+ORDER BY seq
+`
+	rows, err := repo.database.dbPool.Query(ctx, query, limit, lease)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []storage.OutboxEvent
+	for rows.Next() {
+		var event storage.OutboxEvent
+		err = rows.Scan(
+			&event.Id, &event.Type, &event.ImageId, &event.Payload, &event.CreatedAt, &event.Attempts,
+		)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+
+	return events, rows.Err()
+}
+
+func (repo *OutboxRepo) MarkPublished(ctx context.Context, eventId string) error {
+	query := `UPDATE outbox
+SET published_at = now(), attempts = attempts + 1, last_error = '', locked_until = NULL
+WHERE id = $1`
+
+	return repo.exec(ctx, query, eventId)
+}
+
+func (repo *OutboxRepo) MarkFailed(
+	ctx context.Context, eventId string, reason string, retryAfter time.Duration,
+) error {
+	query := `UPDATE outbox
+SET attempts = attempts + 1, last_error = $2, locked_until = now() + $3::interval
+WHERE id = $1`
+
+	return repo.exec(ctx, query, eventId, reason, retryAfter)
+}
+
+func (repo *OutboxRepo) MarkDead(ctx context.Context, eventId string, reason string) error {
+	query := `UPDATE outbox
+SET attempts = attempts + 1, last_error = $2, locked_until = NULL, dead_at = now()
+WHERE id = $1`
+
+	return repo.exec(ctx, query, eventId, reason)
+}
+
+func (repo *OutboxRepo) Release(ctx context.Context, eventIds ...string) error {
+	if len(eventIds) == 0 {
+		return nil
+	}
+	query := `UPDATE outbox SET locked_until = NULL WHERE id = ANY($1::uuid[]) AND published_at IS NULL`
+	_, err := repo.database.dbPool.Exec(ctx, query, eventIds)
+
+	return err
+}
+
+func (repo *OutboxRepo) exec(ctx context.Context, query string, eventId string, args ...interface{}) error {
+	tag, err := repo.database.dbPool.Exec(ctx, query, append([]interface{}{eventId}, args...)...)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Outbox event not found " + eventId}
+	}
+
+	return nil
+}
+
+func (repo *OutboxRepo) DeleteAll(ctx context.Context) (int64, error) {
+	tag, err := repo.database.dbPool.Exec(ctx, `DELETE FROM outbox`)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
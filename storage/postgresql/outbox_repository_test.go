@@ -0,0 +1,87 @@
+package postgresql
+
+import (
+	"api/storage"
+	"api/test"
+	"context"
+	"testing"
+	"time"
+)
+
+func TestOutboxRepo_ImageLifecycle(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outboxRepo := NewOutboxRepository(repo.database)
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, repo)
+	defer func() {
+		if _, err := outboxRepo.DeleteAll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if err = insertDummyData(repo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	img, err := repo.GetOneByName(ctx, "testing-image-one")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.SetNameById(ctx, img.Id, "testing-image-renamed"); err != nil {
+		t.Fatal(err)
+	}
+	if err = repo.DeleteOne(ctx, img.Id); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := outboxRepo.ClaimUnpublished(ctx, 10, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []storage.EventType{
+		storage.EventImageCreated, storage.EventImageCreated, storage.EventImageUpdated, storage.EventImageDeleted,
+	}
+	if len(events) != len(expected) {
+		t.Fatalf("Expected %d events, got %d", len(expected), len(events))
+	}
+	for i, event := range events {
+		if event.Type != expected[i] {
+			t.Fatalf("Expected %s at %d, got %s", expected[i], i, event.Type)
+		}
+	}
+	if leased, err := outboxRepo.ClaimUnpublished(ctx, 10, time.Minute); err != nil || len(leased) != 0 {
+		t.Fatalf("Expected the leased events not claimed again, got %d, %v", len(leased), err)
+	}
+
+	if err = outboxRepo.MarkPublished(ctx, events[0].Id); err != nil {
+		t.Fatal(err)
+	}
+	if err = outboxRepo.MarkFailed(ctx, events[2].Id, "webhook unavailable", time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if err = outboxRepo.Release(ctx, events[1].Id, events[3].Id); err != nil {
+		t.Fatal(err)
+	}
+	// The deletion waits for the failed update of the same image
+	if events, err = outboxRepo.ClaimUnpublished(ctx, 10, time.Minute); err != nil || len(events) != 1 {
+		t.Fatalf("Expected 1 claimed event, got %d, %v", len(events), err)
+	}
+	if events[0].Type != storage.EventImageCreated {
+		t.Fatalf("Expected the creation of the other image, got %s", events[0].Type)
+	}
+	if err = outboxRepo.MarkDead(ctx, events[0].Id, "webhook unavailable"); err != nil {
+		t.Fatal(err)
+	}
+	if events, err = outboxRepo.ClaimUnpublished(ctx, 10, time.Minute); err != nil || len(events) != 0 {
+		t.Fatalf("Expected the dead event not claimed, got %d, %v", len(events), err)
+	}
+}
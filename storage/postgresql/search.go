@@ -0,0 +1,22 @@
+package postgresql
+
+import (
+	"strings"
+	"unicode"
+)
+
+// toPrefixTsQuery converts free text into a tsquery where every term must match as a prefix, example:
+// from: World-war plane
+// to: world:* & war:* & plane:*
+// Any non alphanumeric character separates terms, so SEO names with hyphens match the same as spaces.
+func toPrefixTsQuery(text string) string {
+	terms := strings.FieldsFunc(strings.ToLower(text), func(char rune) bool {
+		return !unicode.IsLetter(char) && !unicode.IsDigit(char)
+	})
+
+	for i, term := range terms {
+		terms[i] = term + ":*"
+	}
+
+	return strings.Join(terms, " & ")
+}
@@ -0,0 +1,26 @@
+package postgresql
+
+import "testing"
+
+func TestToPrefixTsQuery(t *testing.T) {
+	data := []struct {
+		testName string
+		text     string
+		expected string
+	}{
+		{testName: "Free text", text: "plane war", expected: "plane:* & war:*"},
+		{testName: "SEO name", text: "world-war-2-plane", expected: "world:* & war:* & 2:* & plane:*"},
+		{testName: "Uppercase and underscores", text: "World_War", expected: "world:* & war:*"},
+		{testName: "Operators are stripped", text: "plane & !war | (ship):*", expected: "plane:* & war:* & ship:*"},
+		{testName: "Empty", text: " -_ ", expected: ""},
+	}
+
+	for _, d := range data {
+		t.Run(d.testName, func(t *testing.T) {
+			result := toPrefixTsQuery(d.text)
+			if result != d.expected {
+				t.Fatalf("Expected %s, got %s", d.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,157 @@
+package postgresql
+
+import (
+	"api/storage"
+	"context"
+	"errors"
+	"github.com/jackc/pgx/v4"
+)
+
+type TagRepo struct {
+	database *Database
+}
+
+func NewTagRepository(db *Database) *TagRepo {
+	return &TagRepo{database: db}
+}
+
+func (repo *TagRepo) Get(ctx context.Context) (storage.TagUsageList, error) {
+	// Images in the trash do not count
+	query := `SELECT
+ t.id, t.value, t.created_at, t.updated_at, t.author_id, COUNT(i.id)
+ FROM tags t
+ LEFT JOIN images_tags it ON it.tag_id = t.id
+ LEFT JOIN images i ON i.id = it.image_id AND i.deleted_at IS NULL
+ GROUP BY t.id
+ ORDER BY COUNT(i.id) DESC, t.value ASC
+`
+	rows, err := repo.database.dbPool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	tags := storage.TagUsageList{}
+	for rows.Next() {
+		var usage storage.TagUsage
+		err = rows.Scan(
+			&usage.Id, &usage.Value, &usage.CreatedAt, &usage.UpdatedAt, &usage.AuthorId, &usage.Count,
+		)
+		if err != nil {
+			return nil, err
+		}
+		tags = append(tags, usage)
+	}
+
+	return tags, rows.Err()
+}
+
+func (repo *TagRepo) GetOne(ctx context.Context, tagId string) (storage.Tag, error) {
+	query := `SELECT id, value, created_at, updated_at, author_id FROM tags WHERE id = $1 LIMIT 1`
+
+	var tag storage.Tag
+	err := repo.database.dbPool.QueryRow(ctx, query, tagId).
+		Scan(&tag.Id, &tag.Value, &tag.CreatedAt, &tag.UpdatedAt, &tag.AuthorId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.Tag{}, storage.NotFound{Msg: "Tag not found " + tagId}
+		}
+		return storage.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (repo *TagRepo) Create(ctx context.Context, value, authorId string) (storage.Tag, error) {
+	query := `INSERT INTO tags ("value", "author_id")
+ VALUES ($1, $2)
+ RETURNING id, value, created_at, updated_at, author_id
+`
+	var tag storage.Tag
+	err := repo.database.dbPool.QueryRow(ctx, query, value, authorId).
+		Scan(&tag.Id, &tag.Value, &tag.CreatedAt, &tag.UpdatedAt, &tag.AuthorId)
+	if err != nil {
+		if hasErrorCode(err, uniqueViolationCode) {
+			return storage.Tag{}, storage.ErrDuplicate
+		}
+		return storage.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (repo *TagRepo) Rename(ctx context.Context, tagId, newValue string) (storage.Tag, error) {
+	query := `UPDATE tags SET value = $2, updated_at = now()
+ WHERE id = $1
+ RETURNING id, value, created_at, updated_at, author_id
+`
+	var tag storage.Tag
+	err := repo.database.dbPool.QueryRow(ctx, query, tagId, newValue).
+		Scan(&tag.Id, &tag.Value, &tag.CreatedAt, &tag.UpdatedAt, &tag.AuthorId)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.Tag{}, storage.NotFound{Msg: "Tag not found " + tagId}
+		}
+		if hasErrorCode(err, uniqueViolationCode) {
+			return storage.Tag{}, storage.ErrDuplicate
+		}
+		return storage.Tag{}, err
+	}
+
+	return tag, nil
+}
+
+func (repo *TagRepo) DeleteOne(ctx context.Context, tagId string) error {
+	query := "DELETE FROM tags WHERE id = $1"
+
+	commandTag, err := repo.database.dbPool.Exec(ctx, query, tagId)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Tag not found " + tagId}
+	}
+
+	return nil
+}
+
+func (repo *TagRepo) Attach(ctx context.Context, imageId, tagId string) error {
+	query := `INSERT INTO images_tags ("image_id", "tag_id")
+ VALUES ($1, $2)
+ ON CONFLICT (tag_id, image_id) DO NOTHING
+`
+	_, err := repo.database.dbPool.Exec(ctx, query, imageId, tagId)
+	if err != nil {
+		if hasErrorCode(err, foreignKeyViolationCode) {
+			return storage.NotFound{Msg: "Image or tag not found"}
+		}
+		return err
+	}
+
+	return nil
+}
+
+func (repo *TagRepo) Detach(ctx context.Context, imageId, tagId string) error {
+	query := "DELETE FROM images_tags WHERE image_id = $1 AND tag_id = $2"
+
+	commandTag, err := repo.database.dbPool.Exec(ctx, query, imageId, tagId)
+	if err != nil {
+		return err
+	}
+	if commandTag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Tag is not attached to the image"}
+	}
+
+	return nil
+}
+
+func (repo *TagRepo) DeleteAll(ctx context.Context) (rowsAffected int64, err error) {
+	query := "DELETE FROM tags"
+	cmdTag, err := repo.database.dbPool.Exec(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+
+	rowsAffected = cmdTag.RowsAffected()
+	return
+}
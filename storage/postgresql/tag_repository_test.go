@@ -0,0 +1,144 @@
+package postgresql
+
+import (
+	"api/storage"
+	"api/test"
+	"context"
+	"errors"
+	"testing"
+)
+
+func setupTagRepo(ctx context.Context) (*TagRepo, error) {
+	db, err := setupDb(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewTagRepository(db), nil
+}
+
+func cleanTagRepo(t *testing.T, repo *TagRepo) {
+	defer repo.database.Close()
+
+	_, err := repo.DeleteAll(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTagRepo_CreateRenameDelete(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupTagRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanTagRepo(t, repo)
+
+	user, err := userRepo.Create(ctx, storage.UserCreationDto{Email: "john@gmail.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tag, err := repo.Create(ctx, "planes", user.Id)
+	if err != nil {
+		t.Fatalf("failed creating tag: %v", err)
+	}
+	if tag.Value != "planes" || tag.AuthorId == nil || *tag.AuthorId != user.Id {
+		t.Fatalf("unexpected tag %+v", tag)
+	}
+
+	if _, err = repo.Create(ctx, "planes", user.Id); !errors.Is(err, storage.ErrDuplicate) {
+		t.Fatalf("expected duplicate error, got %v", err)
+	}
+
+	renamed, err := repo.Rename(ctx, tag.Id, "aircraft")
+	if err != nil {
+		t.Fatalf("failed renaming tag: %v", err)
+	}
+	if renamed.Value != "aircraft" || renamed.UpdatedAt == nil {
+		t.Fatalf("unexpected renamed tag %+v", renamed)
+	}
+
+	if err = repo.DeleteOne(ctx, tag.Id); err != nil {
+		t.Fatalf("failed deleting tag: %v", err)
+	}
+
+	var notFound storage.NotFound
+	if _, err = repo.GetOne(ctx, tag.Id); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found, got %v", err)
+	}
+}
+
+func TestTagRepo_AttachDetach(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupTagRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageRepo, err := setupImageRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	userRepo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(userRepo)
+	defer cleanImageRepo(t, imageRepo)
+	defer cleanTagRepo(t, repo)
+
+	if err = insertDummyData(imageRepo, userRepo); err != nil {
+		t.Fatal(err)
+	}
+	page, err := imageRepo.Get(ctx, storage.Paging{Limit: 10, Order: storage.OrderAscending}, storage.ImageFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	img := page.Images[0]
+
+	tag, err := repo.Create(ctx, "planes", img.AuthorId)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = repo.Attach(ctx, img.Id, tag.Id); err != nil {
+		t.Fatalf("failed attaching tag: %v", err)
+	}
+	// Attaching twice is a no-op
+	if err = repo.Attach(ctx, img.Id, tag.Id); err != nil {
+		t.Fatalf("failed attaching tag twice: %v", err)
+	}
+
+	tagged, err := imageRepo.GetOne(ctx, img.Id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tagged.Tags) != 1 || tagged.Tags[0].Id != tag.Id || tagged.Tags[0].Value != "planes" {
+		t.Fatalf("expected image to have the planes tag, got %+v", tagged.Tags)
+	}
+
+	usage, err := repo.Get(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 || usage[0].Count != 1 {
+		t.Fatalf("expected a single tag used once, got %+v", usage)
+	}
+
+	if err = repo.Detach(ctx, img.Id, tag.Id); err != nil {
+		t.Fatalf("failed detaching tag: %v", err)
+	}
+	var notFound storage.NotFound
+	if err = repo.Detach(ctx, img.Id, tag.Id); !errors.As(err, &notFound) {
+		t.Fatalf("expected not found when detaching twice, got %v", err)
+	}
+}
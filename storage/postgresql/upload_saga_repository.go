@@ -0,0 +1,86 @@
+package postgresql
+
+import (
+	"api/storage"
+	"context"
+	"time"
+)
+
+type UploadSagaRepo struct {
+	database *Database
+}
+
+func NewUploadSagaRepository(db *Database) *UploadSagaRepo {
+	return &UploadSagaRepo{database: db}
+}
+
+func (repo *UploadSagaRepo) Create(ctx context.Context, saga storage.UploadSaga) (storage.UploadSaga, error) {
+	query := `INSERT INTO upload_sagas ("image_name", "format", "author_id", "step", "image", "error")
+ VALUES ($1, $2, $3, $4, $5, $6)
+ RETURNING id, created_at, updated_at
+`
+	err := repo.database.dbPool.QueryRow(
+		ctx, query, saga.ImageName, saga.Format, saga.AuthorId, saga.Step, saga.Image, saga.Error,
+	).Scan(&saga.Id, &saga.CreatedAt, &saga.UpdatedAt)
+	if err != nil {
+		if hasErrorCode(err, uniqueViolationCode) {
+			return storage.UploadSaga{}, storage.ErrDuplicate
+		}
+		return storage.UploadSaga{}, err
+	}
+
+	return saga, nil
+}
+
+func (repo *UploadSagaRepo) Update(ctx context.Context, saga storage.UploadSaga) error {
+	query := `UPDATE upload_sagas SET step = $2, image = $3, error = $4, updated_at = now() WHERE id = $1`
+
+	tag, err := repo.database.dbPool.Exec(ctx, query, saga.Id, saga.Step, saga.Image, saga.Error)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Upload saga not found " + saga.Id}
+	}
+
+	return nil
+}
+
+func (repo *UploadSagaRepo) GetUnfinished(ctx context.Context, olderThan time.Duration) ([]storage.UploadSaga, error) {
+	query := `SELECT id, image_name, format, author_id, step, image, error, created_at, updated_at
+ FROM upload_sagas
+ WHERE step IN ($1, $2) AND updated_at < now() - $3::interval
+ ORDER BY updated_at
+`
+	rows, err := repo.database.dbPool.Query(
+		ctx, query, storage.UploadSagaStarted, storage.UploadSagaResized, olderThan,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sagas []storage.UploadSaga
+	for rows.Next() {
+		var saga storage.UploadSaga
+		err = rows.Scan(
+			&saga.Id, &saga.ImageName, &saga.Format, &saga.AuthorId, &saga.Step, &saga.Image, &saga.Error,
+			&saga.CreatedAt, &saga.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		sagas = append(sagas, saga)
+	}
+
+	return sagas, rows.Err()
+}
+
+func (repo *UploadSagaRepo) DeleteAll(ctx context.Context) (int64, error) {
+	tag, err := repo.database.dbPool.Exec(ctx, `DELETE FROM upload_sagas`)
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
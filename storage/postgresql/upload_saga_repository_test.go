@@ -0,0 +1,71 @@
+package postgresql
+
+import (
+	"api/storage"
+	"api/test"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestUploadSagaRepo_Unfinished(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	db, err := setupDb(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo := NewUploadSagaRepository(db)
+	defer db.Close()
+	defer func() {
+		if _, err := repo.DeleteAll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	saga, err := repo.Create(ctx, storage.UploadSaga{
+		ImageName: "my-plane",
+		AuthorId:  "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10",
+		Step:      storage.UploadSagaStarted,
+	})
+	if err != nil {
+		t.Fatalf("failed creating saga: %v", err)
+	}
+
+	_, err = repo.Create(ctx, storage.UploadSaga{
+		ImageName: "my-plane",
+		AuthorId:  "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10",
+		Step:      storage.UploadSagaStarted,
+	})
+	if !errors.Is(err, storage.ErrDuplicate) {
+		t.Fatalf("Expected the name reserved by the unfinished saga, got %v", err)
+	}
+
+	saga.Step = storage.UploadSagaResized
+	saga.Image = &storage.Image{Name: "my-plane", Format: storage.PngFormat, Original: "images/my-plane.png"}
+	if err = repo.Update(ctx, saga); err != nil {
+		t.Fatalf("failed updating saga: %v", err)
+	}
+
+	sagas, err := repo.GetUnfinished(ctx, 0)
+	if err != nil {
+		t.Fatalf("failed fetching unfinished sagas: %v", err)
+	}
+	if len(sagas) != 1 || sagas[0].Image == nil || sagas[0].Image.Original != saga.Image.Original {
+		t.Fatalf("Expected the resized saga, got %+v", sagas)
+	}
+
+	if sagas, err = repo.GetUnfinished(ctx, time.Hour); err != nil || len(sagas) != 0 {
+		t.Fatalf("Expected no stale sagas, got %+v, %v", sagas, err)
+	}
+
+	saga.Step = storage.UploadSagaCompleted
+	if err = repo.Update(ctx, saga); err != nil {
+		t.Fatalf("failed completing saga: %v", err)
+	}
+	if sagas, err = repo.GetUnfinished(ctx, 0); err != nil || len(sagas) != 0 {
+		t.Fatalf("Expected no unfinished sagas, got %+v, %v", sagas, err)
+	}
+}
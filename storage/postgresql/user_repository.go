@@ -43,6 +43,33 @@ FROM users WHERE cog_username=$1 LIMIT 1
 	return user, nil
 }
 
+func (repo *UserRepo) GetById(ctx context.Context, userId string) (storage.User, error) {
+	query := `SELECT id, email, role, cog_username, cog_sub, cog_name, created_at, updated_at, disabled
+FROM users WHERE id=$1
+`
+	var user storage.User
+	err := repo.db.dbPool.QueryRow(ctx, query, userId).
+		Scan(
+			&user.Id,
+			&user.Email,
+			&user.Role,
+			&user.CogUsername,
+			&user.CogSub,
+			&user.CogName,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Disabled,
+		)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.User{}, storage.NotFound{Msg: "User not found " + userId}
+		}
+		return storage.User{}, err
+	}
+
+	return user, nil
+}
+
 func (repo *UserRepo) Create(ctx context.Context, dto storage.UserCreationDto) (storage.User, error) {
 	query := `INSERT INTO users
 ("email", "role", "cog_username", "cog_sub", "cog_name", "disabled")
@@ -82,6 +109,33 @@ RETURNING id, email, role, cog_username, cog_sub, cog_name, created_at, updated_
 	return user, nil
 }
 
+func (repo *UserRepo) UpdateRole(ctx context.Context, userId string, role storage.AuthRole) (storage.User, error) {
+	query := `UPDATE users SET role = $2, updated_at = now() WHERE id = $1
+RETURNING id, email, role, cog_username, cog_sub, cog_name, created_at, updated_at, disabled
+`
+	var user storage.User
+	err := repo.db.dbPool.QueryRow(ctx, query, userId, role).
+		Scan(
+			&user.Id,
+			&user.Email,
+			&user.Role,
+			&user.CogUsername,
+			&user.CogSub,
+			&user.CogName,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Disabled,
+		)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.User{}, storage.NotFound{Msg: "User not found " + userId}
+		}
+		return storage.User{}, err
+	}
+
+	return user, nil
+}
+
 func (repo *UserRepo) InsertMany(ctx context.Context, users storage.UserList) (count int64, err error) {
 	count, err = repo.db.dbPool.CopyFrom(
 		ctx,
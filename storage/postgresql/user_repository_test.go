@@ -169,3 +169,40 @@ func TestUserRepo_Create_Exists(t *testing.T) {
 		t.Errorf("expected error duplicate, got %v", err)
 	}
 }
+
+func TestUserRepo_UpdateRole(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	repo, err := setupUserRepo(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cleanUserRepo(repo)
+
+	insertUserDummyData(t, repo)
+	user, err := repo.GetByUsername(ctx, "what-ever-username123")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	updated, err := repo.UpdateRole(ctx, user.Id, storage.AuthRoleAdmin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if updated.Role != storage.AuthRoleAdmin || updated.UpdatedAt == nil {
+		t.Errorf("expected updated admin role, got %+v", updated)
+	}
+	if found, err := repo.GetById(ctx, user.Id); err != nil || found.Role != storage.AuthRoleAdmin {
+		t.Errorf("expected the admin role to be stored, got %+v %v", found, err)
+	}
+
+	_, err = repo.UpdateRole(ctx, "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10", storage.AuthRoleAdmin)
+	var notFound storage.NotFound
+	if !errors.As(err, &notFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+	if _, err = repo.GetById(ctx, "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10"); !errors.As(err, &notFound) {
+		t.Errorf("expected not found, got %v", err)
+	}
+}
@@ -0,0 +1,276 @@
+package postgresql
+
+import (
+	"api/storage"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/jackc/pgx/v4"
+	"golang.org/x/sync/errgroup"
+	"strings"
+)
+
+const webhookDeliveryColumns = `id, subscription_id, event_id, event_type, payload, status_code, success, attempts,
+ error, created_at`
+
+type WebhookRepo struct {
+	database *Database
+}
+
+func NewWebhookRepository(db *Database) *WebhookRepo {
+	return &WebhookRepo{database: db}
+}
+
+func (repo *WebhookRepo) GetSubscriptions(ctx context.Context) ([]storage.WebhookSubscription, error) {
+	query := `SELECT id, url, secret, events, created_at, updated_at FROM webhook_subscriptions ORDER BY created_at`
+
+	rows, err := repo.database.dbPool.Query(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	subscriptions := []storage.WebhookSubscription{}
+	for rows.Next() {
+		var subscription storage.WebhookSubscription
+		err = rows.Scan(
+			&subscription.Id, &subscription.Url, &subscription.Secret, &subscription.Events,
+			&subscription.CreatedAt, &subscription.UpdatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		subscriptions = append(subscriptions, subscription)
+	}
+
+	return subscriptions, rows.Err()
+}
+
+func (repo *WebhookRepo) GetSubscription(
+	ctx context.Context, subscriptionId string,
+) (storage.WebhookSubscription, error) {
+	query := `SELECT id, url, secret, events, created_at, updated_at FROM webhook_subscriptions WHERE id = $1`
+
+	var subscription storage.WebhookSubscription
+	err := repo.database.dbPool.QueryRow(ctx, query, subscriptionId).Scan(
+		&subscription.Id, &subscription.Url, &subscription.Secret, &subscription.Events,
+		&subscription.CreatedAt, &subscription.UpdatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.WebhookSubscription{}, storage.NotFound{Msg: "Webhook not found " + subscriptionId}
+		}
+		return storage.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (repo *WebhookRepo) CreateSubscription(
+	ctx context.Context, subscription storage.WebhookSubscription,
+) (storage.WebhookSubscription, error) {
+	query := `INSERT INTO webhook_subscriptions ("url", "secret", "events")
+ VALUES ($1, $2, $3)
+ RETURNING id, created_at, updated_at
+`
+	err := repo.database.dbPool.QueryRow(
+		ctx, query, subscription.Url, subscription.Secret, nonNilStrings(subscription.Events),
+	).Scan(&subscription.Id, &subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		return storage.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (repo *WebhookRepo) UpdateSubscription(
+	ctx context.Context, subscription storage.WebhookSubscription,
+) (storage.WebhookSubscription, error) {
+	query := `UPDATE webhook_subscriptions SET url = $2, secret = $3, events = $4, updated_at = now()
+ WHERE id = $1
+ RETURNING created_at, updated_at
+`
+	err := repo.database.dbPool.QueryRow(
+		ctx, query, subscription.Id, subscription.Url, subscription.Secret, nonNilStrings(subscription.Events),
+	).Scan(&subscription.CreatedAt, &subscription.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return storage.WebhookSubscription{}, storage.NotFound{Msg: "Webhook not found " + subscription.Id}
+		}
+		return storage.WebhookSubscription{}, err
+	}
+
+	return subscription, nil
+}
+
+func (repo *WebhookRepo) DeleteSubscription(ctx context.Context, subscriptionId string) error {
+	tag, err := repo.database.dbPool.Exec(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", subscriptionId)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return storage.NotFound{Msg: "Webhook not found " + subscriptionId}
+	}
+
+	return nil
+}
+
+func (repo *WebhookRepo) CreateDelivery(
+	ctx context.Context, delivery storage.WebhookDelivery,
+) (storage.WebhookDelivery, error) {
+	query := `INSERT INTO webhook_deliveries
+ ("subscription_id", "event_id", "event_type", "payload", "status_code", "success", "attempts", "error")
+ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+ RETURNING id, created_at
+`
+	err := repo.database.dbPool.QueryRow(
+		ctx,
+		query,
+		delivery.SubscriptionId,
+		delivery.EventId,
+		delivery.EventType,
+		string(delivery.Payload),
+		delivery.StatusCode,
+		delivery.Success,
+		delivery.Attempts,
+		delivery.Error,
+	).Scan(&delivery.Id, &delivery.CreatedAt)
+	if err != nil {
+		if hasErrorCode(err, foreignKeyViolationCode) {
+			return storage.WebhookDelivery{}, storage.NotFound{Msg: "Webhook not found " + delivery.SubscriptionId}
+		}
+		return storage.WebhookDelivery{}, err
+	}
+
+	return delivery, nil
+}
+
+func (repo *WebhookRepo) GetDelivery(ctx context.Context, deliveryId string) (storage.WebhookDelivery, error) {
+	query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries WHERE id = $1"
+
+	rows, err := repo.database.dbPool.Query(ctx, query, deliveryId)
+	if err != nil {
+		return storage.WebhookDelivery{}, err
+	}
+	defer rows.Close()
+
+	deliveries, err := scanWebhookDeliveries(rows)
+	if err != nil {
+		return storage.WebhookDelivery{}, err
+	}
+	if len(deliveries) == 0 {
+		return storage.WebhookDelivery{}, storage.NotFound{Msg: "Webhook delivery not found " + deliveryId}
+	}
+
+	return deliveries[0], nil
+}
+
+func (repo *WebhookRepo) GetDeliveries(
+	ctx context.Context, filter storage.WebhookDeliveryFilter, limit, offset int,
+) (storage.WebhookDeliveryPage, error) {
+	var deliveries []storage.WebhookDelivery
+	var total int
+
+	g, gCtx := errgroup.WithContext(ctx)
+
+	g.Go(func() error {
+		where, args := webhookDeliveryConditions(filter, []interface{}{limit + 1, offset})
+		query := "SELECT " + webhookDeliveryColumns + " FROM webhook_deliveries" + where + `
+ ORDER BY created_at DESC, id DESC
+ LIMIT $1
+ OFFSET $2
+`
+		rows, err := repo.database.dbPool.Query(gCtx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed querying webhook deliveries: %w", err)
+		}
+		defer rows.Close()
+
+		deliveries, err = scanWebhookDeliveries(rows)
+		return err
+	})
+
+	g.Go(func() error {
+		where, args := webhookDeliveryConditions(filter, nil)
+		query := "SELECT count(*) FROM webhook_deliveries" + where
+		if err := repo.database.dbPool.QueryRow(gCtx, query, args...).Scan(&total); err != nil {
+			return fmt.Errorf("failed counting webhook deliveries: %w", err)
+		}
+		return nil
+	})
+
+	if err := g.Wait(); err != nil {
+		return storage.WebhookDeliveryPage{}, err
+	}
+
+	if len(deliveries) <= limit {
+		return storage.WebhookDeliveryPage{Deliveries: deliveries, Total: total}, nil
+	}
+
+	return storage.WebhookDeliveryPage{Deliveries: deliveries[:limit], Total: total, HasNext: true}, nil
+}
+
+func (repo *WebhookRepo) DeleteAll(ctx context.Context) (int64, error) {
+	tag, err := repo.database.dbPool.Exec(ctx, "DELETE FROM webhook_subscriptions")
+	if err != nil {
+		return 0, err
+	}
+
+	return tag.RowsAffected(), nil
+}
+
+// webhookDeliveryConditions appends the filter values to args and returns the matching WHERE clause
+func webhookDeliveryConditions(
+	filter storage.WebhookDeliveryFilter, args []interface{},
+) (string, []interface{}) {
+	var conditions []string
+
+	addCondition := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if filter.SubscriptionId != "" {
+		addCondition("subscription_id = $%d", filter.SubscriptionId)
+	}
+	if filter.EventId != "" {
+		addCondition("event_id = $%d", filter.EventId)
+	}
+	if filter.Success != nil {
+		addCondition("success = $%d", *filter.Success)
+	}
+
+	if len(conditions) == 0 {
+		return "", args
+	}
+
+	return " WHERE " + strings.Join(conditions, " AND "), args
+}
+
+func scanWebhookDeliveries(rows pgx.Rows) ([]storage.WebhookDelivery, error) {
+	deliveries := []storage.WebhookDelivery{}
+
+	for rows.Next() {
+		var delivery storage.WebhookDelivery
+		var payload []byte
+		err := rows.Scan(
+			&delivery.Id, &delivery.SubscriptionId, &delivery.EventId, &delivery.EventType, &payload,
+			&delivery.StatusCode, &delivery.Success, &delivery.Attempts, &delivery.Error, &delivery.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		delivery.Payload = payload
+		deliveries = append(deliveries, delivery)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// nonNilStrings stores a missing list as an empty array instead of NULL
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
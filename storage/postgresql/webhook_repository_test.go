@@ -0,0 +1,71 @@
+package postgresql
+
+import (
+	"api/storage"
+	"api/test"
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestWebhookRepo_Deliveries(t *testing.T) {
+	test.SkipIfNotIntegrationTesting(t)
+	ctx := context.Background()
+
+	db, err := setupDb(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	repo := NewWebhookRepository(db)
+	defer func() {
+		if _, err := repo.DeleteAll(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	subscription, err := repo.CreateSubscription(ctx, storage.WebhookSubscription{
+		Url:    "https://example.com/hooks",
+		Secret: "a-very-secret-secret",
+		Events: []string{string(storage.EventImageCreated)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, success := range []bool{false, true} {
+		_, err = repo.CreateDelivery(ctx, storage.WebhookDelivery{
+			SubscriptionId: subscription.Id,
+			EventId:        "0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10",
+			EventType:      storage.EventImageCreated,
+			Payload:        json.RawMessage(`{"id":"0d1a2ac4-6a35-4f38-a57c-2b1b1a2c7f10"}`),
+			StatusCode:     200,
+			Success:        success,
+			Attempts:       1,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	failed := false
+	page, err := repo.GetDeliveries(
+		ctx, storage.WebhookDeliveryFilter{SubscriptionId: subscription.Id, Success: &failed}, 10, 0,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 1 || len(page.Deliveries) != 1 || page.HasNext {
+		t.Fatalf("Expected a single failed delivery, got %+v", page)
+	}
+
+	if err = repo.DeleteSubscription(ctx, subscription.Id); err != nil {
+		t.Fatal(err)
+	}
+	_, err = repo.GetDelivery(ctx, page.Deliveries[0].Id)
+	var notFound storage.NotFound
+	if !errors.As(err, &notFound) {
+		t.Fatalf("Expected deliveries to be deleted with the subscription, got %v", err)
+	}
+}
@@ -32,6 +32,24 @@ func PagingToLimitOffset(page, size uint) (limit, offset int) {
 	return
 }
 
+// Paging selects a page by offset, or when the Cursor is set by keyset right after the cursor
+type Paging struct {
+	Limit  int
+	Offset int
+	Order  Order
+	Cursor *Cursor
+}
+
+// NewPaging keeps page and size working for existing clients, the page is ignored when there is a cursor
+func NewPaging(page, size uint, order Order, cursor *Cursor) Paging {
+	limit, offset := PagingToLimitOffset(page, size)
+	if cursor != nil {
+		offset = PaginationOffsetDefault
+	}
+
+	return Paging{Limit: limit, Offset: offset, Order: order, Cursor: cursor}
+}
+
 func (order Order) IsValid() bool {
 	switch order {
 	case OrderAscending:
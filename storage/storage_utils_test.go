@@ -28,3 +28,32 @@ func TestPagingToLimitOffset(test *testing.T) {
 		})
 	}
 }
+
+func TestNewPaging(test *testing.T) {
+	cursor := &Cursor{Id: "3c47d736-6c4e-4a1c-a04b-3744cc30b263"}
+
+	data := []struct {
+		testName       string
+		page           uint
+		cursor         *Cursor
+		expectedOffset int
+	}{
+		{testName: "Page without cursor", page: 3, expectedOffset: 20},
+		{testName: "Page with cursor", page: 3, cursor: cursor, expectedOffset: 0},
+	}
+
+	for _, d := range data {
+		test.Run(d.testName, func(t *testing.T) {
+			paging := NewPaging(d.page, 10, OrderAscending, d.cursor)
+			if paging.Limit != 10 || paging.Offset != d.expectedOffset {
+				t.Fatalf(
+					"Expected limit 10 and offset %d, got limit %d and offset %d",
+					d.expectedOffset, paging.Limit, paging.Offset,
+				)
+			}
+			if paging.Order != OrderAscending || paging.Cursor != d.cursor {
+				t.Fatalf("Expected order and cursor to be kept, got %+v", paging)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package storage
+
+import "time"
+
+type Tag struct {
+	Id        string     `json:"id"`
+	Value     string     `json:"value"`
+	CreatedAt *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty"`
+	AuthorId  *string    `json:"authorId,omitempty"`
+}
+
+type TagList []Tag
+
+type TagUsage struct {
+	Tag
+	Count int `json:"count"`
+}
+
+type TagUsageList []TagUsage
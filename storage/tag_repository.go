@@ -0,0 +1,15 @@
+package storage
+
+import (
+	"context"
+)
+
+type TagRepository interface {
+	Get(ctx context.Context) (TagUsageList, error)
+	GetOne(ctx context.Context, tagId string) (Tag, error)
+	Create(ctx context.Context, value, authorId string) (Tag, error)
+	Rename(ctx context.Context, tagId, newValue string) (Tag, error)
+	DeleteOne(ctx context.Context, tagId string) error
+	Attach(ctx context.Context, imageId, tagId string) error
+	Detach(ctx context.Context, imageId, tagId string) error
+}
@@ -0,0 +1,35 @@
+package storage
+
+import "time"
+
+type UploadSagaStep string
+
+const (
+	// UploadSagaStarted files are being uploaded and resized, the resize may have stored some of them already
+	UploadSagaStarted UploadSagaStep = "started"
+	// UploadSagaResized resized files are on the CDN, but the image is not saved yet
+	UploadSagaResized UploadSagaStep = "resized"
+	// UploadSagaCompleted the image is saved
+	UploadSagaCompleted UploadSagaStep = "completed"
+	// UploadSagaCompensated the upload failed and its files are removed
+	UploadSagaCompensated UploadSagaStep = "compensated"
+)
+
+func (step UploadSagaStep) IsFinished() bool {
+	return step == UploadSagaCompleted || step == UploadSagaCompensated
+}
+
+// UploadSaga tracks an image upload, Image holds the resized image from the UploadSagaResized step on. An unfinished
+// saga reserves its image name.
+type UploadSaga struct {
+	Id        string
+	ImageName string
+	// Format of the uploaded files, so that the original of an interrupted resize can be found
+	Format    ImageFormat
+	AuthorId  string
+	Step      UploadSagaStep
+	Image     *Image
+	Error     string
+	CreatedAt *time.Time
+	UpdatedAt *time.Time
+}
@@ -0,0 +1,14 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+type UploadSagaRepository interface {
+	// Create returns ErrDuplicate when an unfinished saga already reserved the image name
+	Create(ctx context.Context, saga UploadSaga) (UploadSaga, error)
+	Update(ctx context.Context, saga UploadSaga) error
+	// GetUnfinished returns the sagas that are not finished and were not updated for longer than olderThan
+	GetUnfinished(ctx context.Context, olderThan time.Duration) ([]UploadSaga, error)
+}
@@ -6,5 +6,7 @@ import (
 
 type UserRepository interface {
 	GetByUsername(ctx context.Context, username string) (User, error)
+	GetById(ctx context.Context, userId string) (User, error)
 	Create(ctx context.Context, dto UserCreationDto) (User, error)
+	UpdateRole(ctx context.Context, userId string, role AuthRole) (User, error)
 }
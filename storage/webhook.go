@@ -0,0 +1,62 @@
+package storage
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WebhookSubscription receives the events of the listed types, or all of them when none are listed. The secret
+// signs the deliveries and is never returned.
+type WebhookSubscription struct {
+	Id        string     `json:"id"`
+	Url       string     `json:"url"`
+	Secret    string     `json:"-"`
+	Events    []string   `json:"events"`
+	CreatedAt *time.Time `json:"createdAt"`
+	UpdatedAt *time.Time `json:"updatedAt"`
+}
+
+// WebhookInput is the subscription as given by admins, an empty secret on update keeps the current one
+type WebhookInput struct {
+	Url    string   `json:"url"`
+	Secret string   `json:"secret"`
+	Events []string `json:"events"`
+}
+
+func (subscription WebhookSubscription) IsSubscribedTo(eventType EventType) bool {
+	if len(subscription.Events) == 0 {
+		return true
+	}
+	for _, event := range subscription.Events {
+		if event == string(eventType) {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is the outcome of delivering an event to a subscription, Payload is the delivered body
+type WebhookDelivery struct {
+	Id             string          `json:"id"`
+	SubscriptionId string          `json:"subscriptionId"`
+	EventId        string          `json:"eventId"`
+	EventType      EventType       `json:"eventType"`
+	Payload        json.RawMessage `json:"payload"`
+	StatusCode     int             `json:"statusCode"`
+	Success        bool            `json:"success"`
+	Attempts       int             `json:"attempts"`
+	Error          string          `json:"error"`
+	CreatedAt      *time.Time      `json:"createdAt"`
+}
+
+type WebhookDeliveryFilter struct {
+	SubscriptionId string
+	EventId        string
+	Success        *bool
+}
+
+type WebhookDeliveryPage struct {
+	Deliveries []WebhookDelivery
+	Total      int
+	HasNext    bool
+}
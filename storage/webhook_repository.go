@@ -0,0 +1,19 @@
+package storage
+
+import (
+	"context"
+)
+
+type WebhookRepository interface {
+	GetSubscriptions(ctx context.Context) ([]WebhookSubscription, error)
+	GetSubscription(ctx context.Context, subscriptionId string) (WebhookSubscription, error)
+	CreateSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, subscription WebhookSubscription) (WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, subscriptionId string) error
+	CreateDelivery(ctx context.Context, delivery WebhookDelivery) (WebhookDelivery, error)
+	GetDelivery(ctx context.Context, deliveryId string) (WebhookDelivery, error)
+	// GetDeliveries returns the deliveries matching the filter, newest first
+	GetDeliveries(
+		ctx context.Context, filter WebhookDeliveryFilter, limit, offset int,
+	) (WebhookDeliveryPage, error)
+}
@@ -7,8 +7,8 @@ import (
 	"api/auth"
 	"api/auth/cognito"
 	"api/core"
-	"api/image"
-	"api/image/resize"
+	"api/events"
+	"api/events/webhook"
 	"api/storage"
 	"api/storage/postgresql"
 	"github.com/google/wire"
@@ -19,20 +19,38 @@ var DatabaseSet = wire.NewSet(
 	postgresql.NewDatabase,
 	postgresql.NewImageRepository,
 	postgresql.NewUserRepo,
+	postgresql.NewTagRepository,
+	postgresql.NewUploadSagaRepository,
+	postgresql.NewOutboxRepository,
+	postgresql.NewWebhookRepository,
+	postgresql.NewAuditRepository,
 	wire.Bind(new(storage.Storage), new(*postgresql.Database)),
 	wire.Bind(new(storage.ImagesRepository), new(*postgresql.ImageRepo)),
+	wire.Bind(new(storage.TagRepository), new(*postgresql.TagRepo)),
 	wire.Bind(new(storage.UserRepository), new(*postgresql.UserRepo)),
+	wire.Bind(new(storage.UploadSagaRepository), new(*postgresql.UploadSagaRepo)),
+	wire.Bind(new(storage.OutboxRepository), new(*postgresql.OutboxRepo)),
+	wire.Bind(new(storage.WebhookRepository), new(*postgresql.WebhookRepo)),
+	wire.Bind(new(storage.AuditRepository), new(*postgresql.AuditRepo)),
 )
 
 func InitializeApp(logger *zerolog.Logger) (*core.App, error) {
 	wire.Build(
 		core.NewConfigFromEnv,
 		DatabaseSet,
-		resize.NewClient,
-		wire.Bind(new(image.Resizer), new(*resize.Client)),
+		newResizer,
+		core.NewAuditLogger,
 		cognito.NewCognitoAuthService,
 		wire.Bind(new(auth.Authenticator), new(*cognito.AuthService)),
 		core.NewImagesService,
+		webhook.NewSender,
+		wire.Bind(new(core.WebhookSender), new(*webhook.Sender)),
+		core.NewWebhooksService,
+		wire.Bind(new(events.EventPublisher), new(*core.WebhooksService)),
+		core.NewUsersService,
+		core.NewOutboxRelay,
+		core.NewTrashPurger,
+		core.NewUploadSagaRecoverer,
 		core.NewApp,
 	)
 
@@ -43,11 +61,19 @@ func InitializeAppForTesting(logger *zerolog.Logger) (*core.App, error) {
 	wire.Build(
 		core.NewConfigFromEnv,
 		DatabaseSet,
-		resize.NewClient,
-		wire.Bind(new(image.Resizer), new(*resize.Client)),
+		newResizer,
+		core.NewAuditLogger,
 		cognito.NewCognitoAuthService,
 		wire.Bind(new(auth.Authenticator), new(*cognito.AuthService)),
 		core.NewImagesService,
+		webhook.NewSender,
+		wire.Bind(new(core.WebhookSender), new(*webhook.Sender)),
+		core.NewWebhooksService,
+		wire.Bind(new(events.EventPublisher), new(*core.WebhooksService)),
+		core.NewUsersService,
+		core.NewOutboxRelay,
+		core.NewTrashPurger,
+		core.NewUploadSagaRecoverer,
 		core.NewApp,
 	)
 
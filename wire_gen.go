@@ -9,7 +9,7 @@ package api
 import (
 	"api/auth/cognito"
 	"api/core"
-	"api/image/resize"
+	"api/events/webhook"
 	"api/storage"
 	"api/storage/postgresql"
 	"github.com/google/wire"
@@ -25,11 +25,26 @@ func InitializeApp(logger *zerolog.Logger) (*core.App, error) {
 	}
 	database := postgresql.NewDatabase(logger)
 	userRepo := postgresql.NewUserRepo(database)
+	auditRepo := postgresql.NewAuditRepository(database)
+	auditLogger := core.NewAuditLogger(auditRepo, logger)
 	authService := cognito.NewCognitoAuthService(config, userRepo)
-	client := resize.NewClient(config, logger)
+	resizer, err := newResizer(config, logger)
+	if err != nil {
+		return nil, err
+	}
 	imageRepo := postgresql.NewImageRepository(database)
-	imagesService := core.NewImagesService(client, imageRepo, authService, logger)
-	app := core.NewApp(config, database, authService, imagesService)
+	tagRepo := postgresql.NewTagRepository(database)
+	uploadSagaRepo := postgresql.NewUploadSagaRepository(database)
+	imagesService := core.NewImagesService(config, resizer, imageRepo, tagRepo, uploadSagaRepo, authService, auditLogger, logger)
+	webhookRepo := postgresql.NewWebhookRepository(database)
+	sender := webhook.NewSender()
+	webhooksService := core.NewWebhooksService(config, webhookRepo, sender, authService, auditLogger, logger)
+	outboxRepo := postgresql.NewOutboxRepository(database)
+	outboxRelay := core.NewOutboxRelay(config, outboxRepo, webhooksService, logger)
+	trashPurger := core.NewTrashPurger(config, imagesService, logger)
+	usersService := core.NewUsersService(userRepo, authService, auditLogger)
+	uploadSagaRecoverer := core.NewUploadSagaRecoverer(config, imagesService, logger)
+	app := core.NewApp(config, database, authService, imagesService, webhooksService, usersService, auditLogger, outboxRelay, trashPurger, uploadSagaRecoverer)
 	return app, nil
 }
 
@@ -40,14 +55,29 @@ func InitializeAppForTesting(logger *zerolog.Logger) (*core.App, error) {
 	}
 	database := postgresql.NewDatabase(logger)
 	userRepo := postgresql.NewUserRepo(database)
+	auditRepo := postgresql.NewAuditRepository(database)
+	auditLogger := core.NewAuditLogger(auditRepo, logger)
 	authService := cognito.NewCognitoAuthService(config, userRepo)
-	client := resize.NewClient(config, logger)
+	resizer, err := newResizer(config, logger)
+	if err != nil {
+		return nil, err
+	}
 	imageRepo := postgresql.NewImageRepository(database)
-	imagesService := core.NewImagesService(client, imageRepo, authService, logger)
-	app := core.NewApp(config, database, authService, imagesService)
+	tagRepo := postgresql.NewTagRepository(database)
+	uploadSagaRepo := postgresql.NewUploadSagaRepository(database)
+	imagesService := core.NewImagesService(config, resizer, imageRepo, tagRepo, uploadSagaRepo, authService, auditLogger, logger)
+	webhookRepo := postgresql.NewWebhookRepository(database)
+	sender := webhook.NewSender()
+	webhooksService := core.NewWebhooksService(config, webhookRepo, sender, authService, auditLogger, logger)
+	outboxRepo := postgresql.NewOutboxRepository(database)
+	outboxRelay := core.NewOutboxRelay(config, outboxRepo, webhooksService, logger)
+	trashPurger := core.NewTrashPurger(config, imagesService, logger)
+	usersService := core.NewUsersService(userRepo, authService, auditLogger)
+	uploadSagaRecoverer := core.NewUploadSagaRecoverer(config, imagesService, logger)
+	app := core.NewApp(config, database, authService, imagesService, webhooksService, usersService, auditLogger, outboxRelay, trashPurger, uploadSagaRecoverer)
 	return app, nil
 }
 
 // wire.go:
 
-var DatabaseSet = wire.NewSet(postgresql.NewDatabase, postgresql.NewImageRepository, postgresql.NewUserRepo, wire.Bind(new(storage.Storage), new(*postgresql.Database)), wire.Bind(new(storage.ImagesRepository), new(*postgresql.ImageRepo)), wire.Bind(new(storage.UserRepository), new(*postgresql.UserRepo)))
+var DatabaseSet = wire.NewSet(postgresql.NewDatabase, postgresql.NewImageRepository, postgresql.NewUserRepo, postgresql.NewTagRepository, postgresql.NewUploadSagaRepository, postgresql.NewOutboxRepository, postgresql.NewWebhookRepository, postgresql.NewAuditRepository, wire.Bind(new(storage.Storage), new(*postgresql.Database)), wire.Bind(new(storage.ImagesRepository), new(*postgresql.ImageRepo)), wire.Bind(new(storage.TagRepository), new(*postgresql.TagRepo)), wire.Bind(new(storage.UserRepository), new(*postgresql.UserRepo)), wire.Bind(new(storage.UploadSagaRepository), new(*postgresql.UploadSagaRepo)), wire.Bind(new(storage.OutboxRepository), new(*postgresql.OutboxRepo)), wire.Bind(new(storage.WebhookRepository), new(*postgresql.WebhookRepo)), wire.Bind(new(storage.AuditRepository), new(*postgresql.AuditRepo)))